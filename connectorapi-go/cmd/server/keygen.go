@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"connectorapi-go/pkg/config"
+)
+
+// keygen implements "connector-api keygen --id id [--not-before time]
+// [--expires-at time] [--stdin]": it generates a new API key, or hashes the
+// key read from stdin, and prints the entry to add to a client's "hashes" in
+// apikeys.json. Times are RFC 3339. It returns the exit status.
+func keygen(args []string) int {
+	fs := flag.NewFlagSet("keygen", flag.ExitOnError)
+	id := fs.String("id", "", "key id shown in logs, metrics and /status/apikeys, e.g. mobileapp-2026-10")
+	notBefore := fs.String("not-before", "", "first time the key is accepted (RFC 3339)")
+	expiresAt := fs.String("expires-at", "", "time the key stops being accepted (RFC 3339)")
+	fromStdin := fs.Bool("stdin", false, "hash the existing key read from stdin instead of generating one")
+	fs.Parse(args)
+	return printKey(os.Stdin, os.Stdout, *id, *notBefore, *expiresAt, *fromStdin)
+}
+
+func printKey(in io.Reader, out io.Writer, id, notBefore, expiresAt string, fromStdin bool) int {
+	if id == "" {
+		fmt.Fprintln(out, "keygen: --id is required")
+		return 2
+	}
+	parse := func(name, value string) (*time.Time, bool) {
+		if value == "" {
+			return nil, true
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			fmt.Fprintf(out, "keygen: --%s: %v\n", name, err)
+			return nil, false
+		}
+		return &t, true
+	}
+	nb, ok := parse("not-before", notBefore)
+	if !ok {
+		return 2
+	}
+	exp, ok := parse("expires-at", expiresAt)
+	if !ok {
+		return 2
+	}
+
+	key, entry, err := config.GenerateAPIKey(id, nb, exp)
+	if err != nil {
+		fmt.Fprintf(out, "keygen: %v\n", err)
+		return 1
+	}
+	if fromStdin {
+		line, err := bufio.NewReader(in).ReadString('\n')
+		if err != nil && err != io.EOF {
+			fmt.Fprintf(out, "keygen: reading key: %v\n", err)
+			return 1
+		}
+		key = strings.TrimRight(line, "\r\n")
+		salt, _ := hex.DecodeString(entry.Salt)
+		entry.Hash = config.HashAPIKey(salt, key)
+	}
+	if err := entry.Check(); err != nil {
+		fmt.Fprintf(out, "keygen: %v\n", err)
+		return 1
+	}
+
+	data, _ := json.MarshalIndent(entry, "", "  ")
+	if !fromStdin {
+		fmt.Fprintf(out, "API key (give it to the client; it is not stored anywhere):\n%s\n\n", key)
+	}
+	fmt.Fprintf(out, "Add to the client's \"hashes\" in apikeys.json:\n%s\n", data)
+	return 0
+}
@@ -1,19 +1,29 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 
 	tcp_client_adapter "connectorapi-go/internal/adapter/client"
+	elk_adapter "connectorapi-go/internal/adapter/client/elk"
 	handler_adapter "connectorapi-go/internal/adapter/handler/api"
 	repo_adapter "connectorapi-go/internal/adapter/utils"
 	service_core "connectorapi-go/internal/core/service"
+	format_core "connectorapi-go/internal/core/service/format"
 	"connectorapi-go/pkg/config"
+	appError "connectorapi-go/pkg/error"
 	"connectorapi-go/pkg/logger"
 	"connectorapi-go/pkg/metrics"
+	"connectorapi-go/pkg/tracing"
 )
 
 // @title           Connector API Gateway
@@ -33,28 +43,51 @@ import (
 
 // @schemes http https
 func main() {
-	cfg, err := config.Load("./configs/config.yaml")
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "validate-config":
+			os.Exit(validateConfig(os.Args[2:]))
+		case "keygen":
+			os.Exit(keygen(os.Args[2:]))
+		}
+	}
+	files := configFiles(flag.CommandLine, os.Args[1:])
+	apiKeysPath, routesPath := files.APIKeys(), files.Routes()
+
+	cfg, err := config.Load(files.Config())
 	if err != nil {
 		log.Fatalf("FATAL: Failed to load configuration: %v", err)
 	}
+	cfgOverrides := cfg.ApplyEnv(os.LookupEnv)
 
-	apiKeys, err := config.LoadAPIKeys("./configs/apikeys.json")
+	apiKeys, err := config.LoadAPIKeys(apiKeysPath)
 	if err != nil {
 		log.Fatalf("FATAL: Failed to load apiKeys: %v", err)
 	}
 
-	dr, err := config.LoadDestinationsAndRoutes("./configs/destinations_routes.json")
+	dr, err := config.LoadDestinationsAndRoutes(routesPath)
 	if err != nil {
 		log.Fatalf("FATAL: Failed to load destinations and routes: %v", err)
 	}
+	drOverrides := dr.ApplyEnv(os.LookupEnv)
 
 	appLogger := logger.New(cfg.Logger.Level)
 	defer appLogger.Sync()
 	appLogger.Info("Logger initialized")
+	appLogger.Infow("Configuration selected", "env", files.Env, "config", files.Config(), "apiKeys", apiKeysPath, "routes", routesPath, "overrides", append(cfgOverrides, drOverrides...))
+	if files.Shared(apiKeysPath) {
+		appLogger.Warnw("No API key file for the environment; using the shared one", "env", files.Env, "file", apiKeysPath)
+	}
 
 	gin.SetMode(cfg.Server.Mode)
 	appLogger.Infow("Gin mode set", "mode", cfg.Server.Mode)
 
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing)
+	if err != nil {
+		appLogger.Fatalw("Failed to set up tracing", "error", err)
+	}
+	appLogger.Infow("Tracing initialized", "exporter", cfg.Tracing.Exporter, "endpoint", cfg.Tracing.Endpoint)
+
 	appLogger.Info("Initializing dependencies...")
 	metrics.Init()
 
@@ -62,28 +95,134 @@ func main() {
 	apiKeyRepo := repo_adapter.NewAPIKeyRepository(apiKeys)
 
 	// --- TCP Socket Client Initialization ---
-	tcpClient := tcp_client_adapter.NewBasicTCPSocketClient(
-		5*time.Second,  // Dial Timeout (e.g., 5 seconds to establish connection)
-		10*time.Second, // Read/Write Timeout (e.g., 10 seconds for data transfer)
-	)
-	appLogger.Info("TCP Socket Client initialized")
+	dialTimeout := 5*time.Second       // Dial Timeout (e.g., 5 seconds to establish connection)
+	readWriteTimeout := 10*time.Second // Read/Write Timeout (e.g., 10 seconds for data transfer)
+	if cfg.TCPClient.DialTimeout > 0 {
+		dialTimeout = cfg.TCPClient.DialTimeout
+	}
+	if cfg.TCPClient.ReadWriteTimeout > 0 {
+		readWriteTimeout = cfg.TCPClient.ReadWriteTimeout
+	}
+	repo_adapter.ConfigureBreakers(cfg.TCPClient.CircuitBreaker)
+	tcpClient := tcp_client_adapter.NewPooledTCPSocketClient(dialTimeout, readWriteTimeout, cfg.TCPClient.Pool)
+	defer tcpClient.Close()
+	if err := tcpClient.SetDestinationCodecs(dr.Destinations); err != nil {
+		appLogger.Fatalw("Invalid destination codec", "error", err)
+	}
+	appLogger.Infow("TCP Socket Client initialized", "pool", cfg.TCPClient.Pool, "circuitBreaker", cfg.TCPClient.CircuitBreaker)
 	
 	appLogger.Infow("Loaded routes", "routes", dr.Routes)
 	appLogger.Infow("Loaded destinations", "destinations", dr.Destinations)
 
+	if err := format_core.CheckLayouts(); err != nil {
+		appLogger.Fatalw("Failed to load record layouts", "error", err)
+	}
+	if cfg.LayoutDir != "" {
+		if err := format_core.LoadLayouts(cfg.LayoutDir); err != nil {
+			appLogger.Fatalw("Failed to load record layouts", "dir", cfg.LayoutDir, "error", err)
+		}
+	}
+
+	catalogPath := cfg.ErrorCatalog.Path
+	if catalogPath == "" {
+		catalogPath = files.ErrorCatalog()
+	}
+	catalog, err := appError.LoadCatalog(catalogPath)
+	if err != nil {
+		appLogger.Fatalw("Failed to load error catalog", "path", catalogPath, "error", err)
+	}
+	appError.SetCatalog(catalog)
+	if files.Shared(catalogPath) {
+		appLogger.Warnw("No error catalog for the environment; using the shared one", "env", files.Env, "file", catalogPath)
+	}
+	appLogger.Infow("Error catalog loaded", "path", catalogPath, "services", len(catalog.Services), "global", len(catalog.Global))
+
+	// ELK log lines are written by one background writer; it is flushed on
+	// SIGINT/SIGTERM.
+	elkWriter := elk_adapter.NewWriter(cfg.ELKPath, cfg.ELK, appLogger)
+	elk_adapter.SetWriter(elkWriter)
+	// Messages are masked per the masking policy, using the record layouts
+	// loaded above for fixed-length data.
+	elk_adapter.SetMasker(elk_adapter.NewMasker(cfg.ELK.Masking, format_core.Layout))
+	go func() {
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+		sig := <-stop
+		appLogger.Infow("Shutting down; flushing ELK log", "signal", sig.String())
+		elkWriter.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := shutdownTracing(ctx); err != nil {
+			appLogger.Warnw("Tracing shutdown failed", "error", err)
+		}
+		cancel()
+		appLogger.Sync()
+		os.Exit(0)
+	}()
+	appLogger.Infow("ELK log writer started", "path", cfg.ELKPath, "elk", cfg.ELK)
+
+	routing := config.NewRouting(dr)
+	appLogger.Info("Setting up router...")
+	router := newRouter(cfg, appLogger, apiKeyRepo, tcpClient, routing)
+
+	// --- Startup self-check ---
+	report := append(service_core.CheckRouting(dr, router.Routes()), service_core.CheckAPIKeys(apiKeys, router.Routes())...)
+	for _, f := range report {
+		if f.Fatal {
+			appLogger.Errorw("Configuration error", "subject", f.Subject, "error", f.Message)
+		} else {
+			appLogger.Warnw("Configuration warning", "subject", f.Subject, "warning", f.Message)
+		}
+	}
+	if report.Fatal() {
+		appLogger.Fatalw("Configuration self-check failed; run validate-config for a full report")
+	}
+
+	// apikeys.json, destinations_routes.json and the error catalog are
+	// reloaded on change and on SIGHUP.
+	newReloader(appLogger, apiKeyRepo, routing, tcpClient, router.Routes(), apiKeysPath, routesPath, catalogPath).watch(cfg.Reload.Interval, cfg.ErrorCatalog.ReloadInterval)
+
+	serverAddress := fmt.Sprintf(":%s", cfg.Server.Port)
+	appLogger.Infow("Starting server", "address", serverAddress)
+	if err := router.Run(serverAddress); err != nil {
+		appLogger.Fatalw("Failed to start server", "error", err)
+	}
+}
+
+// envOr returns the environment variable name, or def when it is unset.
+func envOr(name, def string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return def
+}
+
+// configFiles reads --env and --config-dir from args.
+func configFiles(fs *flag.FlagSet, args []string) config.Files {
+	env := fs.String("env", os.Getenv("CONNECTOR_ENV"), "environment whose config set is used, e.g. uat for config.uat.yaml (CONNECTOR_ENV)")
+	configDir := fs.String("config-dir", envOr("CONNECTOR_CONFIG_DIR", config.DefaultDir), "directory holding the config files (CONNECTOR_CONFIG_DIR)")
+	fs.Parse(args)
+	if *env == "" && fs.NArg() > 0 {
+		*env = fs.Arg(0) // go run ./cmd/server [env]
+	}
+	return config.Files{Dir: *configDir, Env: *env}
+}
+
+// newRouter wires the services and handlers of every endpoint.
+func newRouter(cfg *config.Config, appLogger *zap.SugaredLogger, apiKeyRepo *repo_adapter.APIKeyRepository, tcpClient tcp_client_adapter.TCPSocketClient, routing *config.Routing) *gin.Engine {
 	// --- Core Services ---
-	collectionService := service_core.NewCollectionService(cfg, appLogger, tcpClient, dr.Routes, dr.Destinations)
-	agreementService := service_core.NewAgreementService(cfg, appLogger, tcpClient, dr.Routes, dr.Destinations)
-	creditcardService := service_core.NewCreditCardService(cfg, appLogger, tcpClient, dr.Routes, dr.Destinations)
-	commonService := service_core.NewCommonService(cfg, appLogger, tcpClient, dr.Routes, dr.Destinations)
-	selfServiceService := service_core.NewSelfServiceService(cfg, appLogger, tcpClient, dr.Routes, dr.Destinations)
-	registerService := service_core.NewRegisterService(cfg, appLogger, tcpClient, dr.Routes, dr.Destinations)
-	customerLowerService := service_core.NewCustomerLowerService(cfg, appLogger, tcpClient, dr.Routes, dr.Destinations)
-	consentService := service_core.NewConsentService(cfg, appLogger, tcpClient, dr.Routes, dr.Destinations)
-	uhpService := service_core.NewUhpService(cfg, appLogger, tcpClient, dr.Routes, dr.Destinations)
-	mobileService := service_core.NewMobileService(cfg, appLogger, tcpClient, dr.Routes, dr.Destinations)
-	applicationCapService := service_core.NewApplicationCapService(cfg, appLogger, tcpClient, dr.Routes, dr.Destinations)
-	applicationLowerService := service_core.NewApplicationLowerService(cfg, appLogger, tcpClient, dr.Routes, dr.Destinations)
+	collectionService := service_core.NewCollectionService(cfg, appLogger, tcpClient, routing)
+	agreementService := service_core.NewAgreementService(cfg, appLogger, tcpClient, routing)
+	creditcardService := service_core.NewCreditCardService(cfg, appLogger, tcpClient, routing)
+	commonService := service_core.NewCommonService(cfg, appLogger, tcpClient, routing)
+	selfServiceService := service_core.NewSelfServiceService(cfg, appLogger, tcpClient, routing)
+	registerService := service_core.NewRegisterService(cfg, appLogger, tcpClient, routing)
+	customerLowerService := service_core.NewCustomerLowerService(cfg, appLogger, tcpClient, routing)
+	consentService := service_core.NewConsentService(cfg, appLogger, tcpClient, routing)
+	uhpService := service_core.NewUhpService(cfg, appLogger, tcpClient, routing)
+	mobileService := service_core.NewMobileService(cfg, appLogger, tcpClient, routing)
+	applicationCapService := service_core.NewApplicationCapService(cfg, appLogger, tcpClient, routing)
+	applicationLowerService := service_core.NewApplicationLowerService(cfg, appLogger, tcpClient, routing)
+	configuredService := service_core.NewConfiguredService(cfg, appLogger, tcpClient, routing)
 	appLogger.Info("Customer Service initialized with TCP client")
 
 	// --- Handlers (API Layer) ---
@@ -99,13 +238,15 @@ func main() {
 	mobileHandler := handler_adapter.NewMobileHandler(mobileService, appLogger, apiKeyRepo, cfg)
 	applicationCapHandler := handler_adapter.NewApplicationCapHandler(applicationCapService, appLogger, apiKeyRepo, cfg)
 	applicationLowerHandler := handler_adapter.NewApplicationLowerHandler(applicationLowerService, appLogger, apiKeyRepo, cfg)
+	configuredHandler := handler_adapter.NewConfiguredHandler(configuredService, routing, appLogger, apiKeyRepo, cfg)
 
-	appLogger.Info("Setting up router...")
-	router := handler_adapter.SetupRouter(appLogger, apiKeyRepo, collectionHandler, agreementHandler, creditcardHandler, commonHandler, selfServiceHandler, registerHandler, customerLowerHandler, consentHandler, uhpHandler, mobileHandler, applicationCapHandler, applicationLowerHandler)
+	router := handler_adapter.SetupRouter(appLogger, apiKeyRepo, repo_adapter.NewMemoryLimiter(), collectionHandler, agreementHandler, creditcardHandler, commonHandler, selfServiceHandler, registerHandler, customerLowerHandler, consentHandler, uhpHandler, mobileHandler, applicationCapHandler, applicationLowerHandler, configuredHandler)
 
-	serverAddress := fmt.Sprintf(":%s", cfg.Server.Port)
-	appLogger.Infow("Starting server", "address", serverAddress)
-	if err := router.Run(serverAddress); err != nil {
-		appLogger.Fatalw("Failed to start server", "error", err)
+	// The client IP feeds the API key allowlists, so forwarding headers are
+	// only believed from the configured proxies.
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		appLogger.Errorw("Invalid server.trustedProxies; no proxy is trusted", "error", err)
+		router.SetTrustedProxies(nil)
 	}
+	return router
 }
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	repo_adapter "connectorapi-go/internal/adapter/utils"
+	service_core "connectorapi-go/internal/core/service"
+	"connectorapi-go/pkg/config"
+	appError "connectorapi-go/pkg/error"
+	"connectorapi-go/pkg/metrics"
+)
+
+// destinationClient is the part of the TCP client a routing reload updates.
+type destinationClient interface {
+	SetDestinationCodecs(destinations map[string]config.Destination) error
+	ClosePoolsExcept(keep map[string]bool)
+}
+
+// reloader swaps in new versions of apikeys.json, destinations_routes.json
+// and error_catalog.json while the gateway runs. A file that fails to load or
+// has a fatal self-check finding leaves the version in force untouched.
+type reloader struct {
+	logger      *zap.SugaredLogger
+	apiKeys     *repo_adapter.APIKeyRepository
+	routing     *config.Routing
+	client      destinationClient
+	served      gin.RoutesInfo
+	apiKeysPath string
+	routesPath  string
+	catalogPath string
+
+	mu       sync.Mutex
+	versions map[string]string // by path
+}
+
+func newReloader(logger *zap.SugaredLogger, apiKeys *repo_adapter.APIKeyRepository, routing *config.Routing, client destinationClient, served gin.RoutesInfo, apiKeysPath, routesPath, catalogPath string) *reloader {
+	r := &reloader{
+		logger:      logger,
+		apiKeys:     apiKeys,
+		routing:     routing,
+		client:      client,
+		served:      served,
+		apiKeysPath: apiKeysPath,
+		routesPath:  routesPath,
+		catalogPath: catalogPath,
+		versions:    make(map[string]string),
+	}
+	for _, path := range []string{apiKeysPath, routesPath, catalogPath} {
+		if data, err := os.ReadFile(path); err == nil {
+			r.setVersion(path, config.Version(data))
+			logger.Infow("Configuration loaded", "file", filepath.Base(path), "version", r.versions[path])
+		}
+	}
+	return r
+}
+
+// watch reloads a file when it changes, checking every interval (never when
+// interval is 0) and the catalog every catalogInterval when that is set, and
+// reloads all three files on SIGHUP.
+func (r *reloader) watch(interval, catalogInterval time.Duration) {
+	if interval > 0 {
+		config.WatchFile(r.apiKeysPath, interval, nil, r.reloadAPIKeys)
+		config.WatchFile(r.routesPath, interval, nil, r.reloadRoutes)
+	}
+	if catalogInterval <= 0 {
+		catalogInterval = interval
+	}
+	if catalogInterval > 0 {
+		config.WatchFile(r.catalogPath, catalogInterval, nil, r.reloadCatalog)
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			r.logger.Info("SIGHUP received, reloading configuration")
+			r.reloadAPIKeys()
+			r.reloadRoutes()
+			r.reloadCatalog()
+		}
+	}()
+}
+
+func (r *reloader) reloadAPIKeys() {
+	r.reload(r.apiKeysPath, func(data []byte) error {
+		apiKeys, err := config.ParseAPIKeys(data)
+		if err != nil {
+			return err
+		}
+		if err := r.check(service_core.CheckAPIKeys(apiKeys, r.served)); err != nil {
+			return err
+		}
+		r.apiKeys.Replace(apiKeys)
+		return nil
+	})
+}
+
+func (r *reloader) reloadRoutes() {
+	r.reload(r.routesPath, func(data []byte) error {
+		dr, err := config.ParseDestinationsAndRoutes(data)
+		if err != nil {
+			return err
+		}
+		dr.ApplyEnv(os.LookupEnv)
+		if err := r.check(service_core.CheckRouting(dr, r.served)); err != nil {
+			return err
+		}
+		if err := r.client.SetDestinationCodecs(dr.Destinations); err != nil {
+			return err
+		}
+		r.routing.Store(dr)
+		// Listeners the new routing dropped keep no connections or breakers.
+		addresses := dr.Addresses()
+		r.client.ClosePoolsExcept(addresses)
+		repo_adapter.DropBreakersExcept(addresses)
+		return nil
+	})
+}
+
+func (r *reloader) reloadCatalog() {
+	r.reload(r.catalogPath, func(data []byte) error {
+		catalog, err := appError.ParseCatalog(data)
+		if err != nil {
+			return err
+		}
+		appError.SetCatalog(catalog)
+		return nil
+	})
+}
+
+// reload reads path and, when its contents changed, hands them to apply,
+// which validates them and swaps them in.
+func (r *reloader) reload(path string, apply func(data []byte) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	file := filepath.Base(path)
+	previous := r.versions[path]
+	data, err := os.ReadFile(path)
+	if err == nil {
+		version := config.Version(data)
+		if version == previous {
+			metrics.ConfigReloadsTotal.WithLabelValues(file, "unchanged").Inc()
+			r.logger.Infow("Configuration unchanged", "file", file, "version", version)
+			return
+		}
+		if err = apply(data); err == nil {
+			r.setVersion(path, version)
+			metrics.ConfigReloadsTotal.WithLabelValues(file, "success").Inc()
+			r.logger.Infow("Configuration reloaded", "file", file, "version", version, "previous", previous)
+			return
+		}
+	}
+	metrics.ConfigReloadsTotal.WithLabelValues(file, "failure").Inc()
+	r.logger.Errorw("Configuration reload failed; keeping the current version", "file", file, "version", previous, "error", err)
+}
+
+// check logs the warnings of a self-check report and returns its fatal
+// findings as an error.
+func (r *reloader) check(report service_core.Report) error {
+	for _, f := range report {
+		if !f.Fatal {
+			r.logger.Warnw("Configuration warning", "subject", f.Subject, "warning", f.Message)
+		}
+	}
+	return report.Err()
+}
+
+func (r *reloader) setVersion(path, version string) {
+	file := filepath.Base(path)
+	if previous, ok := r.versions[path]; ok {
+		metrics.ConfigVersion.DeleteLabelValues(file, previous)
+	}
+	r.versions[path] = version
+	metrics.ConfigVersion.WithLabelValues(file, version).Set(1)
+}
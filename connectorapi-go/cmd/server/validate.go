@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	tcp_client_adapter "connectorapi-go/internal/adapter/client"
+	repo_adapter "connectorapi-go/internal/adapter/utils"
+	service_core "connectorapi-go/internal/core/service"
+	format_core "connectorapi-go/internal/core/service/format"
+	"connectorapi-go/pkg/config"
+	appError "connectorapi-go/pkg/error"
+)
+
+// validateConfig implements "connector-api validate-config [--env env]
+// [--config-dir dir]": it loads the selected config set, builds the router
+// the server would serve and prints the startup self-check. It returns the
+// exit status, 1 when a file does not load or a finding is fatal.
+func validateConfig(args []string) int {
+	files := configFiles(flag.NewFlagSet("validate-config", flag.ExitOnError), args)
+	return checkConfigSet(os.Stdout, files)
+}
+
+func checkConfigSet(out io.Writer, files config.Files) int {
+	var report service_core.Report
+	loadFailed := func(path string, err error) {
+		report = append(report, service_core.Finding{Fatal: true, Subject: "file " + path, Message: err.Error()})
+	}
+
+	cfg, err := config.Load(files.Config())
+	if err != nil {
+		loadFailed(files.Config(), err)
+		cfg = &config.Config{}
+	}
+	cfg.ApplyEnv(os.LookupEnv)
+	apiKeys, err := config.LoadAPIKeys(files.APIKeys())
+	if err != nil {
+		loadFailed(files.APIKeys(), err)
+	}
+	dr, err := config.LoadDestinationsAndRoutes(files.Routes())
+	if err != nil {
+		loadFailed(files.Routes(), err)
+		dr = &config.DestinationsAndRoutes{}
+	}
+	dr.ApplyEnv(os.LookupEnv)
+	if err := format_core.CheckLayouts(); err != nil {
+		loadFailed("embedded layouts", err)
+	}
+	if cfg.LayoutDir != "" {
+		if err := format_core.LoadLayouts(cfg.LayoutDir); err != nil {
+			loadFailed(cfg.LayoutDir, err)
+		}
+	}
+	catalogPath := cfg.ErrorCatalog.Path
+	if catalogPath == "" {
+		catalogPath = files.ErrorCatalog()
+	}
+	if _, err := appError.LoadCatalog(catalogPath); err != nil {
+		loadFailed(catalogPath, err)
+	}
+	for _, path := range []string{files.APIKeys(), catalogPath} {
+		if files.Shared(path) {
+			report = append(report, service_core.Finding{Subject: "file " + path, Message: "shared file used; environment " + files.Env + " has none of its own"})
+		}
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	logger := zap.NewNop().Sugar()
+	tcpClient := tcp_client_adapter.NewBasicTCPSocketClient(0, 0)
+	router := newRouter(cfg, logger, repo_adapter.NewAPIKeyRepository(apiKeys), tcpClient, config.NewRouting(dr))
+	report = append(report, service_core.CheckRouting(dr, router.Routes())...)
+	report = append(report, service_core.CheckAPIKeys(apiKeys, router.Routes())...)
+
+	fmt.Fprintf(out, "config  %s\napikeys %s\nroutes  %s\ncatalog %s\n\n", files.Config(), files.APIKeys(), files.Routes(), catalogPath)
+	fmt.Fprint(out, report.String())
+	fatal := 0
+	for _, f := range report {
+		if f.Fatal {
+			fatal++
+		}
+	}
+	fmt.Fprintf(out, "\n%d fatal, %d warnings\n", fatal, len(report)-fatal)
+	if fatal > 0 {
+		return 1
+	}
+	return 0
+}
@@ -11,10 +11,19 @@ import (
 	"strings"
 
 	appError "connectorapi-go/pkg/error"
+	"connectorapi-go/pkg/tracing"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
+// AuthRuleKey is the gin context key holding the API key rule that allowed or
+// refused the request, recorded as AuthRule in the main log.
+const AuthRuleKey = "Api-AuthRule"
+
+// AuthCheckKey holds the API key check that refused the request (key, ip,
+// channel, deviceOS or permission), recorded as AuthCheck.
+const AuthCheckKey = "Api-AuthCheck"
+
 type LogMainData struct {
 	TIMESTAMP			string				`json:"TIMESTAMP"`
 	LOGLEVEL			string				`json:"LOGLEVEL"`
@@ -32,6 +41,8 @@ type LogMainData struct {
 	Status				string				`json:"Status"`
 	ErrorCode			string				`json:"ErrorCode,omitempty"`
 	ErrorMessage		string				`json:"ErrorMessage,omitempty"`
+	AuthRule			string				`json:"AuthRule,omitempty"`
+	AuthCheck			string				`json:"AuthCheck,omitempty"`
 	UsedTime			string				`json:"UsedTime"`
 	ServerName			string				`json:"ServerName"`
 	SeqNo				string				`json:"SeqNo"`
@@ -49,6 +60,7 @@ type LogLineData struct {
 	TraceID				string				`json:"TraceID"`
 	SourceIP			string				`json:"SourceIP"`
 	DestIP				string				`json:"DestIP"`
+	DestPort			string				`json:"DestPort,omitempty"`
 	SourceHostname		string				`json:"SourceHostname"`
 	DestHostname		string				`json:"DestHostname"`
 	Method				string				`json:"Method"`
@@ -94,6 +106,10 @@ func GenerateELKLogMain(c *gin.Context, timesRequest time.Time, request interfac
 		response = ""
 	}
 
+	masker := activeMasker()
+	route := routeOf(c)
+	request = masker.Message(route, request, c.GetString(RequestLayoutKey))
+
 	var responseFormat interface{} = response
 	if appErr != nil {
 		responseFormat = ResponseMessageFormat{
@@ -101,14 +117,14 @@ func GenerateELKLogMain(c *gin.Context, timesRequest time.Time, request interfac
 			ErrorMessage: appErr.ErrorMessage,
 		}
 	} else {
-		responseFormat = response
+		responseFormat = masker.Message(route, response, c.GetString(ResponseLayoutKey))
 	}
 
 	logData := LogMainData{
 		TIMESTAMP:        formattedLogTimestamp,
 		LOGLEVEL:         "INFO",
 		RequestID:        c.GetHeader("Api-RequestID"),
-		TraceID:          "",
+		TraceID:          traceID(c),
 		SourceIP:         "0.0.0.0",
 		DestIP:           GetLocalIP(),
 		SourceHostname:   "Unknown host",
@@ -122,8 +138,10 @@ func GenerateELKLogMain(c *gin.Context, timesRequest time.Time, request interfac
 		ServerName:       "ConnectorAPI",
 		SeqNo:            "0",
 		Header:           extractHeader(c, "", "main"),
-		UserToken:		  userToken,
-		UserRef:          userRef,
+		UserToken:		  masker.Field(route, "UserToken", userToken),
+		UserRef:          masker.Field(route, "UserRef", userRef),
+		AuthRule:         c.GetString(AuthRuleKey),
+		AuthCheck:        c.GetString(AuthCheckKey),
 		RequestDateTime:  formattedRequestTimestamp,
 		RequestMessage:   request,
 		ResponseDateTime: formattedCurrentTimestamp,
@@ -176,7 +194,7 @@ func GenerateELKLogLine(c *gin.Context, timesRequest time.Time, request interfac
 	// if len(parts) > 0 {
 	// 	domain = parts[0]
 	// }
-	destIP := strings.Split(endpoint, ":")[0]
+	destIP, destPort, _ := strings.Cut(endpoint, ":")
 
 	if request == nil {
 		request = ""
@@ -184,12 +202,17 @@ func GenerateELKLogLine(c *gin.Context, timesRequest time.Time, request interfac
 	if response == nil {
 		response = ""
 	}
+	masker := activeMasker()
+	route := routeOf(c)
+	request = masker.Message(route, request, c.GetString(RequestLayoutKey))
+	response = masker.Message(route, response, c.GetString(ResponseLayoutKey))
 
 	logData := LogLineData{
 		RequestID:        c.GetHeader("Api-RequestID"),
-		TraceID:          "",
+		TraceID:          traceID(c),
 		SourceIP:         GetLocalIP(),
 		DestIP:           destIP,
+		DestPort:         destPort,
 		SourceHostname:   "ConnectorAPI",
 		DestHostname:     "",
 		Method:           "TCP",
@@ -201,8 +224,8 @@ func GenerateELKLogLine(c *gin.Context, timesRequest time.Time, request interfac
 		ServerName:       "ConnectorAPI",
 		SeqNo:            seqNo,
 		Header:           extractHeader(c, apikey, "line"),
-		UserToken:		  userToken,
-		UserRef:          userRef,
+		UserToken:		  masker.Field(route, "UserToken", userToken),
+		UserRef:          masker.Field(route, "UserRef", userRef),
 		RequestDateTime:  formattedRequestTimestamp,
 		RequestMessage:   request,
 		ResponseDateTime: formattedCurrentTimestamp,
@@ -278,6 +301,15 @@ func extractHeader(c *gin.Context, apikey string, logType string) string {
 	return header
 }
 
+// traceID is the OpenTelemetry trace of the request, continued from the
+// caller's traceparent header when it sent one.
+func traceID(c *gin.Context) string {
+	if c.Request == nil {
+		return ""
+	}
+	return tracing.TraceID(c.Request.Context())
+}
+
 func GetLocalIP() string {
     addrs, err := net.InterfaceAddrs()
     if err != nil {
@@ -368,6 +400,10 @@ func WriteLogToFile(logLines []string, timestamp time.Time, elkPath string) erro
 
 type HandleErrorResponse func(c *gin.Context, appErr *appError.AppError)
 
+// FinalELKLog writes the main log line of a request followed by its other
+// lines. A logging failure is reported to logger and never fails the
+// response, so it always returns true; the result and handleErrorResponse
+// are kept for the handlers that check it.
 func FinalELKLog(
 	c *gin.Context,
 	logList *[]string,
@@ -385,9 +421,7 @@ func FinalELKLog(
 ) bool {
 	logMain := GenerateELKLogMain(c, timestamp, reqBody, respBody, appErr, serviceName, userToken, userRef)
 	if logMain == "" {
-		logger.Errorw("Error generating log: %v", logMain)
-		handleErrorResponse(c, appError.ErrInternalServer)
-		return false
+		logger.Errorw("Error generating ELK main log", "service", serviceName)
 	}
 
 	allLogs := []string{logMain}
@@ -400,10 +434,8 @@ func FinalELKLog(
 		allLogs = append(allLogs, additionalLines...)
 	}
 
-	if err := WriteLogToFile(allLogs, timestamp, elkPath); err != nil {
-		logger.Errorw("Error writing log file:", err)
-		handleErrorResponse(c, appError.ErrInternalServer)
-		return false
+	if err := writeLines(allLogs, timestamp, elkPath); err != nil {
+		logger.Warnw("ELK log not written", "service", serviceName, "error", err)
 	}
 
 	return true
@@ -0,0 +1,308 @@
+package elk
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"connectorapi-go/pkg/config"
+	"connectorapi-go/pkg/layout"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestLayoutKey and ResponseLayoutKey are the gin context keys naming the
+// record layouts of the System-I request and response of the current request,
+// used to mask fixed-length messages field by field.
+const (
+	RequestLayoutKey  = "Api-RequestLayout"
+	ResponseLayoutKey = "Api-ResponseLayout"
+)
+
+// systemIHeaderLen is the length of the System-I header in front of every
+// fixed-length message; it holds no personal data and is never masked.
+const systemIHeaderLen = 123
+
+// noLayoutDigits is the default config.MaskingConfig.NoLayout action.
+const noLayoutDigits = "digits"
+
+// defaultMaskFields is the built-in policy, by lower-case field name.
+// UserRef is the ELK field that usually carries the ID card number.
+var defaultMaskFields = map[string]string{
+	"idcardno":                 config.MaskLast4,
+	"suppidcardno":             config.MaskLast4,
+	"passportno":               config.MaskLast4,
+	"userref":                  config.MaskLast4,
+	"cardno":                   config.MaskLast4,
+	"creditcardno":             config.MaskLast4,
+	"primarycreditcard":        config.MaskLast4,
+	"accountno":                config.MaskLast4,
+	"refaccountno":             config.MaskLast4,
+	"autopayaccountno":         config.MaskLast4,
+	"birthdate":                config.MaskAll,
+	"suppbirthdate":            config.MaskAll,
+	"salary":                   config.MaskAll,
+	"otherincome":              config.MaskAll,
+	"mobileno":                 config.MaskAll,
+	"homephone":                config.MaskAll,
+	"officephone":              config.MaskAll,
+	"officemobilephone":        config.MaskAll,
+	"spousephone":              config.MaskAll,
+	"referencephone":           config.MaskAll,
+	"debtreferencephone":       config.MaskAll,
+	"debtreferencemobilephone": config.MaskAll,
+	"email":                    config.MaskAll,
+	"emailaddress":             config.MaskAll,
+}
+
+// digitRun matches card and ID card numbers in data without a layout.
+var digitRun = regexp.MustCompile(`\d{13,19}`)
+
+// Masker applies a config.MaskingConfig to the messages of the ELK log.
+type Masker struct {
+	disabled bool
+	noLayout string
+	fields   map[string]string
+	routes   map[string]map[string]string
+	layout   func(name string) (*layout.Layout, bool)
+}
+
+// NewMasker builds the masker of cfg on top of the built-in policy. lookup
+// finds the record layouts named by RequestLayoutKey and ResponseLayoutKey;
+// with a nil lookup all fixed-length data is handled per cfg.NoLayout.
+func NewMasker(cfg config.MaskingConfig, lookup func(name string) (*layout.Layout, bool)) *Masker {
+	m := &Masker{
+		disabled: cfg.Disabled,
+		noLayout: cfg.NoLayout,
+		fields:   make(map[string]string, len(defaultMaskFields)+len(cfg.Fields)),
+		routes:   make(map[string]map[string]string, len(cfg.Routes)),
+		layout:   lookup,
+	}
+	if m.noLayout == "" {
+		m.noLayout = noLayoutDigits
+	}
+	for name, action := range defaultMaskFields {
+		m.fields[name] = action
+	}
+	for name, action := range cfg.Fields {
+		m.fields[strings.ToLower(name)] = action
+	}
+	for route, fields := range cfg.Routes {
+		m.routes[route] = make(map[string]string, len(fields))
+		for name, action := range fields {
+			m.routes[route][strings.ToLower(name)] = action
+		}
+	}
+	return m
+}
+
+var (
+	currentMasker atomic.Pointer[Masker]
+	defaultMasker = NewMasker(config.MaskingConfig{}, nil)
+)
+
+// SetMasker makes the ELK log mask its messages with m. Until it is called the
+// built-in policy applies, without record layouts.
+func SetMasker(m *Masker) {
+	currentMasker.Store(m)
+}
+
+func activeMasker() *Masker {
+	if m := currentMasker.Load(); m != nil {
+		return m
+	}
+	return defaultMasker
+}
+
+// MaskPayload masks a System-I message of the current request for logging;
+// layoutKey is RequestLayoutKey or ResponseLayoutKey.
+func MaskPayload(c *gin.Context, layoutKey string, data string) string {
+	return activeMasker().Fixed(routeOf(c), c.GetString(layoutKey), data)
+}
+
+func routeOf(c *gin.Context) string {
+	if c.Request == nil {
+		return ""
+	}
+	return c.Request.Method + ":" + c.FullPath()
+}
+
+// action returns the action for field name on route; "" when it is logged
+// as it is.
+func (m *Masker) action(route, name string) string {
+	name = strings.ToLower(name)
+	action, ok := m.routes[route][name]
+	if !ok {
+		action = m.fields[name]
+	}
+	if action == config.MaskNone {
+		return ""
+	}
+	return action
+}
+
+// Field masks the value of a single field, such as UserRef.
+func (m *Masker) Field(route, name, value string) string {
+	if m.disabled || value == "" {
+		return value
+	}
+	switch action := m.action(route, name); action {
+	case "":
+		return value
+	case config.MaskDrop:
+		return ""
+	default:
+		return maskText(action, value)
+	}
+}
+
+// Message masks a logged request or response. v is encoded as JSON and every
+// field with an action is masked wherever it appears; a string under the key
+// "data" is System-I fixed-length data and is masked by Fixed with the named
+// layout.
+func (m *Masker) Message(route string, v interface{}, layoutName string) interface{} {
+	if m.disabled || v == nil {
+		return v
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return v
+	}
+	return m.walk(route, layoutName, generic)
+}
+
+func (m *Masker) walk(route, layoutName string, v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, x := range v {
+			switch action := m.action(route, k); action {
+			case "":
+				if s, ok := x.(string); ok && k == "data" {
+					v[k] = m.Fixed(route, layoutName, s)
+				} else {
+					v[k] = m.walk(route, layoutName, x)
+				}
+			case config.MaskDrop:
+				delete(v, k)
+			default:
+				v[k] = maskLeaves(action, x)
+			}
+		}
+	case []interface{}:
+		for i, x := range v {
+			v[i] = m.walk(route, layoutName, x)
+		}
+	}
+	return v
+}
+
+// maskLeaves masks every string and number in v.
+func maskLeaves(action string, v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return maskText(action, v)
+	case json.Number:
+		return maskText(action, v.String())
+	case map[string]interface{}:
+		for k, x := range v {
+			v[k] = maskLeaves(action, x)
+		}
+	case []interface{}:
+		for i, x := range v {
+			v[i] = maskLeaves(action, x)
+		}
+	}
+	return v
+}
+
+// Fixed masks System-I fixed-length data: the header is kept and the body is
+// masked field by field with the named layout, or per the NoLayout action when
+// there is no such layout. Every field keeps its length.
+func (m *Masker) Fixed(route, layoutName, data string) string {
+	if m.disabled {
+		return data
+	}
+	runes := []rune(data)
+	if len(runes) <= systemIHeaderLen {
+		return m.unstructured(data)
+	}
+	header, body := string(runes[:systemIHeaderLen]), string(runes[systemIHeaderLen:])
+	if m.layout != nil {
+		if l, ok := m.layout(layoutName); ok {
+			return header + l.Rewrite(body, func(f layout.Field, text string) string {
+				if action := m.action(route, f.Name); action != "" {
+					return maskFixed(action, text)
+				}
+				return text
+			})
+		}
+	}
+	if m.noLayout == config.MaskDrop {
+		return header
+	}
+	return header + m.unstructured(body)
+}
+
+func (m *Masker) unstructured(data string) string {
+	if m.noLayout != noLayoutDigits {
+		return data
+	}
+	return digitRun.ReplaceAllStringFunc(data, func(s string) string {
+		return maskText(config.MaskLast4, s)
+	})
+}
+
+// maskText applies action to a JSON value. Values of up to four characters
+// are masked completely by last4.
+func maskText(action, s string) string {
+	switch action {
+	case config.MaskAll:
+		return strings.Repeat("*", len([]rune(s)))
+	case config.MaskLast4:
+		runes := []rune(s)
+		if len(runes) <= 4 {
+			return strings.Repeat("*", len(runes))
+		}
+		return strings.Repeat("*", len(runes)-4) + string(runes[len(runes)-4:])
+	case config.MaskHash:
+		return "sha256:" + digest(s)[:16]
+	}
+	return ""
+}
+
+// maskFixed applies action to the text of a fixed-length field, padding
+// included. Padding is kept so the masked value lines up like the original;
+// a hash is written as bare hex, cut to the field length by Rewrite.
+func maskFixed(action, text string) string {
+	value := strings.TrimSpace(text)
+	if value == "" {
+		return text
+	}
+	switch action {
+	case config.MaskDrop:
+		return ""
+	case config.MaskHash:
+		return digest(value)
+	}
+	start := strings.Index(text, value)
+	return text[:start] + maskText(action, value) + text[start+len(value):]
+}
+
+// digest is the hex SHA-256 of s.
+func digest(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
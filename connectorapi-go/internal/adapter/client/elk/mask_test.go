@@ -0,0 +1,123 @@
+package elk
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"connectorapi-go/pkg/config"
+	"connectorapi-go/pkg/layout"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testLayouts = `
+layouts:
+  - name: PanResponse
+    fields:
+      - {name: IDCardNo, type: string, length: 20}
+      - {name: TotalCard, type: int, length: 2}
+      - name: Cards
+        type: group
+        count: TotalCard
+        fields:
+          - {name: CardNo, type: string, length: 16}
+          - {name: CardType, type: string, length: 2}
+`
+
+func testLookup(t *testing.T) func(string) (*layout.Layout, bool) {
+	t.Helper()
+	reg := layout.NewRegistry()
+	layouts, err := layout.Parse([]byte(testLayouts))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range layouts {
+		if err := reg.Add(l); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return reg.Get
+}
+
+func TestMaskerMessage(t *testing.T) {
+	m := NewMasker(config.MaskingConfig{
+		Fields: map[string]string{"AeonID": config.MaskHash},
+		Routes: map[string]map[string]string{
+			"POST:/Api/Test": {"MobileNo": config.MaskDrop, "IDCardNo": config.MaskNone},
+		},
+	}, nil)
+	req := struct {
+		AeonID   string
+		IDCardNo string
+		MobileNo string
+		Amount   float64
+		Cards    []struct{ CardNo string }
+	}{AeonID: "A1", IDCardNo: "1234567890123", MobileNo: "0812345678", Amount: 10.5, Cards: []struct{ CardNo string }{{"4111111111111111"}}}
+
+	got, _ := json.Marshal(m.Message("GET:/Api/Other", req, ""))
+	want := `{"AeonID":"sha256:` + digest("A1")[:16] + `","Amount":10.5,"Cards":[{"CardNo":"************1111"}],"IDCardNo":"*********0123","MobileNo":"**********"}`
+	if string(got) != want {
+		t.Errorf("Message = %s\nwant      %s", got, want)
+	}
+
+	// The route overrides drop MobileNo and keep IDCardNo.
+	got, _ = json.Marshal(m.Message("POST:/Api/Test", req, ""))
+	if s := string(got); strings.Contains(s, "MobileNo") || !strings.Contains(s, `"IDCardNo":"1234567890123"`) {
+		t.Errorf("Message with route overrides = %s", s)
+	}
+
+	if got := m.Field("GET:/Api/Other", "UserRef", "1234567890123"); got != "*********0123" {
+		t.Errorf("Field(UserRef) = %q", got)
+	}
+}
+
+func TestMaskerFixed(t *testing.T) {
+	header := strings.Repeat("H", systemIHeaderLen)
+	body := "1234567890123       02" + "4111111111111111CR" + "5500000000000004DB"
+	m := NewMasker(config.MaskingConfig{}, testLookup(t))
+
+	got := m.Fixed("", "PanResponse", header+body)
+	want := header + "*********0123       02" + "************1111CR" + "************0004DB"
+	if got != want {
+		t.Errorf("Fixed with layout = %q\nwant               %q", got, want)
+	}
+
+	// Without a layout only card and ID card length digit runs are masked.
+	if got := m.Fixed("", "", header+"ABC4111111111111111 20261018"); got != header+"ABC************1111 20261018" {
+		t.Errorf("Fixed without layout = %q", got)
+	}
+
+	drop := NewMasker(config.MaskingConfig{NoLayout: config.MaskDrop}, nil)
+	if got := drop.Fixed("", "PanResponse", header+body); got != header {
+		t.Errorf("Fixed with noLayout drop = %q", got)
+	}
+
+	off := NewMasker(config.MaskingConfig{Disabled: true}, testLookup(t))
+	if got := off.Fixed("", "PanResponse", header+body); got != header+body {
+		t.Errorf("Fixed while disabled = %q", got)
+	}
+}
+
+func TestGenerateELKLogLineMasks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	SetMasker(NewMasker(config.MaskingConfig{}, testLookup(t)))
+	defer SetMasker(nil)
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/Api/Mobile/MobileFullPAN", nil)
+	c.Set(ResponseLayoutKey, "PanResponse")
+	header := strings.Repeat("H", systemIHeaderLen)
+	resp := map[string]string{"data": header + "1234567890123       01" + "4111111111111111CR"}
+
+	line := GenerateELKLogLine(c, time.Now(), map[string]string{"data": header}, resp, nil, "", "127.0.0.1:9000", "MobileFullPan", "MobileFullPan", "", "1234567890123")
+	if strings.Contains(line, "4111111111111111") || strings.Contains(line, "1234567890123") {
+		t.Errorf("log line holds clear data: %s", line)
+	}
+	if !strings.Contains(line, "************1111CR") || !strings.Contains(line, `"UserRef":"*********0123"`) {
+		t.Errorf("log line not masked as expected: %s", line)
+	}
+}
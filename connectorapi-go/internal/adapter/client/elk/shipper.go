@@ -0,0 +1,412 @@
+package elk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"connectorapi-go/pkg/config"
+	"connectorapi-go/pkg/metrics"
+
+	"go.uber.org/zap"
+)
+
+// shipperQueueSize is the number of batches a shipper holds in memory; a
+// batch that finds the queue full goes straight to the spool.
+const shipperQueueSize = 64
+
+// shipper sends the batches of the Writer to one remote sink from a goroutine
+// of its own, so a slow or broken store never holds up the files. A batch is
+// retried MaxRetries times with a doubling backoff and then spooled to disk;
+// while the sink is down new batches are spooled at once, and the spool is
+// replayed, oldest first, every backoff period until the sink takes it.
+type shipper struct {
+	name   string
+	sink   sink
+	cfg    config.SinkConfig
+	spool  *spool
+	logger *zap.SugaredLogger
+	now    func() time.Time
+
+	queue     chan []string
+	closing   chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+
+	down bool          // the last send failed; new batches are spooled
+	wait time.Duration // until the next replay of the spool
+}
+
+// newShipper starts the shipper of cfg. Unset fields default to a 5s
+// timeout, 3 retries (a negative MaxRetries spools at once) and a backoff
+// from 500ms to 30s, spooling in dir/spool/<name>.
+func newShipper(dir string, cfg config.SinkConfig, logger *zap.SugaredLogger) (*shipper, error) {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = 3
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 500 * time.Millisecond
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 30 * time.Second
+	}
+	if cfg.MaxBackoff < cfg.RetryBackoff {
+		cfg.MaxBackoff = cfg.RetryBackoff
+	}
+	if cfg.SpoolDir == "" {
+		cfg.SpoolDir = filepath.Join(dir, "spool", cfg.SinkName())
+	}
+	sk, err := newSink(cfg)
+	if err != nil {
+		return nil, err
+	}
+	sp, err := openSpool(cfg.SpoolDir, cfg.SinkName(), int64(cfg.SpoolMaxMB)<<20)
+	if err != nil {
+		return nil, fmt.Errorf("elk sink %s: %w", cfg.SinkName(), err)
+	}
+	s := &shipper{
+		name:    cfg.SinkName(),
+		sink:    sk,
+		cfg:     cfg,
+		spool:   sp,
+		logger:  logger,
+		now:     time.Now,
+		queue:   make(chan []string, shipperQueueSize),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+		wait:    cfg.RetryBackoff,
+	}
+	// Lines spooled by a previous run go out before new ones.
+	s.down = !sp.empty()
+	go s.run()
+	return s, nil
+}
+
+// enqueue hands a copy of batch to the shipper without blocking.
+func (s *shipper) enqueue(batch []string) {
+	lines := append([]string(nil), batch...)
+	select {
+	case s.queue <- lines:
+	default:
+		s.spoolLines(lines)
+	}
+}
+
+// close ships or spools the queued batches and closes the sink.
+func (s *shipper) close() {
+	s.closeOnce.Do(func() { close(s.closing) })
+	<-s.done
+}
+
+func (s *shipper) run() {
+	defer close(s.done)
+	timer := time.NewTimer(s.wait)
+	defer timer.Stop()
+	for {
+		select {
+		case lines := <-s.queue:
+			s.ship(lines)
+		case <-timer.C:
+			s.replay()
+			timer.Reset(s.wait)
+		case <-s.closing:
+			for len(s.queue) > 0 {
+				s.ship(<-s.queue)
+			}
+			s.sink.Close()
+			return
+		}
+	}
+}
+
+// ship sends one batch, retrying with backoff, and spools what is left when
+// the sink stays down. Once closing, a batch is tried only once.
+func (s *shipper) ship(lines []string) {
+	if s.down {
+		s.spoolLines(lines)
+		return
+	}
+	entries := s.entries(lines)
+	backoff := s.cfg.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		var err error
+		entries, err = s.send(entries, "shipped")
+		if err == nil {
+			return
+		}
+		if attempt >= s.cfg.MaxRetries || s.isClosing() {
+			s.markDown(err, backoff)
+			s.spoolEntries(entries)
+			return
+		}
+		select {
+		case <-time.After(backoff):
+		case <-s.closing:
+		}
+		backoff = s.nextBackoff(backoff)
+	}
+}
+
+// send makes one attempt at entries, counting those taken as result, and
+// returns those still to be sent.
+func (s *shipper) send(entries []entry, result string) ([]entry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Timeout)
+	defer cancel()
+	err := s.sink.Send(ctx, entries)
+	var pe *partialError
+	switch {
+	case err == nil:
+		countSinkLines(s.name, result, len(entries))
+		return nil, nil
+	case errors.As(err, &pe):
+		countSinkLines(s.name, result, len(entries)-len(pe.retry)-pe.rejected)
+		if pe.rejected > 0 {
+			countSinkLines(s.name, "rejected", pe.rejected)
+			s.logger.Warnw("ELK sink rejected lines", "sink", s.name, "lines", pe.rejected, "error", err)
+		}
+		if len(pe.retry) == 0 {
+			return nil, nil
+		}
+		return pe.retry, err
+	}
+	return entries, err
+}
+
+// replay sends the spooled batches, oldest first, until the spool is empty
+// or a send fails; after a failure the next replay waits twice as long.
+func (s *shipper) replay() {
+	for !s.spool.empty() && !s.isClosing() {
+		path, lines, err := s.spool.oldest()
+		if err != nil {
+			s.logger.Errorw("ELK spool unreadable; dropping batch", "sink", s.name, "file", path, "error", err)
+			s.spool.remove(path, true)
+			continue
+		}
+		left, err := s.send(s.entries(lines), "replayed")
+		if err != nil {
+			if len(left) < len(lines) {
+				s.spoolEntries(left)
+				s.spool.remove(path, false)
+			}
+			s.markDown(err, s.nextBackoff(s.wait))
+			return
+		}
+		s.spool.remove(path, false)
+	}
+	if s.down && s.spool.empty() {
+		s.logger.Infow("ELK sink recovered; spool replayed", "sink", s.name)
+		s.down = false
+	}
+	s.wait = s.cfg.RetryBackoff
+}
+
+// markDown records a failed send; the first failure of a streak is logged.
+func (s *shipper) markDown(err error, wait time.Duration) {
+	if !s.down {
+		s.logger.Warnw("ELK sink down; spooling lines", "sink", s.name, "spool", s.spool.dir, "error", err)
+		s.down = true
+	}
+	s.wait = wait
+}
+
+func (s *shipper) nextBackoff(d time.Duration) time.Duration {
+	d *= 2
+	if d > s.cfg.MaxBackoff {
+		d = s.cfg.MaxBackoff
+	}
+	return d
+}
+
+func (s *shipper) isClosing() bool {
+	select {
+	case <-s.closing:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *shipper) entries(lines []string) []entry {
+	now := s.now()
+	entries := make([]entry, len(lines))
+	for i, line := range lines {
+		entries[i] = parseEntry(line, now)
+	}
+	return entries
+}
+
+func (s *shipper) spoolEntries(entries []entry) {
+	lines := make([]string, len(entries))
+	for i, e := range entries {
+		lines[i] = e.line
+	}
+	s.spoolLines(lines)
+}
+
+func (s *shipper) spoolLines(lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	if err := s.spool.put(lines); err != nil {
+		s.logger.Errorw("ELK spool write failed; lines dropped", "sink", s.name, "lines", len(lines), "error", err)
+		countSinkLines(s.name, "dropped_spool_error", len(lines))
+		return
+	}
+	countSinkLines(s.name, "spooled", len(lines))
+}
+
+// spool keeps the batches a sink could not take as files in dir, one batch
+// of log lines per file, named so that they sort oldest first and carry their
+// line count: <unix nanoseconds>-<sequence>-<lines>.log.
+type spool struct {
+	dir  string
+	sink string
+	max  int64 // bytes; 0 is unlimited
+
+	mu    sync.Mutex
+	seq   int
+	files []spoolFile
+	size  int64
+}
+
+type spoolFile struct {
+	name  string
+	size  int64
+	lines int
+}
+
+func openSpool(dir, sinkName string, max int64) (*spool, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	sp := &spool{dir: dir, sink: sinkName, max: max}
+	for _, e := range entries {
+		lines, ok := spoolLineCount(e.Name())
+		if e.IsDir() || !ok {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		sp.files = append(sp.files, spoolFile{e.Name(), info.Size(), lines})
+		sp.size += info.Size()
+	}
+	sort.Slice(sp.files, func(i, j int) bool { return sp.files[i].name < sp.files[j].name })
+	sp.setSize()
+	return sp, nil
+}
+
+// spoolLineCount returns the line count in the name of a spool file.
+func spoolLineCount(name string) (int, bool) {
+	if !strings.HasSuffix(name, ".log") {
+		return 0, false
+	}
+	i := strings.LastIndexByte(name, '-')
+	if i < 0 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(name[i+1 : len(name)-len(".log")])
+	return n, err == nil
+}
+
+func (sp *spool) empty() bool {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	return len(sp.files) == 0
+}
+
+// put writes a batch as the newest file, then drops the oldest files while
+// the spool is over its limit.
+func (sp *spool) put(lines []string) error {
+	var buf strings.Builder
+	for _, line := range lines {
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	sp.seq++
+	name := fmt.Sprintf("%019d-%06d-%d.log", time.Now().UnixNano(), sp.seq%1000000, len(lines))
+	tmp := filepath.Join(sp.dir, name+".tmp")
+	if err := os.WriteFile(tmp, []byte(buf.String()), 0o644); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(sp.dir, name)); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	sp.files = append(sp.files, spoolFile{name, int64(buf.Len()), len(lines)})
+	sp.size += int64(buf.Len())
+	for sp.max > 0 && sp.size > sp.max && len(sp.files) > 1 {
+		oldest := sp.files[0]
+		if err := os.Remove(filepath.Join(sp.dir, oldest.name)); err != nil && !os.IsNotExist(err) {
+			break
+		}
+		sp.files = sp.files[1:]
+		sp.size -= oldest.size
+		countSinkLines(sp.sink, "dropped_spool_full", oldest.lines)
+	}
+	sp.setSize()
+	return nil
+}
+
+// oldest returns the path and lines of the oldest batch.
+func (sp *spool) oldest() (string, []string, error) {
+	sp.mu.Lock()
+	path := filepath.Join(sp.dir, sp.files[0].name)
+	sp.mu.Unlock()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return path, nil, err
+	}
+	return path, strings.Split(strings.TrimSuffix(string(data), "\n"), "\n"), nil
+}
+
+// remove deletes a replayed batch, counting its lines as dropped when it was
+// not sent.
+func (sp *spool) remove(path string, dropped bool) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	name := filepath.Base(path)
+	for i, f := range sp.files {
+		if f.name != name {
+			continue
+		}
+		os.Remove(path)
+		sp.files = append(sp.files[:i], sp.files[i+1:]...)
+		sp.size -= f.size
+		if dropped {
+			countSinkLines(sp.sink, "dropped_spool_error", f.lines)
+		}
+		break
+	}
+	sp.setSize()
+}
+
+func (sp *spool) setSize() {
+	if metrics.ELKSpoolBytes != nil {
+		metrics.ELKSpoolBytes.WithLabelValues(sp.sink).Set(float64(sp.size))
+	}
+}
+
+func countSinkLines(sinkName, result string, n int) {
+	if metrics.ELKSinkLines != nil && n > 0 {
+		metrics.ELKSinkLines.WithLabelValues(sinkName, result).Add(float64(n))
+	}
+}
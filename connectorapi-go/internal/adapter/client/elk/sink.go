@@ -0,0 +1,256 @@
+package elk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"connectorapi-go/pkg/config"
+)
+
+// lineTimeLayout is the timestamp that starts every ELK log line, as in
+// "2006-01-02 15:04:05.000 INFO :{...}".
+const lineTimeLayout = "2006-01-02 15:04:05.000"
+
+// entry is one ELK log line as shipped to a remote sink: the line as written
+// to the files, its time and its JSON document.
+type entry struct {
+	time time.Time
+	line string
+	doc  []byte
+}
+
+// parseEntry splits the timestamp and level prefix off line and adds its time
+// to the JSON as @timestamp, so the store need not parse the prefix. A line
+// without the prefix takes the time now; one that is not a JSON object is
+// shipped as {"message": line}.
+func parseEntry(line string, now time.Time) entry {
+	e := entry{time: now, line: line}
+	body := line
+	if len(line) > len(lineTimeLayout) {
+		if t, err := time.ParseInLocation(lineTimeLayout, line[:len(lineTimeLayout)], time.Local); err == nil {
+			if i := strings.Index(line[len(lineTimeLayout):], " :"); i >= 0 {
+				e.time = t
+				body = line[len(lineTimeLayout)+i+2:]
+			}
+		}
+	}
+	stamp, _ := json.Marshal(e.time.Format("2006-01-02T15:04:05.000Z07:00"))
+	body = strings.TrimSpace(body)
+	if strings.HasPrefix(body, "{") && json.Valid([]byte(body)) {
+		rest := strings.TrimSpace(body[1:])
+		if rest != "}" {
+			rest = "," + rest
+		}
+		e.doc = []byte(`{"@timestamp":` + string(stamp) + rest)
+		return e
+	}
+	message, _ := json.Marshal(body)
+	e.doc = []byte(`{"@timestamp":` + string(stamp) + `,"message":` + string(message) + `}`)
+	return e
+}
+
+// sink ships batches of entries to a log store. Send is called from one
+// goroutine at a time; a failed batch may be sent again, so a store can see
+// a line twice but never miss one that was reported as sent.
+type sink interface {
+	Send(ctx context.Context, entries []entry) error
+	Close() error
+}
+
+// partialError reports a batch of which the sink took only some entries:
+// retry holds those worth sending again, rejected counts those refused for
+// good, such as documents that do not fit the index mapping.
+type partialError struct {
+	retry    []entry
+	rejected int
+	err      error
+}
+
+func (e *partialError) Error() string { return e.err.Error() }
+
+// newSink returns the remote sink of cfg, with unset fields defaulted.
+func newSink(cfg config.SinkConfig) (sink, error) {
+	switch cfg.Type {
+	case config.SinkLogstash:
+		network := cfg.Network
+		if network == "" {
+			network = "tcp"
+		}
+		return &logstashSink{network: network, address: cfg.Address, timeout: cfg.Timeout}, nil
+	case config.SinkElasticsearch:
+		index := cfg.Index
+		if index == "" {
+			index = "connector-elk"
+		}
+		return &elasticsearchSink{
+			url:      strings.TrimRight(cfg.URL, "/"),
+			index:    index,
+			username: cfg.Username,
+			password: cfg.Password,
+			client:   &http.Client{Timeout: cfg.Timeout},
+		}, nil
+	}
+	return nil, fmt.Errorf("elk sink %s: type %q is not a remote sink", cfg.SinkName(), cfg.Type)
+}
+
+// logstashSink writes one JSON document per line to a Logstash json_lines
+// input. Over TCP the connection is kept open and redialled after an error;
+// over UDP every line is a datagram of its own.
+type logstashSink struct {
+	network string
+	address string
+	timeout time.Duration
+	conn    net.Conn
+}
+
+func (s *logstashSink) Send(ctx context.Context, entries []entry) error {
+	if s.conn == nil {
+		d := net.Dialer{Timeout: s.timeout}
+		conn, err := d.DialContext(ctx, s.network, s.address)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+	s.conn.SetWriteDeadline(time.Now().Add(s.timeout))
+	err := s.write(entries)
+	if err != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+	return err
+}
+
+func (s *logstashSink) write(entries []entry) error {
+	if s.network == "udp" {
+		for _, e := range entries {
+			if _, err := s.conn.Write(append(e.doc, '\n')); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var buf bytes.Buffer
+	for _, e := range entries {
+		buf.Write(e.doc)
+		buf.WriteByte('\n')
+	}
+	_, err := s.conn.Write(buf.Bytes())
+	return err
+}
+
+func (s *logstashSink) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// elasticsearchSink indexes entries with the _bulk API into one index per
+// day of their time, <index>-yyyy.MM.dd.
+type elasticsearchSink struct {
+	url      string
+	index    string
+	username string
+	password string
+	client   *http.Client
+}
+
+type bulkAction struct {
+	Index struct {
+		Index string `json:"_index"`
+	} `json:"index"`
+}
+
+type bulkResponse struct {
+	Errors bool                  `json:"errors"`
+	Items  []map[string]bulkItem `json:"items"`
+}
+
+type bulkItem struct {
+	Status int             `json:"status"`
+	Error  json.RawMessage `json:"error,omitempty"`
+}
+
+func (s *elasticsearchSink) indexOf(e entry) string {
+	return s.index + "-" + e.time.Format("2006.01.02")
+}
+
+func (s *elasticsearchSink) Send(ctx context.Context, entries []entry) error {
+	var body bytes.Buffer
+	for _, e := range entries {
+		var action bulkAction
+		action.Index.Index = s.indexOf(e)
+		line, _ := json.Marshal(action)
+		body.Write(line)
+		body.WriteByte('\n')
+		body.Write(e.doc)
+		body.WriteByte('\n')
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url+"/_bulk", &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if s.username != "" {
+		req.SetBasicAuth(s.username, s.password)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("elasticsearch bulk: %s: %s", resp.Status, bytes.TrimSpace(text))
+	}
+	var result bulkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("elasticsearch bulk: bad response: %w", err)
+	}
+	if !result.Errors {
+		return nil
+	}
+
+	// Items are in the order of the request. Overload (429) and server errors
+	// are worth retrying; any other status will fail again.
+	pe := &partialError{}
+	var reason json.RawMessage
+	for i, item := range result.Items {
+		if i >= len(entries) {
+			break
+		}
+		for _, r := range item {
+			switch {
+			case r.Status < 300:
+			case r.Status == http.StatusTooManyRequests || r.Status >= 500:
+				pe.retry = append(pe.retry, entries[i])
+			default:
+				pe.rejected++
+			}
+			if r.Status >= 300 && reason == nil {
+				reason = r.Error
+			}
+		}
+	}
+	if len(pe.retry) == 0 && pe.rejected == 0 {
+		return nil
+	}
+	pe.err = fmt.Errorf("elasticsearch bulk: %d of %d lines failed, %d for good: %s",
+		len(pe.retry)+pe.rejected, len(entries), pe.rejected, reason)
+	return pe
+}
+
+func (s *elasticsearchSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,310 @@
+package elk
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"connectorapi-go/pkg/config"
+
+	"go.uber.org/zap"
+)
+
+// bulkServer is a stand-in for the Elasticsearch _bulk API. It answers 503
+// while down is set, and otherwise with the statuses of reply, in turn, for
+// the items of a request; items without one are created.
+type bulkServer struct {
+	*httptest.Server
+	down atomic.Bool
+
+	mu       sync.Mutex
+	requests [][]string // the lines of each accepted request
+	reply    [][]int
+	auth     string
+}
+
+func newBulkServer(t *testing.T) *bulkServer {
+	b := &bulkServer{}
+	b.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_bulk" || r.Header.Get("Content-Type") != "application/x-ndjson" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if b.down.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		lines := strings.Split(strings.TrimSuffix(string(body), "\n"), "\n")
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.requests = append(b.requests, lines)
+		b.auth = r.Header.Get("Authorization")
+		var statuses []int
+		if len(b.reply) > 0 {
+			statuses, b.reply = b.reply[0], b.reply[1:]
+		}
+		resp := bulkResponse{}
+		for i := 0; i < len(lines)/2; i++ {
+			status := http.StatusCreated
+			if i < len(statuses) {
+				status = statuses[i]
+			}
+			resp.Errors = resp.Errors || status >= 300
+			item := bulkItem{Status: status}
+			if status >= 300 {
+				item.Error = json.RawMessage(`{"type":"test"}`)
+			}
+			resp.Items = append(resp.Items, map[string]bulkItem{"index": item})
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(b.Close)
+	return b
+}
+
+// docs returns the documents received so far, without the action lines.
+func (b *bulkServer) docs() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var docs []string
+	for _, lines := range b.requests {
+		for i := 1; i < len(lines); i += 2 {
+			docs = append(docs, lines[i])
+		}
+	}
+	return docs
+}
+
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func logLine(t time.Time, json string) string {
+	return t.Format(lineTimeLayout) + " INFO :" + json
+}
+
+func TestParseEntry(t *testing.T) {
+	at := time.Date(2026, 10, 18, 9, 30, 0, 123e6, time.Local)
+	e := parseEntry(logLine(at, `{"ServiceName":"Echo"}`), time.Now())
+	if !e.time.Equal(at) {
+		t.Errorf("time = %v, want %v", e.time, at)
+	}
+	var doc map[string]string
+	if err := json.Unmarshal(e.doc, &doc); err != nil {
+		t.Fatalf("doc %s: %v", e.doc, err)
+	}
+	if doc["ServiceName"] != "Echo" || doc["@timestamp"] != at.Format("2006-01-02T15:04:05.000Z07:00") {
+		t.Errorf("doc = %s", e.doc)
+	}
+
+	now := time.Now()
+	e = parseEntry("not json", now)
+	if !e.time.Equal(now) || !strings.Contains(string(e.doc), `"message":"not json"`) {
+		t.Errorf("plain line: %v %s", e.time, e.doc)
+	}
+}
+
+func TestElasticsearchBulk(t *testing.T) {
+	es := newBulkServer(t)
+	s, err := newSink(config.SinkConfig{Type: config.SinkElasticsearch, URL: es.URL + "/", Index: "connector", Username: "elk", Password: "secret", Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	day := time.Date(2026, 10, 18, 23, 59, 0, 0, time.Local)
+	entries := []entry{
+		parseEntry(logLine(day, `{"n":1}`), time.Now()),
+		parseEntry(logLine(day.Add(2*time.Minute), `{"n":2}`), time.Now()),
+	}
+	if err := s.Send(context.Background(), entries); err != nil {
+		t.Fatal(err)
+	}
+	lines := es.requests[0]
+	if len(lines) != 4 {
+		t.Fatalf("bulk body has %d lines: %q", len(lines), lines)
+	}
+	if lines[0] != `{"index":{"_index":"connector-2026.10.18"}}` || lines[2] != `{"index":{"_index":"connector-2026.10.19"}}` {
+		t.Errorf("actions = %s, %s; want one index per day", lines[0], lines[2])
+	}
+	if !strings.Contains(lines[3], `"n":2`) {
+		t.Errorf("document = %s", lines[3])
+	}
+	if es.auth == "" {
+		t.Error("no basic auth sent")
+	}
+}
+
+func TestShipperRetriesFailedBulkItems(t *testing.T) {
+	es := newBulkServer(t)
+	// 429 is retried, 400 is rejected for good, the third line is taken.
+	es.reply = [][]int{{http.StatusTooManyRequests, http.StatusBadRequest}}
+	s, err := newShipper(t.TempDir(), config.SinkConfig{Type: config.SinkElasticsearch, URL: es.URL, RetryBackoff: time.Millisecond}, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.enqueue([]string{`{"n":1}`, `{"n":2}`, `{"n":3}`})
+	eventually(t, "the retry", func() bool { return len(es.docs()) == 4 })
+	s.close()
+
+	if len(es.requests) != 2 || len(es.requests[1]) != 2 || !strings.Contains(es.requests[1][1], `"n":1`) {
+		t.Errorf("requests = %q; want the 429 line sent again alone", es.requests)
+	}
+	if !s.spool.empty() {
+		t.Error("lines spooled after a successful retry")
+	}
+}
+
+func TestShipperSpoolsWhileDown(t *testing.T) {
+	es := newBulkServer(t)
+	es.down.Store(true)
+	dir := t.TempDir()
+	cfg := config.SinkConfig{Type: config.SinkElasticsearch, URL: es.URL, MaxRetries: 1, RetryBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+	s, err := newShipper(dir, cfg, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.enqueue([]string{`{"n":1}`})
+	eventually(t, "the first batch to be spooled", func() bool { return !s.spool.empty() })
+	s.enqueue([]string{`{"n":2}`})
+	s.close()
+
+	spoolDir := filepath.Join(dir, "spool", "elasticsearch")
+	entries, err := os.ReadDir(spoolDir)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("spool holds %d batches (%v), want 2", len(entries), err)
+	}
+
+	// The next run replays the spool, oldest first, once the sink is back.
+	es.down.Store(false)
+	s, err = newShipper(dir, cfg, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.close()
+	eventually(t, "the spool to be replayed", func() bool { return s.spool.empty() })
+	if docs := es.docs(); len(docs) != 2 || !strings.Contains(docs[0], `"n":1`) || !strings.Contains(docs[1], `"n":2`) {
+		t.Errorf("replayed docs = %q", docs)
+	}
+	if entries, _ := os.ReadDir(spoolDir); len(entries) != 0 {
+		t.Errorf("spool not emptied: %v", entries)
+	}
+}
+
+func TestSpoolLimit(t *testing.T) {
+	sp, err := openSpool(t.TempDir(), "test", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := sp.put([]string{strings.Repeat("x", 40)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 3 x 41 bytes is over the limit: the oldest batch goes.
+	if len(sp.files) != 2 || sp.size != 82 {
+		t.Errorf("spool holds %d files, %d bytes", len(sp.files), sp.size)
+	}
+}
+
+func TestLogstashSink(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	received := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewScanner(conn)
+		for r.Scan() {
+			received <- r.Text()
+		}
+	}()
+
+	s, err := newSink(config.SinkConfig{Type: config.SinkLogstash, Address: ln.Addr().String(), Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if err := s.Send(context.Background(), []entry{parseEntry(`{"n":1}`, time.Now()), parseEntry(`{"n":2}`, time.Now())}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"n":1`, `"n":2`} {
+		select {
+		case line := <-received:
+			if !strings.HasPrefix(line, `{"@timestamp":`) || !strings.Contains(line, want) {
+				t.Errorf("line = %s, want %s", line, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("no line received")
+		}
+	}
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	udp, _ := newSink(config.SinkConfig{Type: config.SinkLogstash, Network: "udp", Address: pc.LocalAddr().String(), Timeout: time.Second})
+	defer udp.Close()
+	if err := udp.Send(context.Background(), []entry{parseEntry(`{"n":3}`, time.Now())}); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 1024)
+	pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil || !strings.Contains(string(buf[:n]), `"n":3`) || buf[n-1] != '\n' {
+		t.Errorf("datagram %q, %v", buf[:n], err)
+	}
+}
+
+func TestWriterRemoteSinkKeepsFiles(t *testing.T) {
+	for _, disable := range []bool{false, true} {
+		es := newBulkServer(t)
+		dir := t.TempDir()
+		w := NewWriter(dir, config.ELKConfig{
+			FlushInterval: time.Hour,
+			Sinks:         []config.SinkConfig{{Type: config.SinkElasticsearch, URL: es.URL}},
+			DisableFiles:  disable,
+		}, zap.NewNop().Sugar())
+		w.Write([]string{logLine(time.Now(), `{"ServiceName":"Echo"}`)})
+		w.Close()
+
+		if docs := es.docs(); len(docs) != 1 || !strings.Contains(docs[0], `"ServiceName":"Echo"`) {
+			t.Errorf("disableFiles=%v: shipped docs = %q", disable, docs)
+		}
+		files := 0
+		for _, name := range listDir(t, dir) {
+			if logFileName.MatchString(name) {
+				files++
+			}
+		}
+		if want := map[bool]int{false: 1, true: 0}[disable]; files != want {
+			t.Errorf("disableFiles=%v: %d log files written, want %d", disable, files, want)
+		}
+	}
+}
@@ -0,0 +1,442 @@
+package elk
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"connectorapi-go/pkg/config"
+	"connectorapi-go/pkg/metrics"
+
+	"go.uber.org/zap"
+)
+
+// Overflow policies of config.ELKConfig.OnFull.
+const (
+	OnFullDrop  = "drop"
+	OnFullBlock = "block"
+)
+
+var (
+	ErrQueueFull    = errors.New("elk log queue full; lines dropped")
+	ErrWriterClosed = errors.New("elk log writer closed")
+)
+
+// logFileName matches the day files and their rotated, possibly compressed,
+// copies.
+var logFileName = regexp.MustCompile(`^LOG\d{8}(\.\d+)?\.txt(\.gz)?$`)
+
+var currentWriter atomic.Pointer[Writer]
+
+// SetWriter makes FinalELKLog hand its lines to w instead of writing the file
+// itself.
+func SetWriter(w *Writer) {
+	currentWriter.Store(w)
+}
+
+// writeLines queues lines on the writer set with SetWriter, or writes them
+// directly when there is none.
+func writeLines(lines []string, timestamp time.Time, elkPath string) error {
+	if w := currentWriter.Load(); w != nil {
+		return w.Write(lines)
+	}
+	return WriteLogToFile(lines, timestamp, elkPath)
+}
+
+// Writer appends ELK log lines to LOG<yyyymmdd>.txt in one directory from a
+// single goroutine. Lines are queued by Write and flushed in batches; the
+// day's file is rotated to LOG<yyyymmdd>.<n>.txt when it outgrows MaxSizeMB,
+// and rotated files are compressed and pruned in the background per the
+// configuration. Each batch is also handed to the remote sinks of the
+// configuration, which ship it from goroutines of their own.
+type Writer struct {
+	dir      string
+	cfg      config.ELKConfig
+	logger   *zap.SugaredLogger
+	files    bool // write LOG<yyyymmdd>.txt; off only with disableFiles
+	shippers []*shipper
+
+	queue     chan []string
+	closing   chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+	now       func() time.Time
+	retryWait time.Duration
+
+	file    *os.File
+	day     string
+	size    int64
+	failing bool // the last write failed; logged once per failure streak
+
+	housekeeping sync.WaitGroup // background housekeeping runs
+	housekeepMu  sync.Mutex     // one run at a time
+}
+
+// NewWriter starts a writer for dir. Unset fields of cfg default to a queue of
+// 10000 requests, batches of 256 lines flushed at least every second, and the
+// drop policy. A sink that cannot be started is logged and left out.
+func NewWriter(dir string, cfg config.ELKConfig, logger *zap.SugaredLogger) *Writer {
+	w := newWriter(dir, cfg, logger)
+	go w.run()
+	return w
+}
+
+func newWriter(dir string, cfg config.ELKConfig, logger *zap.SugaredLogger) *Writer {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 10000
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 256
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+	if cfg.OnFull != OnFullBlock {
+		cfg.OnFull = OnFullDrop
+	}
+	w := &Writer{
+		dir:       dir,
+		cfg:       cfg,
+		logger:    logger,
+		files:     !cfg.DisableFiles,
+		queue:     make(chan []string, cfg.QueueSize),
+		closing:   make(chan struct{}),
+		done:      make(chan struct{}),
+		now:       time.Now,
+		retryWait: time.Second,
+	}
+	for _, sc := range cfg.Sinks {
+		if sc.Type == config.SinkFile {
+			continue
+		}
+		s, err := newShipper(dir, sc, logger)
+		if err != nil {
+			logger.Errorw("ELK sink not started", "sink", sc.SinkName(), "error", err)
+			continue
+		}
+		w.shippers = append(w.shippers, s)
+	}
+	return w
+}
+
+// Write queues the lines of one request. With the drop policy it never
+// blocks and returns ErrQueueFull when the lines were dropped.
+func (w *Writer) Write(lines []string) error {
+	select {
+	case <-w.closing:
+		return ErrWriterClosed
+	default:
+	}
+	if w.cfg.OnFull == OnFullBlock {
+		select {
+		case w.queue <- lines:
+			w.setQueueLength()
+			return nil
+		case <-w.closing:
+			return ErrWriterClosed
+		}
+	}
+	select {
+	case w.queue <- lines:
+		w.setQueueLength()
+		return nil
+	default:
+		countLines("dropped_queue_full", len(lines))
+		return ErrQueueFull
+	}
+}
+
+// Close flushes the queued lines and closes the current file.
+func (w *Writer) Close() error {
+	w.closeOnce.Do(func() { close(w.closing) })
+	<-w.done
+	return nil
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]string, 0, w.cfg.BatchSize)
+	add := func(lines []string) {
+		for _, line := range lines {
+			if line == "" {
+				continue
+			}
+			batch = append(batch, line)
+			if len(batch) >= w.cfg.BatchSize {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		}
+		w.setQueueLength()
+	}
+	for {
+		select {
+		case lines := <-w.queue:
+			add(lines)
+		case <-ticker.C:
+			if len(batch) > 0 {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		case <-w.closing:
+			for len(w.queue) > 0 {
+				add(<-w.queue)
+			}
+			if len(batch) > 0 {
+				w.flush(batch)
+			}
+			if w.file != nil {
+				w.file.Close()
+			}
+			w.housekeeping.Wait()
+			for _, s := range w.shippers {
+				s.close()
+			}
+			return
+		}
+	}
+}
+
+// flush hands a batch to the sinks and writes it to the files. A failed
+// write is dropped, or with the block policy retried until it succeeds or
+// the writer is closed.
+func (w *Writer) flush(batch []string) {
+	for _, s := range w.shippers {
+		s.enqueue(batch)
+	}
+	if !w.files {
+		return
+	}
+	for {
+		err := w.writeBatch(batch)
+		if err == nil {
+			if w.failing {
+				w.logger.Infow("ELK log writes recovered", "dir", w.dir)
+				w.failing = false
+			}
+			countLines("written", len(batch))
+			return
+		}
+		if !w.failing {
+			w.logger.Errorw("ELK log write failed", "dir", w.dir, "policy", w.cfg.OnFull, "error", err)
+			w.failing = true
+		}
+		if w.cfg.OnFull != OnFullBlock {
+			countLines("dropped_write_error", len(batch))
+			return
+		}
+		select {
+		case <-time.After(w.retryWait):
+		case <-w.closing:
+			if err := w.writeBatch(batch); err != nil {
+				countLines("dropped_write_error", len(batch))
+			} else {
+				countLines("written", len(batch))
+			}
+			return
+		}
+	}
+}
+
+func (w *Writer) writeBatch(batch []string) error {
+	day := w.now().Format("20060102")
+	if w.file == nil || day != w.day {
+		if err := w.open(day); err != nil {
+			return err
+		}
+	}
+	if limit := int64(w.cfg.MaxSizeMB) << 20; limit > 0 && w.size >= limit {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+	var buf strings.Builder
+	for _, line := range batch {
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	n, err := io.WriteString(w.file, buf.String())
+	w.size += int64(n)
+	if err != nil {
+		// Reopen on the next batch in case the file was removed or replaced.
+		w.file.Close()
+		w.file = nil
+	}
+	return err
+}
+
+// open makes LOG<day>.txt the current file; the previous one, if any, is now
+// rotated.
+func (w *Writer) open(day string) error {
+	rotated := w.file != nil && day != w.day
+	if w.file != nil {
+		w.file.Close()
+		w.file = nil
+	}
+	if err := os.MkdirAll(w.dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(w.dir, "LOG"+day+".txt"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	first := w.day == ""
+	w.file, w.day, w.size = f, day, info.Size()
+	if first || rotated {
+		w.housekeep()
+	}
+	return nil
+}
+
+// rotate renames the current file to the next free LOG<day>.<n>.txt.
+func (w *Writer) rotate() error {
+	w.file.Close()
+	w.file = nil
+	current := filepath.Join(w.dir, "LOG"+w.day+".txt")
+	for n := 1; ; n++ {
+		name := filepath.Join(w.dir, fmt.Sprintf("LOG%s.%d.txt", w.day, n))
+		if exists(name) || exists(name+".gz") {
+			continue
+		}
+		if err := os.Rename(current, name); err != nil {
+			return err
+		}
+		break
+	}
+	day := w.day
+	w.day = "" // so open treats it as a fresh start and housekeeps
+	return w.open(day)
+}
+
+// housekeep starts a background run of tidy, so compressing a large file
+// never holds up the writer. Runs take turns.
+func (w *Writer) housekeep() {
+	day, now := w.day, w.now()
+	w.housekeeping.Add(1)
+	go func() {
+		defer w.housekeeping.Done()
+		w.housekeepMu.Lock()
+		defer w.housekeepMu.Unlock()
+		w.tidy(day, now)
+	}()
+}
+
+// tidy compresses rotated files and applies MaxAge and MaxFiles. The files of
+// day and later are left alone: the writer may be appending to them. Errors
+// are logged; they never stop the writer.
+func (w *Writer) tidy(day string, now time.Time) {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		w.logger.Warnw("ELK log housekeeping failed", "dir", w.dir, "error", err)
+		return
+	}
+	type rotatedFile struct {
+		path    string
+		modTime time.Time
+	}
+	var files []rotatedFile
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !logFileName.MatchString(name) {
+			continue
+		}
+		if current := strings.TrimSuffix(strings.TrimPrefix(name, "LOG"), ".txt"); len(current) == len(day) && current >= day {
+			continue
+		}
+		path := filepath.Join(w.dir, name)
+		if w.cfg.Compress && !strings.HasSuffix(name, ".gz") {
+			if err := gzipFile(path); err != nil {
+				w.logger.Warnw("ELK log compression failed", "file", path, "error", err)
+			} else {
+				path += ".gz"
+			}
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		files = append(files, rotatedFile{path, info.ModTime()})
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.After(files[j].modTime) })
+	for i, f := range files {
+		expired := w.cfg.MaxAge > 0 && now.Sub(f.modTime) > w.cfg.MaxAge
+		surplus := w.cfg.MaxFiles > 0 && i >= w.cfg.MaxFiles
+		if expired || surplus {
+			if err := os.Remove(f.path); err != nil {
+				w.logger.Warnw("ELK log retention failed", "file", f.path, "error", err)
+			}
+		}
+	}
+}
+
+// gzipFile replaces path with path.gz, keeping its modification time.
+func gzipFile(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	tmp := path + ".gz.tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	_, err = io.Copy(zw, in)
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chtimes(tmp, info.ModTime(), info.ModTime())
+	}
+	if err == nil {
+		err = os.Rename(tmp, path+".gz")
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Remove(path)
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func (w *Writer) setQueueLength() {
+	if metrics.ELKQueueLength != nil {
+		metrics.ELKQueueLength.Set(float64(len(w.queue)))
+	}
+}
+
+func countLines(result string, n int) {
+	if metrics.ELKLogLinesTotal != nil {
+		metrics.ELKLogLinesTotal.WithLabelValues(result).Add(float64(n))
+	}
+}
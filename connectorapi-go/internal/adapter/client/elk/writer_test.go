@@ -0,0 +1,129 @@
+package elk
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"connectorapi-go/pkg/config"
+
+	"go.uber.org/zap"
+)
+
+func listDir(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestWriterFlushesOnClose(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "elk", "log")
+	w := NewWriter(dir, config.ELKConfig{FlushInterval: time.Hour}, zap.NewNop().Sugar())
+	w.now = func() time.Time { return time.Date(2026, 10, 18, 9, 0, 0, 0, time.Local) }
+	for i := 0; i < 3; i++ {
+		if err := w.Write([]string{"main", "", "line"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.Close()
+
+	data, err := os.ReadFile(filepath.Join(dir, "LOG20261018.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(string(data), "main\nline\n"); got != 3 {
+		t.Errorf("file holds %d requests:\n%s", got, data)
+	}
+	if err := w.Write([]string{"late"}); err != ErrWriterClosed {
+		t.Errorf("Write after Close = %v", err)
+	}
+}
+
+func TestWriterRotation(t *testing.T) {
+	dir := t.TempDir()
+	day := time.Date(2026, 10, 18, 9, 0, 0, 0, time.Local)
+	w := newWriter(dir, config.ELKConfig{MaxSizeMB: 1, MaxFiles: 2, Compress: true}, zap.NewNop().Sugar())
+	w.now = func() time.Time { return day }
+
+	big := strings.Repeat("x", 1<<20)
+	for i := 0; i < 4; i++ {
+		w.flush([]string{big})
+	}
+	w.housekeeping.Wait()
+	// Three size rotations, of which MaxFiles keeps the two newest.
+	if got, want := listDir(t, dir), []string{"LOG20261018.2.txt.gz", "LOG20261018.3.txt.gz", "LOG20261018.txt"}; strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("after size rotation: %v, want %v", got, want)
+	}
+
+	f, err := os.Open(filepath.Join(dir, "LOG20261018.3.txt.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := io.ReadAll(zr); len(data) != len(big)+1 {
+		t.Errorf("rotated file holds %d bytes", len(data))
+	}
+
+	// The next day starts a new file and compresses the previous one.
+	day = day.AddDate(0, 0, 1)
+	w.flush([]string{"next day"})
+	w.housekeeping.Wait()
+	names := listDir(t, dir)
+	if !contains(names, "LOG20261019.txt") || !contains(names, "LOG20261018.txt.gz") || len(names) != 3 {
+		t.Errorf("after day change: %v", names)
+	}
+}
+
+func TestWriterDropPolicy(t *testing.T) {
+	w := newWriter(t.TempDir(), config.ELKConfig{QueueSize: 1}, zap.NewNop().Sugar())
+	if err := w.Write([]string{"a"}); err != nil {
+		t.Fatal(err)
+	}
+	// Nothing drains the queue: the second request is dropped, not blocked.
+	if err := w.Write([]string{"b"}); err != ErrQueueFull {
+		t.Errorf("Write on a full queue = %v", err)
+	}
+}
+
+func TestWriterWriteErrors(t *testing.T) {
+	// A file where the directory should be makes every write fail.
+	path := filepath.Join(t.TempDir(), "not-a-dir")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeLines([]string{"x"}, time.Now(), path+"/"); err == nil {
+		t.Fatal("write into a file succeeded")
+	}
+
+	w := newWriter(path, config.ELKConfig{}, zap.NewNop().Sugar())
+	w.flush([]string{"x"}) // dropped and logged, not retried
+	if w.file != nil || !w.failing {
+		t.Error("failed write not recorded")
+	}
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -2,9 +2,14 @@ package client
 
 import (
 	"connectorapi-go/internal/adapter/utils"
+	"connectorapi-go/pkg/config"
+	"context"
+	"errors"
 	"fmt"             
 	// "io"
 	"net"  // For TCP connections
+	"sort"
+	"sync"
 	"time" // For timeouts
 	"bufio"
 )
@@ -12,12 +17,20 @@ import (
 // TCPSocketClient defines the interface for a TCP socket client.
 type TCPSocketClient interface {
 	SendAndReceive(address string, combinedPayloadString string) (string, error)
+	SendAndReceiveContext(ctx context.Context, address string, combinedPayloadString string) (string, error)
 }
 
-// BasicTCPSocketClient implements TCPSocketClient with length-prefixing and TIS-620 encoding.
+// BasicTCPSocketClient implements TCPSocketClient with a per-destination wire codec.
 type BasicTCPSocketClient struct {
 	DialTimeout      time.Duration // Timeout for establishing the connection
 	ReadWriteTimeout time.Duration // Timeout for read/write operations
+	Pool             config.TCPPoolConfig // Per-address pool settings, MaxOpen 0 dials per request
+
+	poolsMu sync.Mutex
+	pools   map[string]*connPool
+
+	codecsMu sync.RWMutex
+	codecs   map[string]utils.Codec // by destination IP, cp874 when absent
 }
 
 // NewBasicTCPSocketClient creates a new instance of BasicTCPSocketClient.
@@ -28,6 +41,114 @@ func NewBasicTCPSocketClient(dialTimeout, readWriteTimeout time.Duration) *Basic
 	}
 }
 
+// NewPooledTCPSocketClient creates a BasicTCPSocketClient that keeps persistent
+// connections per System-I address.
+func NewPooledTCPSocketClient(dialTimeout, readWriteTimeout time.Duration, pool config.TCPPoolConfig) *BasicTCPSocketClient {
+	return &BasicTCPSocketClient{
+		DialTimeout:      dialTimeout,
+		ReadWriteTimeout: readWriteTimeout,
+		Pool:             pool,
+		pools:            make(map[string]*connPool),
+	}
+}
+
+// pool returns the connection pool for an address, creating it on first use.
+// It returns nil when pooling is disabled.
+func (c *BasicTCPSocketClient) pool(address string) *connPool {
+	if c.Pool.MaxOpen <= 0 {
+		return nil
+	}
+	c.poolsMu.Lock()
+	defer c.poolsMu.Unlock()
+	if c.pools == nil {
+		c.pools = make(map[string]*connPool)
+	}
+	p, ok := c.pools[address]
+	if !ok {
+		p = newConnPool(address, c.DialTimeout, c.Pool)
+		c.pools[address] = p
+	}
+	return p
+}
+
+// SetDestinationCodecs installs the wire codec of every destination, keyed by
+// ip:port for each of its ports, so destinations that share a host can use
+// different codecs. It fails without changing anything if a destination names
+// an unknown codec or two destinations want different codecs on one port.
+func (c *BasicTCPSocketClient) SetDestinationCodecs(destinations map[string]config.Destination) error {
+	codecs := make(map[string]utils.Codec)
+	owners := make(map[string]string)
+	for name, dest := range destinations {
+		codec, err := utils.CodecByName(dest.Codec)
+		if err != nil {
+			return fmt.Errorf("destination %s: %w", name, err)
+		}
+		for _, ports := range dest.Ports {
+			for _, port := range ports {
+				address := net.JoinHostPort(dest.IP, port)
+				if prev, ok := codecs[address]; ok && prev.Name() != codec.Name() {
+					return fmt.Errorf("destinations %s and %s use different codecs on %s", owners[address], name, address)
+				}
+				codecs[address] = codec
+				owners[address] = name
+			}
+		}
+	}
+	c.codecsMu.Lock()
+	c.codecs = codecs
+	c.codecsMu.Unlock()
+	return nil
+}
+
+// codecFor returns the codec installed for address, or cp874 for an address
+// no destination lists.
+func (c *BasicTCPSocketClient) codecFor(address string) utils.Codec {
+	c.codecsMu.RLock()
+	codec, ok := c.codecs[address]
+	c.codecsMu.RUnlock()
+	if !ok {
+		codec, _ = utils.CodecByName(utils.CodecCP874)
+	}
+	return codec
+}
+
+// PoolStats returns a snapshot of every address pool, sorted by address.
+func (c *BasicTCPSocketClient) PoolStats() []PoolStats {
+	c.poolsMu.Lock()
+	defer c.poolsMu.Unlock()
+	stats := make([]PoolStats, 0, len(c.pools))
+	for _, p := range c.pools {
+		stats = append(stats, p.snapshot())
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Address < stats[j].Address })
+	return stats
+}
+
+// ClosePoolsExcept closes the pools of every address not in keep, such as
+// listeners a routing reload removed. Connections still in use are closed
+// when they are put back.
+func (c *BasicTCPSocketClient) ClosePoolsExcept(keep map[string]bool) {
+	c.poolsMu.Lock()
+	defer c.poolsMu.Unlock()
+	for address, p := range c.pools {
+		if keep[address] {
+			continue
+		}
+		p.close()
+		delete(c.pools, address)
+	}
+}
+
+// Close closes every idle pooled connection.
+func (c *BasicTCPSocketClient) Close() {
+	c.poolsMu.Lock()
+	defer c.poolsMu.Unlock()
+	for address, p := range c.pools {
+		p.close()
+		delete(c.pools, address)
+	}
+}
+
 // func (c *BasicTCPSocketClient) SendAndReceive(address string, combinedPayloadString string) (string, error) {
 // 	fmt.Println("Connecting to the server...")
 // 	conn, err := net.DialTimeout("tcp", address, c.DialTimeout)
@@ -84,42 +205,143 @@ func NewBasicTCPSocketClient(dialTimeout, readWriteTimeout time.Duration) *Basic
 // }
 
 func (c *BasicTCPSocketClient) SendAndReceive(address string, combinedPayloadString string) (string, error) {
-	fmt.Println("Connecting to the server...")
-	conn, err := net.DialTimeout("tcp", address, c.DialTimeout)
-	if err != nil {
-		return "", fmt.Errorf("ER040: " + err.Error())
+	return c.SendAndReceiveContext(context.Background(), address, combinedPayloadString)
+}
+
+// SendAndReceiveContext sends one request and reads one CRLF-terminated response.
+// The exchange is bounded by the context deadline (the route budget) when ctx
+// has one and by ReadWriteTimeout otherwise, and the socket is released as soon
+// as ctx is cancelled.
+// Requests to a port whose circuit breaker is open fail fast with ER040, and
+// every outcome is fed back into that port's breaker and the TCP metrics.
+func (c *BasicTCPSocketClient) SendAndReceiveContext(ctx context.Context, address string, combinedPayloadString string) (string, error) {
+	labels := newExchangeLabels(address, combinedPayloadString)
+	if !utils.BreakerAllow(address) {
+		err := newSendError("ER040", false, "circuit breaker open for "+address)
+		labels.observeOutcome("", err)
+		return "", err
+	}
+	done := utils.TrackOutstanding(address)
+	inFlight := labels.trackInFlight()
+	responseStr, err := c.exchange(ctx, address, combinedPayloadString, labels)
+	inFlight()
+	done()
+	if localError(err) {
+		utils.BreakerRelease(address)
+	} else {
+		utils.BreakerRecord(address, ErrorCode(err))
+	}
+	labels.observeOutcome(responseStr, err)
+	return responseStr, err
+}
+
+func (c *BasicTCPSocketClient) exchange(ctx context.Context, address string, combinedPayloadString string, labels exchangeLabels) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", contextError(err, false)
 	}
-	defer conn.Close()
 
-	fmt.Println("Encoding request to CP874...")
-	encodedRequest, err := utils.Utf8ToCP874(combinedPayloadString)
+	codec := c.codecFor(address)
+	fmt.Println("Encoding request to " + codec.Name() + "...")
+	encodedRequest, err := codec.Encode(combinedPayloadString)
 	if err != nil {
-		return "", fmt.Errorf("ER099: Failed to encode request to CP874: " + err.Error())
+		return "", newSendError("ER099", false, "Failed to encode request to "+codec.Name()+": "+err.Error())
 	}
 
-	_, err = conn.Write(encodedRequest)
+	fmt.Println("Connecting to the server...")
+	p := c.pool(address)
+	var conn *pooledConn
+	if p != nil {
+		conn, err = p.get(ctx)
+	} else {
+		conn, err = c.dial(ctx, address)
+	}
 	if err != nil {
-		return "", fmt.Errorf("ER060: " + err.Error())
+		if ctx.Err() != nil {
+			return "", contextError(ctx.Err(), false)
+		}
+		sendErr := newSendError("ER040", false, err.Error())
+		sendErr.Local = errors.Is(err, errPoolExhausted)
+		return "", sendErr
+	}
+
+	broken := true
+	release := func() {
+		if p != nil {
+			p.put(conn, broken)
+			return
+		}
+		conn.Close()
 	}
+	defer func() { release() }()
 
-	fmt.Println("Request sent (UTF-8):", combinedPayloadString)
+	// Unblock any pending read or write the moment the caller goes away.
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Unix(1, 0))
+	})
+	defer stop()
 
-	conn.SetReadDeadline(time.Now().Add(c.ReadWriteTimeout))
+	// A route budget replaces ReadWriteTimeout, so it can be longer as well
+	// as shorter.
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(c.ReadWriteTimeout)
+	}
+	conn.SetDeadline(deadline)
 
-	reader := bufio.NewReader(conn)
-	responseLine, err := reader.ReadBytes('\n')
+	sentAt := time.Now()
+	n, err := conn.Write(encodedRequest)
+	labels.countBytes("sent", n)
 	if err != nil {
-		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-			return "", fmt.Errorf("ER050: read timeout")
+		if ctx.Err() != nil {
+			return "", contextError(ctx.Err(), true)
 		}
-		return "", fmt.Errorf("ER060: failed to read: %v", err)
+		return "", newSendError("ER060", true, err.Error())
 	}
 
-	decoded, err := utils.DecodeCP874(responseLine)
+	responseLine, err := conn.reader.ReadBytes(codec.Delimiter())
+	labels.countBytes("received", len(responseLine))
 	if err != nil {
-		return "", fmt.Errorf("ER099: Failed to decode response from CP874: " + err.Error())
+		if ctx.Err() != nil {
+			return "", contextError(ctx.Err(), true)
+		}
+		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+			return "", newSendError("ER050", true, "read timeout")
+		}
+		return "", newSendError("ER060", true, fmt.Sprintf("failed to read: %v", err))
+	}
+
+	labels.observeRoundTrip(time.Since(sentAt))
+
+	if stop() {
+		conn.SetDeadline(time.Time{})
+		broken = false
 	}
 
-	fmt.Println("Final result (UTF-8):", decoded)
+	decoded, err := codec.Decode(responseLine)
+	if err != nil {
+		return "", newSendError("ER099", true, "Failed to decode response from "+codec.Name()+": "+err.Error())
+	}
 	return decoded, nil
-}
\ No newline at end of file
+}
+
+// dial opens a one-shot connection when pooling is disabled.
+func (c *BasicTCPSocketClient) dial(ctx context.Context, address string) (*pooledConn, error) {
+	dialer := net.Dialer{Timeout: c.DialTimeout}
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	observeDial(address, time.Since(start))
+	now := time.Now()
+	return &pooledConn{Conn: conn, reader: bufio.NewReader(conn), createdAt: now, lastUsed: now}, nil
+}
+
+// contextError maps a finished context to the client error codes: an expired
+// route deadline is a timeout (ER050), a caller that went away is ER070.
+func contextError(err error, written bool) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return newSendError("ER050", written, "deadline exceeded")
+	}
+	return newSendError("ER070", written, fmt.Sprintf("request cancelled: %v", err))
+}
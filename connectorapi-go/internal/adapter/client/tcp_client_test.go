@@ -0,0 +1,174 @@
+package client
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"connectorapi-go/internal/adapter/utils"
+	"connectorapi-go/pkg/config"
+)
+
+func TestSendAndReceiveContextHonoursDeadline(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			// Never answer, like a hung System-I listener.
+			defer conn.Close()
+		}
+	}()
+
+	for _, pool := range []config.TCPPoolConfig{{}, {MaxOpen: 1}} {
+		c := NewPooledTCPSocketClient(time.Second, 10*time.Second, pool)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		start := time.Now()
+		_, err := c.SendAndReceiveContext(ctx, ln.Addr().String(), "PING\r\n")
+		cancel()
+		c.Close()
+		if err == nil || !strings.HasPrefix(err.Error(), "ER050") {
+			t.Fatalf("pool %+v: expected ER050, got %v", pool, err)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("pool %+v: call took %s, deadline was not applied", pool, elapsed)
+		}
+	}
+}
+
+func TestSendAndReceiveContextRouteBudgetOutlastsReadWriteTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, 64)
+		conn.Read(buf)
+		// Slower than ReadWriteTimeout, within the route budget.
+		time.Sleep(300 * time.Millisecond)
+		conn.Write([]byte("PONG\r\n"))
+	}()
+
+	c := NewBasicTCPSocketClient(time.Second, 100*time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if _, err := c.SendAndReceiveContext(ctx, ln.Addr().String(), "PING\r\n"); err != nil {
+		t.Fatalf("route budget longer than ReadWriteTimeout not applied: %v", err)
+	}
+}
+
+func TestPoolExhaustionIsNotAListenerFailure(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	c := NewPooledTCPSocketClient(50*time.Millisecond, time.Second, config.TCPPoolConfig{MaxOpen: 1})
+	defer c.Close()
+	busy := make(chan struct{})
+	go func() {
+		defer close(busy)
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+		c.SendAndReceiveContext(ctx, ln.Addr().String(), "PING\r\n")
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	_, err = c.SendAndReceiveContext(context.Background(), ln.Addr().String(), "PING\r\n")
+	if ErrorCode(err) != "ER040" || !localError(err) {
+		t.Fatalf("expected a local ER040, got %v", err)
+	}
+	for _, b := range utils.BreakerStatuses() {
+		if b.Address == ln.Addr().String() && b.ConsecutiveFailures > 0 {
+			t.Errorf("pool exhaustion counted against the listener: %+v", b)
+		}
+	}
+	<-busy
+}
+
+func TestSendAndReceiveContextCancelled(t *testing.T) {
+	c := NewBasicTCPSocketClient(time.Second, time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := c.SendAndReceiveContext(ctx, "127.0.0.1:1", "PING\r\n")
+	if err == nil || !strings.HasPrefix(err.Error(), "ER070") {
+		t.Fatalf("expected ER070, got %v", err)
+	}
+}
+
+func TestSetDestinationCodecsConflict(t *testing.T) {
+	c := NewBasicTCPSocketClient(time.Second, time.Second)
+	err := c.SetDestinationCodecs(map[string]config.Destination{
+		"a": {IP: "10.0.0.1", Codec: "ibm838", Ports: map[string][]string{"Echo": {"4001"}}},
+		"b": {IP: "10.0.0.1", Codec: "tis620", Ports: map[string][]string{"Ping": {"4001"}}},
+	})
+	if err == nil {
+		t.Fatal("two codecs on one ip:port accepted")
+	}
+}
+
+func TestSendAndReceiveUsesDestinationCodec(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, 64)
+		n, _ := conn.Read(buf)
+		received <- buf[:n]
+		// "ตกลง" followed by the EBCDIC line feed.
+		conn.Write([]byte{0x68, 0x42, 0x9C, 0x48, 0x25})
+	}()
+
+	c := NewBasicTCPSocketClient(time.Second, time.Second)
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	err = c.SetDestinationCodecs(map[string]config.Destination{
+		"systemI": {IP: "127.0.0.1", Codec: "ibm838", Ports: map[string][]string{"Echo": {port}}},
+		// Another listener on the same host keeps its own codec.
+		"legacy": {IP: "127.0.0.1", Codec: "cp874", Ports: map[string][]string{"Echo": {"1"}}},
+	})
+	if err != nil {
+		t.Fatalf("SetDestinationCodecs: %v", err)
+	}
+	resp, err := c.SendAndReceive(ln.Addr().String(), "กA")
+	if err != nil {
+		t.Fatalf("SendAndReceive: %v", err)
+	}
+	if got := <-received; string(got) != "\x42\xC1" {
+		t.Errorf("request bytes % X, want 42 C1", got)
+	}
+	if resp != "ตกลง\n" {
+		t.Errorf("response %q, want %q", resp, "ตกลง\n")
+	}
+}
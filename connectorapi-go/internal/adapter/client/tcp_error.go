@@ -0,0 +1,48 @@
+package client
+
+import "errors"
+
+// SendError is returned by TCPSocketClient implementations. Its text keeps the
+// "ERxxx: message" form the services match on, and Written records whether any
+// part of the request may already have reached System-I. Local marks failures
+// on this side, such as an exhausted connection pool, that say nothing about
+// the listener.
+type SendError struct {
+	Code    string
+	Message string
+	Written bool
+	Local   bool
+}
+
+func newSendError(code string, written bool, message string) *SendError {
+	return &SendError{Code: code, Message: message, Written: written}
+}
+
+func (e *SendError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+// ErrorCode returns the ERxxx code of err, or "" when err is not a SendError.
+func ErrorCode(err error) string {
+	var sendErr *SendError
+	if errors.As(err, &sendErr) {
+		return sendErr.Code
+	}
+	return ""
+}
+
+// localError reports whether err failed on this side of the connection.
+func localError(err error) bool {
+	var sendErr *SendError
+	return errors.As(err, &sendErr) && sendErr.Local
+}
+
+// RequestWritten reports whether the request may have reached the listener
+// before err happened. Unknown errors are treated as written.
+func RequestWritten(err error) bool {
+	var sendErr *SendError
+	if errors.As(err, &sendErr) {
+		return sendErr.Written
+	}
+	return true
+}
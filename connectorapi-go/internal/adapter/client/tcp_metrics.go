@@ -0,0 +1,100 @@
+package client
+
+import (
+	"net"
+	"strings"
+	"time"
+
+	"connectorapi-go/pkg/metrics"
+)
+
+// exchangeLabels identify a System-I exchange in the TCP metrics: the
+// destination IP, the listener port and the Service name of the request
+// header.
+type exchangeLabels struct {
+	destination string
+	port        string
+	service     string
+}
+
+func newExchangeLabels(address, payload string) exchangeLabels {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	return exchangeLabels{destination: host, port: port, service: headerService(payload)}
+}
+
+// headerService returns the Service name of a System-I request header, which
+// follows the 10-character System code.
+func headerService(payload string) string {
+	if len(payload) < 25 {
+		return ""
+	}
+	return strings.TrimSpace(payload[10:25])
+}
+
+// headerResponseCode returns the response code of a System-I response header,
+// OK when it is empty.
+func headerResponseCode(response string) string {
+	code := ""
+	if len(response) >= 73 {
+		code = strings.TrimSpace(response[67:73])
+	}
+	if code == "" {
+		return "OK"
+	}
+	return code
+}
+
+// trackInFlight counts an exchange as in flight on its port until the
+// returned function is called.
+func (l exchangeLabels) trackInFlight() func() {
+	if metrics.TCPInFlight == nil {
+		return func() {}
+	}
+	g := metrics.TCPInFlight.WithLabelValues(l.destination, l.port)
+	g.Inc()
+	return g.Dec
+}
+
+// observeOutcome counts the error class of a failed exchange or the response
+// code of a completed one.
+func (l exchangeLabels) observeOutcome(response string, err error) {
+	if metrics.TCPErrorsTotal == nil {
+		return
+	}
+	if err != nil {
+		code := ErrorCode(err)
+		if code == "" {
+			code = "unknown"
+		}
+		metrics.TCPErrorsTotal.WithLabelValues(l.destination, l.port, l.service, code).Inc()
+		return
+	}
+	metrics.SystemIResponses.WithLabelValues(l.destination, l.port, l.service, headerResponseCode(response)).Inc()
+}
+
+func (l exchangeLabels) observeRoundTrip(d time.Duration) {
+	if metrics.TCPRoundTripSeconds != nil {
+		metrics.TCPRoundTripSeconds.WithLabelValues(l.destination, l.port, l.service).Observe(d.Seconds())
+	}
+}
+
+func (l exchangeLabels) countBytes(direction string, n int) {
+	if metrics.TCPBytesTotal != nil && n > 0 {
+		metrics.TCPBytesTotal.WithLabelValues(l.destination, l.port, l.service, direction).Add(float64(n))
+	}
+}
+
+// observeDial records the time taken by a successful connect to address.
+func observeDial(address string, d time.Duration) {
+	if metrics.TCPDialSeconds == nil {
+		return
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	metrics.TCPDialSeconds.WithLabelValues(host, port).Observe(d.Seconds())
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"connectorapi-go/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// useTestMetrics points the TCP metrics at unregistered collectors for the
+// duration of a test.
+func useTestMetrics(t *testing.T) {
+	t.Helper()
+	dial, rtt, bytes, errs, inFlight, responses := metrics.TCPDialSeconds, metrics.TCPRoundTripSeconds, metrics.TCPBytesTotal, metrics.TCPErrorsTotal, metrics.TCPInFlight, metrics.SystemIResponses
+	t.Cleanup(func() {
+		metrics.TCPDialSeconds, metrics.TCPRoundTripSeconds, metrics.TCPBytesTotal, metrics.TCPErrorsTotal, metrics.TCPInFlight, metrics.SystemIResponses = dial, rtt, bytes, errs, inFlight, responses
+	})
+	metrics.TCPDialSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "dial"}, []string{"destination", "port"})
+	metrics.TCPRoundTripSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "rtt"}, []string{"destination", "port", "service"})
+	metrics.TCPBytesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{Name: "bytes"}, []string{"destination", "port", "service", "direction"})
+	metrics.TCPErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{Name: "errors"}, []string{"destination", "port", "service", "code"})
+	metrics.TCPInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "in_flight"}, []string{"destination", "port"})
+	metrics.SystemIResponses = prometheus.NewCounterVec(prometheus.CounterOpts{Name: "responses"}, []string{"destination", "port", "service", "code"})
+}
+
+func TestSendAndReceiveMetrics(t *testing.T) {
+	useTestMetrics(t)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	response := strings.Repeat(" ", 67) + "SVC902" + strings.Repeat(" ", 50) + "BODY"
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		bufio.NewReader(conn).ReadString('\n')
+		conn.Write([]byte(response + "\r\n"))
+	}()
+
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	payload := "SYSTEMI   MOBILE_SVC     001PING\r\n"
+	c := NewBasicTCPSocketClient(time.Second, time.Second)
+	if _, err := c.SendAndReceiveContext(context.Background(), ln.Addr().String(), payload); err != nil {
+		t.Fatalf("SendAndReceiveContext: %v", err)
+	}
+
+	if got := testutil.ToFloat64(metrics.SystemIResponses.WithLabelValues("127.0.0.1", port, "MOBILE_SVC", "SVC902")); got != 1 {
+		t.Errorf("responses{SVC902} = %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.TCPBytesTotal.WithLabelValues("127.0.0.1", port, "MOBILE_SVC", "sent")); got != float64(len(payload)) {
+		t.Errorf("bytes sent = %v, want %d", got, len(payload))
+	}
+	if got := testutil.ToFloat64(metrics.TCPBytesTotal.WithLabelValues("127.0.0.1", port, "MOBILE_SVC", "received")); got != float64(len(response)+2) {
+		t.Errorf("bytes received = %v, want %d", got, len(response)+2)
+	}
+	if got := testutil.CollectAndCount(metrics.TCPRoundTripSeconds); got != 1 {
+		t.Errorf("round-trip series = %d", got)
+	}
+	if got := testutil.CollectAndCount(metrics.TCPDialSeconds); got != 1 {
+		t.Errorf("dial series = %d", got)
+	}
+	if got := testutil.ToFloat64(metrics.TCPInFlight.WithLabelValues("127.0.0.1", port)); got != 0 {
+		t.Errorf("in flight after the exchange = %v", got)
+	}
+
+	// A refused connection is counted by error class.
+	ln.Close()
+	if _, err := c.SendAndReceiveContext(context.Background(), ln.Addr().String(), payload); ErrorCode(err) != "ER040" {
+		t.Fatalf("expected ER040, got %v", err)
+	}
+	if got := testutil.ToFloat64(metrics.TCPErrorsTotal.WithLabelValues("127.0.0.1", port, "MOBILE_SVC", "ER040")); got != 1 {
+		t.Errorf("errors{ER040} = %v", got)
+	}
+}
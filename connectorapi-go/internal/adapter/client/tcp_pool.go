@@ -0,0 +1,329 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"connectorapi-go/pkg/config"
+	"connectorapi-go/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// errPoolExhausted is returned when every connection for an address is in use
+// and none was released before the dial timeout or the caller's deadline.
+var errPoolExhausted = errors.New("connection pool exhausted")
+
+// pooledConn wraps a System-I connection with the bookkeeping the pool needs.
+type pooledConn struct {
+	net.Conn
+	reader    *bufio.Reader
+	createdAt time.Time
+	lastUsed  time.Time
+}
+
+// PoolStats is a snapshot of one address pool.
+type PoolStats struct {
+	Address   string
+	Open      int
+	Idle      int
+	InUse     int
+	Dials     uint64
+	Reuses    uint64
+	Discards  uint64
+	WaitCount uint64
+	WaitTime  time.Duration
+}
+
+// connPool keeps persistent connections to a single ip:port.
+type connPool struct {
+	address     string
+	dialTimeout time.Duration
+	cfg         config.TCPPoolConfig
+
+	mu    sync.Mutex
+	idle  []*pooledConn
+	open  int
+	slots chan struct{}
+	stats PoolStats
+	// closed is set by close; connections put back after it are discarded.
+	closed bool
+
+	stop chan struct{}
+}
+
+func newConnPool(address string, dialTimeout time.Duration, cfg config.TCPPoolConfig) *connPool {
+	p := &connPool{
+		address:     address,
+		dialTimeout: dialTimeout,
+		cfg:         cfg,
+		slots:       make(chan struct{}, cfg.MaxOpen),
+		stop:        make(chan struct{}),
+	}
+	p.stats.Address = address
+	go p.maintain()
+	return p
+}
+
+// get returns an idle connection that passed the health check or dials a new one.
+func (p *connPool) get(ctx context.Context) (*pooledConn, error) {
+	select {
+	case p.slots <- struct{}{}:
+	default:
+		start := time.Now()
+		p.event("wait")
+		timer := time.NewTimer(p.dialTimeout)
+		defer timer.Stop()
+		select {
+		case p.slots <- struct{}{}:
+		case <-timer.C:
+			p.event("exhausted")
+			return nil, errPoolExhausted
+		case <-ctx.Done():
+			p.event("exhausted")
+			return nil, ctx.Err()
+		}
+		waited := time.Since(start)
+		p.mu.Lock()
+		p.stats.WaitTime += waited
+		p.mu.Unlock()
+		if metrics.TCPPoolWaitSeconds != nil {
+			metrics.TCPPoolWaitSeconds.WithLabelValues(p.address).Observe(waited.Seconds())
+		}
+	}
+
+	for {
+		pc := p.popIdle()
+		if pc == nil {
+			break
+		}
+		if p.expired(pc, time.Now()) || (p.cfg.HealthCheck && !healthy(pc)) {
+			p.discard(pc)
+			continue
+		}
+		p.event("reuse")
+		p.report()
+		return pc, nil
+	}
+
+	pc, err := p.dial(ctx)
+	if err != nil {
+		<-p.slots
+		return nil, err
+	}
+	p.report()
+	return pc, nil
+}
+
+// put hands a connection back. Broken connections are closed instead of reused.
+func (p *connPool) put(pc *pooledConn, broken bool) {
+	defer func() { <-p.slots }()
+	if broken || p.expired(pc, time.Now()) {
+		p.discard(pc)
+		p.report()
+		return
+	}
+	pc.lastUsed = time.Now()
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		p.discard(pc)
+		p.report()
+		return
+	}
+	p.idle = append(p.idle, pc)
+	p.mu.Unlock()
+	p.report()
+}
+
+func (p *connPool) popIdle() *pooledConn {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := len(p.idle)
+	if n == 0 {
+		return nil
+	}
+	pc := p.idle[n-1]
+	p.idle = p.idle[:n-1]
+	return pc
+}
+
+func (p *connPool) dial(ctx context.Context) (*pooledConn, error) {
+	dialer := net.Dialer{Timeout: p.dialTimeout}
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", p.address)
+	if err != nil {
+		return nil, err
+	}
+	observeDial(p.address, time.Since(start))
+	now := time.Now()
+	p.mu.Lock()
+	p.open++
+	p.mu.Unlock()
+	p.event("dial")
+	return &pooledConn{Conn: conn, reader: bufio.NewReader(conn), createdAt: now, lastUsed: now}, nil
+}
+
+func (p *connPool) discard(pc *pooledConn) {
+	pc.Close()
+	p.mu.Lock()
+	p.open--
+	p.mu.Unlock()
+	p.event("discard")
+}
+
+// event counts a pool event both in the local stats and in Prometheus.
+func (p *connPool) event(kind string) {
+	p.mu.Lock()
+	switch kind {
+	case "dial":
+		p.stats.Dials++
+	case "reuse":
+		p.stats.Reuses++
+	case "discard":
+		p.stats.Discards++
+	case "wait":
+		p.stats.WaitCount++
+	}
+	p.mu.Unlock()
+	if metrics.TCPPoolEventsTotal != nil {
+		metrics.TCPPoolEventsTotal.WithLabelValues(p.address, kind).Inc()
+	}
+}
+
+func (p *connPool) expired(pc *pooledConn, now time.Time) bool {
+	if p.cfg.MaxLifetime > 0 && now.Sub(pc.createdAt) > p.cfg.MaxLifetime {
+		return true
+	}
+	if p.cfg.IdleTimeout > 0 && now.Sub(pc.lastUsed) > p.cfg.IdleTimeout {
+		return true
+	}
+	return false
+}
+
+// healthy probes an idle connection with a very short read. A timeout means the
+// peer is still there and has nothing unsolicited to say; EOF or stray bytes
+// mean the connection cannot be trusted for the next request.
+func healthy(pc *pooledConn) bool {
+	if pc.reader.Buffered() > 0 {
+		return false
+	}
+	pc.SetReadDeadline(time.Now().Add(time.Millisecond))
+	defer pc.SetReadDeadline(time.Time{})
+	_, err := pc.reader.Peek(1)
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		return true
+	}
+	return false
+}
+
+// maintain evicts expired idle connections and keeps MinIdle warm connections.
+func (p *connPool) maintain() {
+	interval := p.cfg.IdleTimeout / 2
+	if interval <= 0 || interval > 30*time.Second {
+		interval = 30 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now()
+		p.mu.Lock()
+		kept := p.idle[:0]
+		var evicted []*pooledConn
+		for _, pc := range p.idle {
+			if p.expired(pc, now) {
+				evicted = append(evicted, pc)
+				continue
+			}
+			kept = append(kept, pc)
+		}
+		p.idle = kept
+		missing := p.cfg.MinIdle - len(p.idle)
+		p.mu.Unlock()
+
+		for _, pc := range evicted {
+			p.discard(pc)
+		}
+		for i := 0; i < missing; i++ {
+			if !p.warm() {
+				break
+			}
+		}
+		p.report()
+	}
+}
+
+// warm dials one extra idle connection if a slot is free.
+func (p *connPool) warm() bool {
+	select {
+	case p.slots <- struct{}{}:
+	default:
+		return false
+	}
+	pc, err := p.dial(context.Background())
+	if err != nil {
+		<-p.slots
+		return false
+	}
+	p.put(pc, false)
+	return true
+}
+
+func (p *connPool) close() {
+	close(p.stop)
+	p.mu.Lock()
+	p.closed = true
+	idle := p.idle
+	p.idle = nil
+	p.mu.Unlock()
+	for _, pc := range idle {
+		p.discard(pc)
+	}
+	if metrics.TCPPoolConnections != nil {
+		metrics.TCPPoolConnections.DeletePartialMatch(prometheus.Labels{"address": p.address})
+	}
+}
+
+func (p *connPool) snapshot() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.stats
+	s.Open = p.open
+	s.Idle = len(p.idle)
+	s.InUse = p.open - len(p.idle)
+	return s
+}
+
+// report pushes the current pool gauges to Prometheus, unless the pool is
+// closed and its series are gone.
+func (p *connPool) report() {
+	if metrics.TCPPoolConnections == nil {
+		return
+	}
+	p.mu.Lock()
+	closed := p.closed
+	p.mu.Unlock()
+	if closed {
+		return
+	}
+	s := p.snapshot()
+	metrics.TCPPoolConnections.WithLabelValues(p.address, "open").Set(float64(s.Open))
+	metrics.TCPPoolConnections.WithLabelValues(p.address, "idle").Set(float64(s.Idle))
+	metrics.TCPPoolConnections.WithLabelValues(p.address, "in_use").Set(float64(s.InUse))
+}
+
+func (s PoolStats) String() string {
+	return fmt.Sprintf("%s open=%d idle=%d inUse=%d dials=%d reuses=%d discards=%d waits=%d",
+		s.Address, s.Open, s.Idle, s.InUse, s.Dials, s.Reuses, s.Discards, s.WaitCount)
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"bufio"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"connectorapi-go/pkg/config"
+)
+
+// startEchoServer answers every line with "OK:<line>" and keeps the connection open.
+func startEchoServer(t *testing.T) (string, *int32) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	var accepted int32
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(&accepted, 1)
+			go func(c net.Conn) {
+				defer c.Close()
+				r := bufio.NewReader(c)
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					c.Write([]byte("OK:" + line))
+				}
+			}(conn)
+		}
+	}()
+	return ln.Addr().String(), &accepted
+}
+
+func TestPooledClientReusesConnection(t *testing.T) {
+	address, accepted := startEchoServer(t)
+	c := NewPooledTCPSocketClient(time.Second, time.Second, config.TCPPoolConfig{MaxOpen: 2, HealthCheck: true})
+	defer c.Close()
+
+	for i := 0; i < 3; i++ {
+		got, err := c.SendAndReceive(address, "PING\r\n")
+		if err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		if got != "OK:PING\r\n" {
+			t.Fatalf("request %d: got %q", i, got)
+		}
+	}
+
+	stats := c.PoolStats()
+	if len(stats) != 1 {
+		t.Fatalf("expected one pool, got %d", len(stats))
+	}
+	if stats[0].Dials != 1 || stats[0].Reuses != 2 {
+		t.Errorf("unexpected stats: %s", stats[0])
+	}
+	if n := atomic.LoadInt32(accepted); n != 1 {
+		t.Errorf("server accepted %d connections, want 1", n)
+	}
+}
+
+func TestPooledClientDiscardsClosedConnection(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			// Answer once, then hang up like a non-persistent listener.
+			line, _ := bufio.NewReader(conn).ReadString('\n')
+			conn.Write([]byte("OK:" + line))
+			conn.Close()
+		}
+	}()
+
+	c := NewPooledTCPSocketClient(time.Second, time.Second, config.TCPPoolConfig{MaxOpen: 1, HealthCheck: true})
+	defer c.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.SendAndReceive(ln.Addr().String(), "PING\r\n"); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if s := c.PoolStats()[0]; s.Dials != 2 || s.Discards != 1 {
+		t.Errorf("unexpected stats: %s", s)
+	}
+}
+
+func TestClosePoolsExcept(t *testing.T) {
+	kept, _ := startEchoServer(t)
+	dropped, _ := startEchoServer(t)
+	c := NewPooledTCPSocketClient(time.Second, time.Second, config.TCPPoolConfig{MaxOpen: 1})
+	defer c.Close()
+	for _, address := range []string{kept, dropped} {
+		if _, err := c.SendAndReceive(address, "PING\r\n"); err != nil {
+			t.Fatalf("%s: %v", address, err)
+		}
+	}
+
+	c.ClosePoolsExcept(map[string]bool{kept: true})
+	if stats := c.PoolStats(); len(stats) != 1 || stats[0].Address != kept || stats[0].Idle != 1 {
+		t.Errorf("pools after the reload = %v, want only %s with its idle connection", stats, kept)
+	}
+}
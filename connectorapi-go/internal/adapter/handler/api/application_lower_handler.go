@@ -102,7 +102,7 @@ func (h *applicationLowerHandler) SubmitLoanApplication(c *gin.Context) {
 	if submitLoanApplicationResult.DomainError != nil {
 		responseError = submitLoanApplicationResult.DomainError
 	}
-	if !elkLog.FinalELKLog(submitLoanApplicationResult.GinCtx, &logList, submitLoanApplicationResult.Timestamp, req, "", submitLoanApplicationResult.DomainError, submitLoanApplicationResult.ServiceName, "", submitLoanApplicationResult.UserRef, []string{submitLoanApplicationResult.LogLine1}, h.logger, h.config.ELKPath, handleErrorResponse) {
+	if !elkLog.FinalELKLog(submitLoanApplicationResult.GinCtx, &logList, submitLoanApplicationResult.Timestamp, req, "", submitLoanApplicationResult.DomainError, submitLoanApplicationResult.ServiceName, "", submitLoanApplicationResult.UserToken, []string{submitLoanApplicationResult.LogLine1}, h.logger, h.config.ELKPath, handleErrorResponse) {
 		return
 	}
 	if responseError != nil {
@@ -173,7 +173,7 @@ func (h *commonHandler) CheckApplyCondition(c *gin.Context) {
 	if checkApplyConditionResult.DomainError != nil {
 		responseError = checkApplyConditionResult.DomainError
 	}
-	if !elkLog.FinalELKLog(checkApplyConditionResult.GinCtx, &logList, checkApplyConditionResult.Timestamp, req, checkApplyConditionResult.Response, checkApplyConditionResult.DomainError, checkApplyConditionResult.ServiceName, checkApplyConditionResult.UserRef, "", []string{checkApplyConditionResult.LogLine1}, h.logger, h.config.ELKPath, handleErrorResponse) {
+	if !elkLog.FinalELKLog(checkApplyConditionResult.GinCtx, &logList, checkApplyConditionResult.Timestamp, req, checkApplyConditionResult.Response, checkApplyConditionResult.DomainError, checkApplyConditionResult.ServiceName, checkApplyConditionResult.UserToken, "", []string{checkApplyConditionResult.LogLine1}, h.logger, h.config.ELKPath, handleErrorResponse) {
 		return
 	}
 	if responseError != nil {
@@ -236,7 +236,7 @@ func (h *commonHandler) CheckApplyCondition2ndCard(c *gin.Context) {
 	if checkApplyCondition2ndCardResult.DomainError != nil {
 		responseError = checkApplyCondition2ndCardResult.DomainError
 	}
-	if !elkLog.FinalELKLog(checkApplyCondition2ndCardResult.GinCtx, &logList, checkApplyCondition2ndCardResult.Timestamp, req, checkApplyCondition2ndCardResult.Response, checkApplyCondition2ndCardResult.DomainError, checkApplyCondition2ndCardResult.ServiceName, checkApplyCondition2ndCardResult.UserRef, "", []string{checkApplyCondition2ndCardResult.LogLine1}, h.logger, h.config.ELKPath, handleErrorResponse) {
+	if !elkLog.FinalELKLog(checkApplyCondition2ndCardResult.GinCtx, &logList, checkApplyCondition2ndCardResult.Timestamp, req, checkApplyCondition2ndCardResult.Response, checkApplyCondition2ndCardResult.DomainError, checkApplyCondition2ndCardResult.ServiceName, checkApplyCondition2ndCardResult.UserToken, "", []string{checkApplyCondition2ndCardResult.LogLine1}, h.logger, h.config.ELKPath, handleErrorResponse) {
 		return
 	}
 	if responseError != nil {
@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"connectorapi-go/internal/adapter/utils"
+	"connectorapi-go/internal/core/domain"
+	"connectorapi-go/pkg/config"
+	appError "connectorapi-go/pkg/error"
+	elkLog "connectorapi-go/internal/adapter/client/elk"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// configuredService defines the interface
+type configuredService interface {
+	Inquire(c *gin.Context, route config.Route, req map[string]interface{}) domain.ConfiguredResult
+}
+
+// configuredHandler serves the routes described only in
+// destinations_routes.json, the same way the hand-written handlers do. The
+// paths are registered once at startup; everything else about a route is read
+// from the routing in force when the request arrives.
+type configuredHandler struct {
+	service configuredService
+	routing *config.Routing
+	logger  *zap.SugaredLogger
+	apikey  *utils.APIKeyRepository
+	config  *config.Config
+}
+
+// NewConfiguredHandler creates a new instance of configuredHandler
+func NewConfiguredHandler(s configuredService, routing *config.Routing, logger *zap.SugaredLogger, apikey *utils.APIKeyRepository, cfg *config.Config) *configuredHandler {
+	return &configuredHandler{
+		service: s,
+		routing: routing,
+		logger:  logger,
+		apikey:  apikey,
+		config:  cfg,
+	}
+}
+
+// RegisterRoutes registers every configured route under the /Api group. A
+// route that a hand-written handler already serves (registered) is skipped.
+func (h *configuredHandler) RegisterRoutes(rg *gin.RouterGroup, registered gin.RoutesInfo) {
+	taken := make(map[string]bool, len(registered))
+	for _, r := range registered {
+		taken[r.Method+":"+r.Path] = true
+	}
+
+	routes := h.routing.Current().Routes
+	keys := make([]string, 0, len(routes))
+	for key, route := range routes {
+		if route.Configured() {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if taken[key] {
+			h.logger.Warnw("Configured route is already served by a handler; ignoring its layouts", "route", key)
+			continue
+		}
+		method, path, _ := strings.Cut(key, ":")
+		rg.Handle(method, strings.TrimPrefix(path, rg.BasePath()), h.handle(key))
+		h.logger.Infow("Registered configured route", "route", key, "service", routes[key].Name)
+	}
+}
+
+func (h *configuredHandler) handle(key string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req map[string]interface{}
+		timeNow := time.Now()
+		var logList []string
+
+		route, ok := h.routing.Current().Routes[key]
+		if !ok || !route.Configured() {
+			h.logger.Errorw("Configured route was removed by a reload", "route", key)
+			handleErrorResponse(c, appError.ErrService)
+			return
+		}
+		serviceName := route.Name
+
+		dec := json.NewDecoder(c.Request.Body)
+		dec.UseNumber()
+		if err := dec.Decode(&req); err != nil || req == nil {
+			handleErrorResponse(c, appError.ErrInternalServer)
+			return
+		}
+
+		appErr := ValidateHeaders(c, c.Request.Method, c.FullPath(), h.apikey, h.logger)
+		if appErr != nil {
+			handleErrorResponse(c, appErr)
+			if !elkLog.FinalELKLog(c, &logList, timeNow, &req, "", appErr, serviceName, "", "", nil, h.logger, h.config.ELKPath, handleErrorResponse) {
+				return
+			}
+			return
+		}
+
+		if appErr := validateRecord(req, route.Validation); appErr != nil {
+			handleErrorResponse(c, appErr)
+			if !elkLog.FinalELKLog(c, &logList, timeNow, &req, "", appErr, serviceName, "", "", nil, h.logger, h.config.ELKPath, handleErrorResponse) {
+				return
+			}
+			return
+		}
+
+		result := h.service.Inquire(c, route, req)
+		if result.AppError != nil {
+			handleErrorResponse(c, result.AppError)
+			return
+		}
+
+		var responseError *appError.AppError
+		if result.DomainError != nil {
+			responseError = result.DomainError
+		}
+		if !elkLog.FinalELKLog(result.GinCtx, &logList, result.Timestamp, req, result.Response, result.DomainError, result.ServiceName, "", result.UserRef, []string{result.LogLine1}, h.logger, h.config.ELKPath, handleErrorResponse) {
+			return
+		}
+		if responseError != nil {
+			handleErrorResponse(c, responseError)
+			return
+		}
+
+		c.JSON(http.StatusOK, result.Response)
+	}
+}
+
+// validateRecord applies a configured route's field rules, reporting problems
+// with the same errors HandleValidationError gives for struct tags.
+func validateRecord(req map[string]interface{}, rules map[string]config.FieldRule) *appError.AppError {
+	fields := make([]string, 0, len(rules))
+	for field := range rules {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var missingFields, lengthExceededFields, invalidValueFields []string
+	for _, field := range fields {
+		rule := rules[field]
+		v, ok := req[field]
+		if !ok || v == nil || v == "" {
+			if rule.Required {
+				missingFields = append(missingFields, field)
+			}
+			continue
+		}
+		var value string
+		switch v := v.(type) {
+		case string:
+			value = v
+		case json.Number:
+			value = v.String()
+		default:
+			invalidValueFields = append(invalidValueFields, field)
+			continue
+		}
+		if rule.MaxLength > 0 && utf8.RuneCountInString(value) > rule.MaxLength {
+			lengthExceededFields = append(lengthExceededFields, field)
+			continue
+		}
+		if !rule.Match(value) {
+			invalidValueFields = append(invalidValueFields, field)
+		}
+	}
+
+	if len(missingFields) > 0 {
+		return &appError.AppError{
+			ErrorCode:    appError.ErrRequiedParam.ErrorCode,
+			ErrorMessage: appError.ErrRequiedParam.ErrorMessage + "(" + strings.Join(missingFields, ", ") + ")",
+			Err:          fmt.Errorf("required fields: %v", missingFields),
+		}
+	}
+	if len(lengthExceededFields) > 0 {
+		return &appError.AppError{
+			ErrorCode:    appError.ErrInternalLength.ErrorCode,
+			ErrorMessage: appError.ErrInternalLength.ErrorMessage + " (" + strings.Join(lengthExceededFields, ", ") + ")",
+			Err:          fmt.Errorf("max length fields: %v", lengthExceededFields),
+		}
+	}
+	if len(invalidValueFields) > 0 {
+		return &appError.AppError{
+			ErrorCode:    appError.ErrRequiedParam.ErrorCode,
+			ErrorMessage: appError.ErrRequiedParam.ErrorMessage + " (" + strings.Join(invalidValueFields, ", ") + ")",
+			Err:          fmt.Errorf("invalid value fields: %v", invalidValueFields),
+		}
+	}
+	return nil
+}
@@ -2,10 +2,13 @@ package handler
 
 import (
 	"fmt"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
 
 	appError "connectorapi-go/pkg/error"
+	elkLog "connectorapi-go/internal/adapter/client/elk"
 	"connectorapi-go/internal/adapter/utils"
 
 	"github.com/gin-gonic/gin"
@@ -33,9 +36,15 @@ func handleErrorResponse(c *gin.Context, appErr *appError.AppError) {
 		statusCode = http.StatusUnauthorized
 	case appError.ErrTimeOut.ErrorCode:
 		statusCode = http.StatusGatewayTimeout
+	case appError.ErrSystemIResponse.ErrorCode:
+		statusCode = http.StatusBadGateway
 	default:
 		statusCode = http.StatusBadRequest
 	}
+	// A status set on the error itself (e.g. from the error catalog) wins.
+	if code, err := strconv.Atoi(appErr.StatusCode); err == nil && code >= 400 && code <= 599 {
+		statusCode = code
+	}
 
 	errResponse := appError.ErrorResponse{
 		ErrorCode:    appErr.ErrorCode,
@@ -63,12 +72,51 @@ func getAPIHeaders(c *gin.Context) apiHeaders {
 // 	}
 // }
 
+// refusals maps the check that refused a request to the error returned.
+var refusals = map[string]*appError.AppError{
+	"ip":       appError.ErrIPNotAllowed,
+	"channel":  appError.ErrChannelNotAllowed,
+	"deviceOS": appError.ErrDeviceOSNotAllowed,
+}
+
+// authorize validates the API key of the request against the client's
+// allowlists and permissions and keeps the outcome in the context for the
+// ELK main log.
+func authorize(c *gin.Context, apiKeyRepo *utils.APIKeyRepository, headers apiHeaders, method string, path string, logger *zap.SugaredLogger) *appError.AppError {
+	d := apiKeyRepo.Authorize(access(c, headers, method, path))
+	c.Set(elkLog.AuthRuleKey, d.Rule)
+	c.Set(elkLog.AuthCheckKey, d.Check)
+	if d.Allowed {
+		return nil
+	}
+	keyID := d.KeyID
+	if keyID == "" {
+		keyID = utils.PlainKeyID(headers.APIKey)
+	}
+	logger.Warnw("Authorization failed", "path", path, "client", d.Client, "apiKeyID", keyID, "check", d.Check, "rule", d.Rule)
+	if appErr, ok := refusals[d.Check]; ok {
+		return appErr
+	}
+	return appError.ErrUnauthorized
+}
+
+// access describes the request for the API key checks.
+func access(c *gin.Context, headers apiHeaders, method string, path string) utils.Access {
+	return utils.Access{
+		APIKey:   headers.APIKey,
+		Method:   method,
+		Path:     path,
+		IP:       net.ParseIP(c.ClientIP()),
+		Channel:  headers.Channel,
+		DeviceOS: headers.DeviceOS,
+	}
+}
+
 func ValidateHeaders(c *gin.Context, method string, path string, apiKeyRepo *utils.APIKeyRepository, logger *zap.SugaredLogger) *appError.AppError {
 	headers := getAPIHeaders(c)
 
-	if !apiKeyRepo.Validate(headers.APIKey, method, path) {
-		logger.Warnw("Authorization failed", "path", path, "apiKey", headers.APIKey)
-		return appError.ErrUnauthorized
+	if appErr := authorize(c, apiKeyRepo, headers, method, path, logger); appErr != nil {
+		return appErr
 	}
 
 	if headers.RequestID == "" || len(headers.RequestID) > 20 {
@@ -87,9 +135,8 @@ func ValidateHeaders(c *gin.Context, method string, path string, apiKeyRepo *uti
 func ValidateHeadersForApiKeyAndApiRequestID(c *gin.Context, method string, path string, apiKeyRepo *utils.APIKeyRepository, logger *zap.SugaredLogger) *appError.AppError {
 	headers := getAPIHeaders(c)
 
-	if !apiKeyRepo.Validate(headers.APIKey, method, path) {
-		logger.Warnw("Authorization failed", "path", path, "apiKey", headers.APIKey)
-		return appError.ErrUnauthorized
+	if appErr := authorize(c, apiKeyRepo, headers, method, path, logger); appErr != nil {
+		return appErr
 	}
 
 	if headers.RequestID == "" || len(headers.RequestID) > 20 {
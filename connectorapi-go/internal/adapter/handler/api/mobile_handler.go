@@ -73,11 +73,9 @@ func (h *mobileHandler) DashboardSummary(c *gin.Context) {
 		return
 	}
 
-	apiKey := c.GetHeader("Api-Key")
-	if !h.apikey.Validate(apiKey, c.Request.Method, c.FullPath()) {
-		h.logger.Errorw("Authorization failed", "path", c.FullPath(), "apiKey", apiKey)
-		handleErrorResponse(c, appError.ErrUnauthorized)
-		if !elkLog.FinalELKLog(c, &logList, timeNow, &req, "", appError.ErrUnauthorized, serviceName, "", "", nil, h.logger, h.config.ELKPath, handleErrorResponse) {
+	if appErr := authorize(c, h.apikey, getAPIHeaders(c), c.Request.Method, c.FullPath(), h.logger); appErr != nil {
+		handleErrorResponse(c, appErr)
+		if !elkLog.FinalELKLog(c, &logList, timeNow, &req, "", appErr, serviceName, "", "", nil, h.logger, h.config.ELKPath, handleErrorResponse) {
 			return
 		}
 		return
@@ -163,11 +161,9 @@ func (h *mobileHandler) DashboardDetail(c *gin.Context) {
 		return
 	}
 
-	apiKey := c.GetHeader("Api-Key")
-	if !h.apikey.Validate(apiKey, c.Request.Method, c.FullPath()) {
-		h.logger.Errorw("Authorization failed", "path", c.FullPath(), "apiKey", apiKey)
-		handleErrorResponse(c, appError.ErrUnauthorized)
-		if !elkLog.FinalELKLog(c, &logList, timeNow, &req, "", appError.ErrUnauthorized, serviceName, "", "", nil, h.logger, h.config.ELKPath, handleErrorResponse) {
+	if appErr := authorize(c, h.apikey, getAPIHeaders(c), c.Request.Method, c.FullPath(), h.logger); appErr != nil {
+		handleErrorResponse(c, appErr)
+		if !elkLog.FinalELKLog(c, &logList, timeNow, &req, "", appErr, serviceName, "", "", nil, h.logger, h.config.ELKPath, handleErrorResponse) {
 			return
 		}
 		return
@@ -2,13 +2,17 @@ package handler
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
 	"net/http"
+	"strconv"
 	"time"
 
 	"connectorapi-go/internal/adapter/utils"
+	appError "connectorapi-go/pkg/error"
 	"connectorapi-go/pkg/logger"
 	"connectorapi-go/pkg/metrics"
+	"connectorapi-go/pkg/tracing"
 	_ "connectorapi-go/docs"
 
 	"github.com/gin-gonic/gin"
@@ -16,6 +20,11 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -29,6 +38,7 @@ const apiChannel   = "Api-Channel"
 func SetupRouter(
 	appLogger *zap.SugaredLogger,
 	repo *utils.APIKeyRepository,
+	limiter utils.Limiter,
 	collectionHandler *collectionHandler,
 	agreementHandler *agreementHandler,
 	creditCardHandler *creditCardHandler,
@@ -41,10 +51,12 @@ func SetupRouter(
 	mobileHandler *mobileHandler,
 	applicationCapHandler *applicationCapHandler,
 	applicationLowerHandler *applicationLowerHandler,
+	configuredHandler *configuredHandler,
 ) *gin.Engine {
 	router := gin.New()
 
 	// --- Global Middlewares ---
+	router.Use(TracingMiddleware())
 	router.Use(ApiRequestIDMiddleware())
 	router.Use(ApiKeyMiddleware())
 	router.Use(ApiLanguageMiddleware())
@@ -61,8 +73,15 @@ func SetupRouter(
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// --- Status Group (API key with a GET:/status/... permission) ---
+	statusRoute := router.Group("/status", StatusAuthMiddleware(repo, appLogger))
+	{
+		statusRoute.GET("/breakers", BreakerStatus)
+		statusRoute.GET("/apikeys", APIKeyStatus(repo))
+	}
+
 	// --- API Group  ---
-	apiRoute := router.Group("/Api")
+	apiRoute := router.Group("/Api", RateLimitMiddleware(repo, limiter, appLogger))
 	{
 		collectionHandler.RegisterRoutes(apiRoute)
 		agreementHandler.RegisterRoutes(apiRoute)
@@ -76,6 +95,10 @@ func SetupRouter(
 		mobileHandler.RegisterRoutes(apiRoute)
 		applicationCapHandler.RegisterRoutes(apiRoute)
 		applicationLowerHandler.RegisterRoutes(apiRoute)
+
+		// Routes described only in destinations_routes.json; registered last
+		// so a hand-written handler always wins.
+		configuredHandler.RegisterRoutes(apiRoute, router.Routes())
 	}
 
 	return router
@@ -171,7 +194,142 @@ func PrometheusMiddleware() gin.HandlerFunc {
 	}
 }
 
+// TracingMiddleware starts the server span of each request, continuing the
+// trace of a W3C traceparent header when the caller sends one. The span is
+// carried by c.Request's context, where the services and the ELK log find it.
+func TracingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		name := c.Request.Method
+		if path := c.FullPath(); path != "" {
+			name += " " + path
+		}
+		ctx, span := tracing.Tracer().Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(c.FullPath()),
+				semconv.URLPath(c.Request.URL.Path),
+				tracing.RouteKey.String(c.Request.Method+":"+c.FullPath()),
+			))
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(
+			semconv.HTTPResponseStatusCode(status),
+			tracing.RequestIDKey.String(c.GetHeader(apiRequestID)),
+		)
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
+// StatusAuthMiddleware admits only API keys whose permissions cover the
+// status route, e.g. "GET:/status/*" for an operations client; the same
+// allowlists and deny rules apply as for /Api.
+func StatusAuthMiddleware(repo *utils.APIKeyRepository, appLogger *zap.SugaredLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if appErr := authorize(c, repo, getAPIHeaders(c), c.Request.Method, c.FullPath(), appLogger); appErr != nil {
+			handleErrorResponse(c, appErr)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RateLimitMiddleware enforces the rate limits and quotas of the client
+// presenting the API key before the handler runs, and answers 429 with
+// Retry-After when one is reached. Requests the key may not make pass through
+// uncounted; the handler refuses them.
+func RateLimitMiddleware(repo *utils.APIKeyRepository, limiter utils.Limiter, appLogger *zap.SugaredLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if appErr := limitRequest(c, repo, limiter, appLogger); appErr != nil {
+			handleErrorResponse(c, appErr)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// limitRequest counts the request against the limits of its route and of its
+// client, or against neither when one is reached, and returns SYS013 (rate)
+// or SYS014 (quota) with a Retry-After header in that case.
+func limitRequest(c *gin.Context, repo *utils.APIKeyRepository, limiter utils.Limiter, appLogger *zap.SugaredLogger) *appError.AppError {
+	headers := getAPIHeaders(c)
+	if d := repo.Check(access(c, headers, c.Request.Method, c.FullPath())); !d.Allowed {
+		return nil
+	}
+	client, entry := repo.Client(headers.APIKey)
+	if client == nil {
+		return nil
+	}
+	route := c.Request.Method + ":" + c.FullPath()
+	var checks []utils.LimitCheck
+	var scopes []string
+	if l, ok := client.RouteLimits[route]; ok {
+		checks = append(checks, utils.LimitCheck{Key: entry + " " + route, Limit: l})
+		scopes = append(scopes, route)
+	}
+	if client.RateLimit != nil {
+		checks = append(checks, utils.LimitCheck{Key: entry, Limit: *client.RateLimit})
+		scopes = append(scopes, "client")
+	}
+	if len(checks) == 0 {
+		return nil
+	}
+
+	results := limiter.Allow(checks, time.Now())
+	var refused *utils.LimitResult
+	for i, res := range results {
+		scope := scopes[i]
+		if metrics.RateLimitTokens != nil {
+			if checks[i].Limit.RequestsPerSecond > 0 {
+				metrics.RateLimitTokens.WithLabelValues(entry, scope).Set(res.Tokens)
+			}
+			metrics.QuotaUsed.WithLabelValues(entry, scope, "daily").Set(float64(res.Daily))
+			metrics.QuotaUsed.WithLabelValues(entry, scope, "monthly").Set(float64(res.Monthly))
+		}
+		if res.Limit == "" {
+			continue
+		}
+		if metrics.RateLimitedTotal != nil {
+			metrics.RateLimitedTotal.WithLabelValues(entry, scope, res.Limit).Inc()
+		}
+		appLogger.Warnw("Rate limit reached", "client", entry, "scope", scope, "limit", res.Limit, "retryAfter", res.RetryAfter)
+		if refused == nil || res.RetryAfter > refused.RetryAfter {
+			refused = &results[i]
+		}
+	}
+	if refused == nil {
+		return nil
+	}
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(refused.RetryAfter.Seconds()))))
+	if refused.Limit == "rate" {
+		return appError.ErrRateLimited
+	}
+	return appError.ErrQuotaExceeded
+}
+
 // HealthCheck provides a simple health check endpoint.
 func HealthCheck(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
+
+// BreakerStatus lists the circuit breaker state of every System-I port seen so far.
+func BreakerStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"breakers": utils.BreakerStatuses()})
+}
+
+// APIKeyStatus reports the validity window, request counts and last use of
+// every API key; keys themselves are never shown.
+func APIKeyStatus(repo *utils.APIKeyRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"apiKeys": repo.Usage()})
+	}
+}
@@ -1,12 +1,62 @@
 package utils
 
 import (
+	"crypto/subtle"
+	"encoding/hex"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"connectorapi-go/pkg/config"
+	"connectorapi-go/pkg/metrics"
 )
 
-// Validation of API keys based on configuration
+// Validation of API keys based on configuration. The keys can be replaced
+// while requests are being validated (see Replace); usage is kept per key ID
+// across replacements.
 type APIKeyRepository struct {
-	keys map[string]*config.APIKey
+	keys atomic.Pointer[keySet]
+
+	usageMu sync.Mutex
+	usage   map[string]*keyUsage // by key ID
+
+	now func() time.Time
+}
+
+// credential is one key of a client, plaintext or hashed.
+type credential struct {
+	id        string
+	entry     string // names the client entry, see Client
+	client    *config.APIKey
+	salt      []byte
+	hash      []byte
+	notBefore time.Time
+	expiresAt time.Time
+	usage     *keyUsage
+}
+
+type keySet struct {
+	plain  map[string]*credential
+	hashed []*credential
+}
+
+// KeyUsage is a snapshot of the requests made with one key.
+type KeyUsage struct {
+	ClientName string     `json:"clientName"`
+	ID         string     `json:"id"`
+	NotBefore  *time.Time `json:"notBefore,omitempty"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	Allowed    int64      `json:"allowed"`
+	Refused    int64      `json:"refused"`
+	LastUsed   *time.Time `json:"lastUsed,omitempty"`
+}
+
+type keyUsage struct {
+	mu sync.Mutex
+	KeyUsage
 }
 
 // // New repository and pre-loads keys into map
@@ -19,29 +69,249 @@ type APIKeyRepository struct {
 // }
 
 func NewAPIKeyRepository(apiKeys []config.APIKey) *APIKeyRepository {
-	keyMap := make(map[string]*config.APIKey)
+	r := &APIKeyRepository{usage: make(map[string]*keyUsage), now: time.Now}
+	r.Replace(apiKeys)
+	return r
+}
+
+// PlainKeyID names a plaintext key in logs and metrics without revealing it.
+func PlainKeyID(key string) string {
+	return "plain-" + config.Version([]byte(key))
+}
+
+// Replace swaps in a new set of keys; requests already validated are not
+// affected.
+func (r *APIKeyRepository) Replace(apiKeys []config.APIKey) {
+	set := &keySet{plain: make(map[string]*credential)}
 	for i := range apiKeys {
-		for _, k := range apiKeys[i].Key { // loop ทุก key ใน []string
-			keyMap[k] = &apiKeys[i]
+		client := &apiKeys[i]
+		if client.Rules == nil {
+			// Built in code rather than by config.ParseAPIKeys.
+			resolved := *client
+			resolved.Rules = client.ResolveRules(nil)
+			resolved.Networks, _ = client.ParseCIDRs()
+			client = &resolved
+		}
+		entry := client.ClientName
+		switch {
+		case len(client.Hashes) > 0:
+			entry += "/" + client.Hashes[0].ID
+		case len(client.Key) > 0:
+			entry += "/" + PlainKeyID(client.Key[0])
+		}
+		for _, k := range client.Key { // loop ทุก key ใน []string
+			set.plain[k] = &credential{id: PlainKeyID(k), entry: entry, client: client, usage: r.usageOf(client, PlainKeyID(k), nil, nil)}
+		}
+		for _, h := range client.Hashes {
+			salt, _ := hex.DecodeString(h.Salt)
+			hash, _ := hex.DecodeString(h.Hash)
+			c := &credential{id: h.ID, entry: entry, client: client, salt: salt, hash: hash, usage: r.usageOf(client, h.ID, h.NotBefore, h.ExpiresAt)}
+			if h.NotBefore != nil {
+				c.notBefore = *h.NotBefore
+			}
+			if h.ExpiresAt != nil {
+				c.expiresAt = *h.ExpiresAt
+			}
+			set.hashed = append(set.hashed, c)
 		}
 	}
-	return &APIKeyRepository{keys: keyMap}
+	r.keys.Store(set)
 }
 
-// Validate checks if an API key is valid, active, and has permission
-func (r *APIKeyRepository) Validate(apiKey, method, path string) bool {
-	clientKey, exists := r.keys[apiKey]
-	if !exists || clientKey.Status != "active" {
-		return false
+// usageOf returns the usage record of a key ID, keeping its counters when the
+// key survives a reload.
+func (r *APIKeyRepository) usageOf(client *config.APIKey, id string, notBefore, expiresAt *time.Time) *keyUsage {
+	r.usageMu.Lock()
+	defer r.usageMu.Unlock()
+	u, ok := r.usage[id]
+	if !ok {
+		u = &keyUsage{KeyUsage: KeyUsage{ID: id}}
+		r.usage[id] = u
 	}
+	u.mu.Lock()
+	u.ClientName, u.NotBefore, u.ExpiresAt = client.ClientName, notBefore, expiresAt
+	u.mu.Unlock()
+	return u
+}
 
-	// Check if the key has permission for the specific METHOD:PATH
-	routeKey := method + ":" + path
-	for _, p := range clientKey.Permissions {
-		if p == routeKey {
-			return true
+// lookup finds the credential apiKey matches. Hashed keys are compared in
+// constant time.
+func (s *keySet) lookup(apiKey string) *credential {
+	if c, ok := s.plain[apiKey]; ok {
+		return c
+	}
+	for _, c := range s.hashed {
+		sum, _ := hex.DecodeString(config.HashAPIKey(c.salt, apiKey))
+		if subtle.ConstantTimeCompare(sum, c.hash) == 1 {
+			return c
 		}
 	}
+	return nil
+}
+
+// Client returns the client entry apiKey belongs to and a name for the entry
+// (client name and first key ID) that is stable across reloads, without
+// recording a use. It returns nil for an unknown key.
+func (r *APIKeyRepository) Client(apiKey string) (*config.APIKey, string) {
+	cred := r.keys.Load().lookup(apiKey)
+	if cred == nil {
+		return nil, ""
+	}
+	return cred.client, cred.entry
+}
 
-	return false
+// Decision is the outcome of validating an API key for a request.
+type Decision struct {
+	Allowed bool
+	Client  string
+	KeyID   string
+	Check   string // the check that refused the request: key, ip, channel, deviceOS or permission
+	Rule    string // the rule that matched, or why none was considered
+}
+
+// Access is a request to authorize.
+type Access struct {
+	APIKey   string
+	Method   string
+	Path     string
+	IP       net.IP
+	Channel  string
+	DeviceOS string
+}
+
+// Validate checks if an API key is valid, active, within its validity window
+// and has permission. Deny rules win over permissions.
+func (r *APIKeyRepository) Validate(apiKey, method, path string) Decision {
+	return r.decide(Access{APIKey: apiKey, Method: method, Path: path}, false, true)
+}
+
+// Authorize is Validate plus the client's IP, channel and device OS
+// allowlists, checked before its permissions.
+func (r *APIKeyRepository) Authorize(a Access) Decision {
+	return r.decide(a, true, true)
+}
+
+// Check is Authorize without counting the request in the key's usage, for
+// looking ahead at a decision the handler will make.
+func (r *APIKeyRepository) Check(a Access) Decision {
+	return r.decide(a, true, false)
+}
+
+func (r *APIKeyRepository) decide(a Access, origin, record bool) Decision {
+	cred := r.keys.Load().lookup(a.APIKey)
+	if cred == nil {
+		if record {
+			recordKeyUse(nil, "unknown_key")
+		}
+		return Decision{Check: "key", Rule: "unknown key"}
+	}
+	d := Decision{Client: cred.client.ClientName, KeyID: cred.id, Check: "key"}
+	now := r.now()
+	use := func(result string) {
+		if record {
+			r.use(cred, now, result)
+		}
+	}
+	switch {
+	case cred.client.Status != "active":
+		use("inactive")
+		d.Rule = "client inactive"
+		return d
+	case !cred.notBefore.IsZero() && now.Before(cred.notBefore):
+		use("not_yet_valid")
+		d.Rule = "key not yet valid"
+		return d
+	case !cred.expiresAt.IsZero() && !now.Before(cred.expiresAt):
+		use("expired")
+		d.Rule = "key expired"
+		return d
+	}
+
+	if origin {
+		switch cred.client.Restriction(a.IP, a.Channel, a.DeviceOS) {
+		case "ip":
+			use("ip_not_allowed")
+			d.Check, d.Rule = "ip", "client IP "+a.IP.String()+" not in allowedCIDRs"
+			return d
+		case "channel":
+			use("channel_not_allowed")
+			d.Check, d.Rule = "channel", "Api-Channel "+strconv.Quote(a.Channel)+" not in allowedChannels"
+			return d
+		case "deviceOS":
+			use("device_os_not_allowed")
+			d.Check, d.Rule = "deviceOS", "Api-DeviceOS "+strconv.Quote(a.DeviceOS)+" not in allowedDeviceOS"
+			return d
+		}
+	}
+
+	// Deny rules come first in Rules, so the first match decides.
+	d.Check = "permission"
+	for _, rule := range cred.client.Rules {
+		if rule.Matches(a.Method, a.Path) {
+			d.Allowed, d.Rule = !rule.Deny, rule.String()
+			break
+		}
+	}
+	if d.Rule == "" {
+		d.Rule = "no matching permission"
+	}
+	if d.Allowed {
+		d.Check = ""
+		use("allowed")
+	} else {
+		use("denied")
+	}
+	return d
+}
+
+func (r *APIKeyRepository) use(cred *credential, now time.Time, result string) {
+	u := cred.usage
+	u.mu.Lock()
+	if result == "allowed" {
+		u.Allowed++
+	} else {
+		u.Refused++
+	}
+	u.LastUsed = &now
+	u.mu.Unlock()
+	recordKeyUse(cred, result)
+	if metrics.APIKeyLastUsed != nil {
+		metrics.APIKeyLastUsed.WithLabelValues(cred.client.ClientName, cred.id).Set(float64(now.Unix()))
+	}
+}
+
+func recordKeyUse(cred *credential, result string) {
+	if metrics.APIKeyRequestsTotal == nil {
+		return
+	}
+	client, id := "", ""
+	if cred != nil {
+		client, id = cred.client.ClientName, cred.id
+	}
+	metrics.APIKeyRequestsTotal.WithLabelValues(client, id, result).Inc()
+}
+
+// Usage returns a snapshot of the usage of every key in force, by client
+// then key ID.
+func (r *APIKeyRepository) Usage() []KeyUsage {
+	set := r.keys.Load()
+	creds := make([]*credential, 0, len(set.plain)+len(set.hashed))
+	for _, c := range set.plain {
+		creds = append(creds, c)
+	}
+	creds = append(creds, set.hashed...)
+
+	usage := make([]KeyUsage, 0, len(creds))
+	for _, c := range creds {
+		c.usage.mu.Lock()
+		usage = append(usage, c.usage.KeyUsage)
+		c.usage.mu.Unlock()
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].ClientName != usage[j].ClientName {
+			return usage[i].ClientName < usage[j].ClientName
+		}
+		return usage[i].ID < usage[j].ID
+	})
+	return usage
 }
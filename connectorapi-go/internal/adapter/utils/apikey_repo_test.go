@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"connectorapi-go/pkg/config"
+)
+
+func TestAPIKeyRepositoryReplace(t *testing.T) {
+	const route = "/Api/Collection/CollectionDetail"
+	repo := NewAPIKeyRepository([]config.APIKey{
+		{Key: []string{"OLD"}, Status: "active", Permissions: []string{"POST:" + route}},
+	})
+	if !repo.Validate("OLD", "POST", route).Allowed {
+		t.Fatal("initial key rejected")
+	}
+
+	repo.Replace([]config.APIKey{
+		{Key: []string{"NEW"}, Status: "active", Permissions: []string{"POST:" + route}},
+	})
+	if repo.Validate("OLD", "POST", route).Allowed {
+		t.Error("replaced key still accepted")
+	}
+	if !repo.Validate("NEW", "POST", route).Allowed {
+		t.Error("new key rejected")
+	}
+}
+
+func TestAPIKeyRepositoryHashedKeys(t *testing.T) {
+	const route = "/Api/Mobile/DashboardSummary"
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	oldExpiry, newStart := now.Add(time.Hour), now.Add(-time.Hour)
+	oldKey, oldHash, err := config.GenerateAPIKey("mobile-01", nil, &oldExpiry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, newHash, _ := config.GenerateAPIKey("mobile-02", &newStart, nil)
+	nextKey, nextHash, _ := config.GenerateAPIKey("mobile-03", &oldExpiry, nil)
+
+	keys := []config.APIKey{{
+		ClientName:  "MobileApp",
+		Status:      "active",
+		Hashes:      []config.APIKeyHash{oldHash, newHash, nextHash},
+		Permissions: []string{"POST:" + route},
+	}}
+	repo := NewAPIKeyRepository(keys)
+	repo.now = func() time.Time { return now }
+
+	if !repo.Validate(oldKey, "POST", route).Allowed || !repo.Validate(newKey, "POST", route).Allowed {
+		t.Fatal("keys inside their window rejected")
+	}
+	if repo.Validate(nextKey, "POST", route).Allowed {
+		t.Error("key accepted before notBefore")
+	}
+	if repo.Validate(newKey, "POST", "/Api/Mobile/DashboardDetail").Allowed {
+		t.Error("key accepted without permission")
+	}
+	repo.now = func() time.Time { return oldExpiry }
+	if repo.Validate(oldKey, "POST", route).Allowed {
+		t.Error("key accepted at expiresAt")
+	}
+	if !repo.Validate(nextKey, "POST", route).Allowed {
+		t.Error("key rejected from notBefore on")
+	}
+
+	// Usage survives a reload of the same key IDs.
+	repo.Replace(keys)
+	usage := map[string]KeyUsage{}
+	for _, u := range repo.Usage() {
+		usage[u.ID] = u
+	}
+	if u := usage["mobile-01"]; u.Allowed != 1 || u.Refused != 1 || u.LastUsed == nil || !u.LastUsed.Equal(oldExpiry) {
+		t.Errorf("mobile-01 usage = %+v", u)
+	}
+	if u := usage["mobile-02"]; u.Allowed != 1 || u.Refused != 1 || u.ClientName != "MobileApp" {
+		t.Errorf("mobile-02 usage = %+v", u)
+	}
+}
+
+func TestAPIKeyRepositoryRules(t *testing.T) {
+	keys, err := config.ParseAPIKeys([]byte(`{
+		"groups": {"mobile": {"permissions": ["POST:/Api/Mobile/*"]}},
+		"clients": [{"clientName": "MobileApp", "status": "active", "key": ["K"],
+			"groups": ["mobile"], "deny": ["POST:/Api/Mobile/DashboardDetail"]}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := NewAPIKeyRepository(keys)
+
+	for _, tc := range []struct {
+		key, path string
+		allowed   bool
+		rule      string
+	}{
+		{"K", "/Api/Mobile/DashboardSummary", true, "allow POST:/Api/Mobile/* (group:mobile)"},
+		{"K", "/Api/Mobile/DashboardDetail", false, "deny POST:/Api/Mobile/DashboardDetail (client)"},
+		{"K", "/Api/Collection/CollectionLog", false, "no matching permission"},
+		{"X", "/Api/Mobile/DashboardSummary", false, "unknown key"},
+	} {
+		d := repo.Validate(tc.key, "POST", tc.path)
+		if d.Allowed != tc.allowed || d.Rule != tc.rule {
+			t.Errorf("Validate(%s, %s) = %+v", tc.key, tc.path, d)
+		}
+	}
+}
+
+func TestAPIKeyRepositoryAuthorize(t *testing.T) {
+	keys, err := config.ParseAPIKeys([]byte(`[{"clientName": "Partner", "status": "active", "key": ["K"],
+		"permissions": ["POST:/Api/Mobile/*"], "allowedCIDRs": ["10.1.0.0/16", "192.168.1.5"],
+		"allowedChannels": ["L", "F"], "allowedDeviceOS": ["android"]}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := NewAPIKeyRepository(keys)
+	ok := Access{APIKey: "K", Method: "POST", Path: "/Api/Mobile/DashboardSummary", IP: net.ParseIP("10.1.2.3"), Channel: "L", DeviceOS: "Android"}
+
+	for _, tc := range []struct {
+		name  string
+		edit  func(a *Access)
+		check string
+	}{
+		{"allowed", func(a *Access) {}, ""},
+		{"single IP", func(a *Access) { a.IP = net.ParseIP("192.168.1.5") }, ""},
+		{"other network", func(a *Access) { a.IP = net.ParseIP("10.2.0.1") }, "ip"},
+		{"no IP", func(a *Access) { a.IP = nil }, "ip"},
+		{"channel", func(a *Access) { a.Channel = "W" }, "channel"},
+		{"device OS", func(a *Access) { a.DeviceOS = "ios" }, "deviceOS"},
+		{"permission", func(a *Access) { a.Path = "/Api/Collection/CollectionLog" }, "permission"},
+	} {
+		a := ok
+		tc.edit(&a)
+		d := repo.Authorize(a)
+		if d.Allowed != (tc.check == "") || d.Check != tc.check {
+			t.Errorf("%s: %+v", tc.name, d)
+		}
+	}
+
+	// Validate leaves the allowlists to Authorize.
+	if !repo.Validate("K", "POST", "/Api/Mobile/DashboardSummary").Allowed {
+		t.Error("Validate applied the allowlists")
+	}
+	if _, err := config.ParseAPIKeys([]byte(`[{"clientName": "X", "allowedCIDRs": ["10.0.0.0/33"]}]`)); err == nil {
+		t.Error("invalid CIDR accepted")
+	}
+}
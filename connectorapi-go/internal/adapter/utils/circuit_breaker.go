@@ -0,0 +1,255 @@
+package utils
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"connectorapi-go/pkg/config"
+	"connectorapi-go/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Breaker states, also used as the value of the circuit_breaker_state gauge.
+const (
+	BreakerClosed   = 0
+	BreakerHalfOpen = 1
+	BreakerOpen     = 2
+)
+
+var breakerStateNames = map[int]string{
+	BreakerClosed:   "closed",
+	BreakerHalfOpen: "half-open",
+	BreakerOpen:     "open",
+}
+
+// BreakerStatus is the public view of one ip:port breaker.
+type BreakerStatus struct {
+	Address             string    `json:"address"`
+	State               string    `json:"state"`
+	ConsecutiveFailures int       `json:"consecutiveFailures"`
+	Ejections           int       `json:"ejections"`
+	OpenedAt            time.Time `json:"openedAt,omitempty"`
+	RetryAt             time.Time `json:"retryAt,omitempty"`
+	LastError           string    `json:"lastError,omitempty"`
+}
+
+type portBreaker struct {
+	state     int
+	failures  int
+	ejections int
+	openedAt  time.Time
+	retryAt   time.Time
+	probing   bool
+	kept      bool // open, but put back by AvailablePorts to respect MaxEjectionPercent
+	lastError string
+}
+
+// breakerRegistry holds one breaker per System-I listener, keyed by ip:port.
+type breakerRegistry struct {
+	mu       sync.Mutex
+	cfg      config.CircuitBreakerConfig
+	breakers map[string]*portBreaker
+}
+
+var breakers = &breakerRegistry{
+	cfg:      config.CircuitBreakerConfig{FailureThreshold: 5, OpenTimeout: 30 * time.Second, MaxEjectionPercent: 50},
+	breakers: make(map[string]*portBreaker),
+}
+
+// ConfigureBreakers replaces the breaker settings. Zero values keep the defaults.
+func ConfigureBreakers(cfg config.CircuitBreakerConfig) {
+	breakers.mu.Lock()
+	defer breakers.mu.Unlock()
+	if cfg.FailureThreshold > 0 {
+		breakers.cfg.FailureThreshold = cfg.FailureThreshold
+	}
+	if cfg.OpenTimeout > 0 {
+		breakers.cfg.OpenTimeout = cfg.OpenTimeout
+	}
+	if cfg.MaxEjectionPercent > 0 {
+		breakers.cfg.MaxEjectionPercent = cfg.MaxEjectionPercent
+	}
+	breakers.cfg.Disabled = cfg.Disabled
+}
+
+// BreakerAllow reports whether a request may be sent to address. An open
+// breaker whose ejection time has passed lets exactly one probe through; one
+// that AvailablePorts kept to respect MaxEjectionPercent lets every request
+// through, each an outcome that may close it.
+func BreakerAllow(address string) bool {
+	breakers.mu.Lock()
+	defer breakers.mu.Unlock()
+	if breakers.cfg.Disabled {
+		return true
+	}
+	b, ok := breakers.breakers[address]
+	if !ok {
+		return true
+	}
+	switch b.state {
+	case BreakerOpen:
+		if time.Now().Before(b.retryAt) {
+			return b.kept
+		}
+		breakers.transition(address, b, BreakerHalfOpen)
+		b.probing = true
+		return true
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+	return true
+}
+
+// BreakerRecord feeds the outcome of one exchange with address into its
+// breaker. Only listener failures (ER040/ER050/ER060) count; an empty code is
+// a success and any other code is ignored.
+func BreakerRecord(address string, errorCode string) {
+	breakers.mu.Lock()
+	defer breakers.mu.Unlock()
+	if breakers.cfg.Disabled {
+		return
+	}
+	b, ok := breakers.breakers[address]
+	if !ok {
+		b = &portBreaker{}
+		breakers.breakers[address] = b
+	}
+
+	switch errorCode {
+	case "":
+		b.failures = 0
+		b.probing = false
+		b.lastError = ""
+		if b.state != BreakerClosed {
+			b.ejections = 0
+			breakers.transition(address, b, BreakerClosed)
+		}
+	case "ER040", "ER050", "ER060":
+		b.failures++
+		b.probing = false
+		b.lastError = errorCode
+		// A kept port that fails again stays open until its retry time.
+		if b.state == BreakerHalfOpen || (b.state == BreakerClosed && b.failures >= breakers.cfg.FailureThreshold) {
+			breakers.open(address, b)
+		}
+	default:
+		if b.state == BreakerHalfOpen {
+			b.probing = false
+		}
+	}
+}
+
+// BreakerRelease ends a half-open probe to address without an outcome, for
+// exchanges that failed on this side, such as an exhausted connection pool,
+// and so say nothing about the listener.
+func BreakerRelease(address string) {
+	breakers.mu.Lock()
+	defer breakers.mu.Unlock()
+	if b, ok := breakers.breakers[address]; ok && b.state == BreakerHalfOpen {
+		b.probing = false
+	}
+}
+
+// open ejects a listener. Repeat offenders stay out longer, up to 10x OpenTimeout.
+func (r *breakerRegistry) open(address string, b *portBreaker) {
+	if b.ejections < 10 {
+		b.ejections++
+	}
+	now := time.Now()
+	b.openedAt = now
+	b.retryAt = now.Add(time.Duration(b.ejections) * r.cfg.OpenTimeout)
+	r.transition(address, b, BreakerOpen)
+}
+
+func (r *breakerRegistry) transition(address string, b *portBreaker, state int) {
+	b.state = state
+	b.kept = false
+	if metrics.CircuitBreakerState != nil {
+		metrics.CircuitBreakerState.WithLabelValues(address).Set(float64(state))
+		metrics.CircuitBreakerTransitions.WithLabelValues(address, breakerStateNames[state]).Inc()
+	}
+}
+
+// AvailablePorts filters out ports whose breaker is open. It never ejects more
+// than MaxEjectionPercent of the list: when too many listeners are open the
+// ones closest to their retry time are kept, and admitted by BreakerAllow, so
+// traffic still flows.
+func AvailablePorts(ip string, portList []string) []string {
+	breakers.mu.Lock()
+	defer breakers.mu.Unlock()
+	if breakers.cfg.Disabled || len(portList) == 0 {
+		return portList
+	}
+
+	now := time.Now()
+	available := make([]string, 0, len(portList))
+	var ejected []string
+	for _, port := range portList {
+		b, ok := breakers.breakers[ip+":"+port]
+		if ok && b.state == BreakerOpen && now.Before(b.retryAt) {
+			b.kept = false
+			ejected = append(ejected, port)
+			continue
+		}
+		available = append(available, port)
+	}
+
+	maxEjected := len(portList) * breakers.cfg.MaxEjectionPercent / 100
+	if len(ejected) <= maxEjected {
+		return available
+	}
+	sort.Slice(ejected, func(i, j int) bool {
+		return breakers.breakers[ip+":"+ejected[i]].retryAt.Before(breakers.breakers[ip+":"+ejected[j]].retryAt)
+	})
+	kept := ejected[:len(ejected)-maxEjected]
+	for _, port := range kept {
+		breakers.breakers[ip+":"+port].kept = true
+	}
+	return append(available, kept...)
+}
+
+// DropBreakersExcept forgets the breaker of every address not in keep, such as
+// listeners a routing reload removed.
+func DropBreakersExcept(keep map[string]bool) {
+	breakers.mu.Lock()
+	defer breakers.mu.Unlock()
+	for address := range breakers.breakers {
+		if keep[address] {
+			continue
+		}
+		delete(breakers.breakers, address)
+		if metrics.CircuitBreakerState != nil {
+			metrics.CircuitBreakerState.DeleteLabelValues(address)
+			metrics.CircuitBreakerTransitions.DeletePartialMatch(prometheus.Labels{"address": address})
+		}
+	}
+}
+
+// BreakerStatuses returns every known breaker, sorted by address.
+func BreakerStatuses() []BreakerStatus {
+	breakers.mu.Lock()
+	defer breakers.mu.Unlock()
+	statuses := make([]BreakerStatus, 0, len(breakers.breakers))
+	for address, b := range breakers.breakers {
+		s := BreakerStatus{
+			Address:             address,
+			State:               breakerStateNames[b.state],
+			ConsecutiveFailures: b.failures,
+			Ejections:           b.ejections,
+			LastError:           b.lastError,
+		}
+		if b.state != BreakerClosed {
+			s.OpenedAt = b.openedAt
+			s.RetryAt = b.retryAt
+		}
+		statuses = append(statuses, s)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Address < statuses[j].Address })
+	return statuses
+}
@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"connectorapi-go/pkg/config"
+)
+
+func resetBreakers(t *testing.T, cfg config.CircuitBreakerConfig) {
+	t.Helper()
+	breakers = &breakerRegistry{cfg: cfg, breakers: make(map[string]*portBreaker)}
+}
+
+func TestBreakerTripsAndProbes(t *testing.T) {
+	resetBreakers(t, config.CircuitBreakerConfig{FailureThreshold: 3, OpenTimeout: 20 * time.Millisecond, MaxEjectionPercent: 100})
+	addr := "10.0.0.1:40110"
+
+	for i := 0; i < 2; i++ {
+		BreakerRecord(addr, "ER040")
+	}
+	if !BreakerAllow(addr) {
+		t.Fatal("breaker opened before threshold")
+	}
+	BreakerRecord(addr, "ER099")
+	BreakerRecord(addr, "ER050")
+	if BreakerAllow(addr) {
+		t.Fatal("breaker should be open after 3 consecutive failures")
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	if !BreakerAllow(addr) {
+		t.Fatal("half-open breaker should allow one probe")
+	}
+	if BreakerAllow(addr) {
+		t.Fatal("half-open breaker should allow only one probe at a time")
+	}
+	BreakerRecord(addr, "")
+	if got := BreakerStatuses()[0].State; got != "closed" {
+		t.Fatalf("state after successful probe = %q, want closed", got)
+	}
+}
+
+func TestAvailablePortsRespectsMaxEjection(t *testing.T) {
+	resetBreakers(t, config.CircuitBreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute, MaxEjectionPercent: 50})
+	ports := []string{"1", "2", "3", "4"}
+	BreakerRecord("ip:1", "ER060")
+	if got := AvailablePorts("ip", ports); len(got) != 3 {
+		t.Fatalf("AvailablePorts = %v, want 3 ports", got)
+	}
+	BreakerRecord("ip:2", "ER060")
+	BreakerRecord("ip:3", "ER060")
+	if got := AvailablePorts("ip", ports); len(got) != 2 {
+		t.Fatalf("AvailablePorts = %v, want at most half the ports ejected", got)
+	}
+
+	// The kept ports are admitted; the ejected one is not.
+	available := AvailablePorts("ip", ports)
+	for _, port := range ports {
+		want := containsPort(available, port)
+		if got := BreakerAllow("ip:" + port); got != want {
+			t.Errorf("BreakerAllow(ip:%s) = %v, want %v", port, got, want)
+		}
+	}
+	// A kept port that answers closes.
+	kept := available[1]
+	BreakerRecord("ip:"+kept, "")
+	for _, st := range BreakerStatuses() {
+		if st.Address == "ip:"+kept && st.State != "closed" {
+			t.Errorf("kept port %s is %s after a success", kept, st.State)
+		}
+	}
+}
+
+func TestBreakerReleaseEndsProbe(t *testing.T) {
+	resetBreakers(t, config.CircuitBreakerConfig{FailureThreshold: 1, OpenTimeout: time.Millisecond, MaxEjectionPercent: 100})
+	addr := "10.0.0.1:40110"
+	BreakerRecord(addr, "ER050")
+	time.Sleep(2 * time.Millisecond)
+	if !BreakerAllow(addr) {
+		t.Fatal("no probe after the open timeout")
+	}
+	// The probe never reached the listener, e.g. the pool was exhausted.
+	BreakerRelease(addr)
+	if !BreakerAllow(addr) {
+		t.Error("probe not released")
+	}
+}
+
+func containsPort(ports []string, port string) bool {
+	for _, p := range ports {
+		if p == port {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDropBreakersExcept(t *testing.T) {
+	resetBreakers(t, config.CircuitBreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute, MaxEjectionPercent: 100})
+	BreakerRecord("ip:1", "ER040")
+	BreakerRecord("ip:2", "ER040")
+
+	DropBreakersExcept(map[string]bool{"ip:1": true})
+	if statuses := BreakerStatuses(); len(statuses) != 1 || statuses[0].Address != "ip:1" {
+		t.Errorf("breakers = %+v, want only ip:1", statuses)
+	}
+	if !BreakerAllow("ip:2") {
+		t.Error("a dropped address is still ejected")
+	}
+}
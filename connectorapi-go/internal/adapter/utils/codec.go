@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Wire codecs accepted in the codec field of a destination.
+const (
+	CodecCP874  = "cp874"
+	CodecTIS620 = "tis620"
+	CodecIBM838 = "ibm838"
+	CodecUTF8   = "utf8"
+)
+
+// Codec converts between the gateway's UTF-8 strings and the bytes a System-I
+// listener expects on the wire.
+type Codec interface {
+	Name() string
+	Encode(s string) ([]byte, error)
+	Decode(b []byte) (string, error)
+	// Encodable reports whether r can be sent on the wire, so requests can
+	// be checked before the payload is built.
+	Encodable(r rune) bool
+	// EncodedLen is the number of bytes Encode(s) produces, for the header
+	// request length field.
+	EncodedLen(s string) int
+	// Delimiter is the encoded byte that ends a response.
+	Delimiter() byte
+}
+
+// CodecByName returns the codec for a destination setting. An empty name is
+// cp874, the encoding every listener has used so far.
+func CodecByName(name string) (Codec, error) {
+	switch name {
+	case "", CodecCP874:
+		return cp874Codec{}, nil
+	case CodecTIS620:
+		return tis620Codec{}, nil
+	case CodecIBM838:
+		return ibm838Codec{}, nil
+	case CodecUTF8:
+		return utf8Codec{}, nil
+	}
+	return nil, fmt.Errorf("unknown codec %q", name)
+}
+
+// cp874Codec is Windows-874, TIS-620 plus the Microsoft punctuation in 0x80-0xA0.
+type cp874Codec struct{}
+
+func (cp874Codec) Name() string                    { return CodecCP874 }
+func (cp874Codec) Delimiter() byte                 { return '\n' }
+func (cp874Codec) Encode(s string) ([]byte, error) { return Utf8ToCP874(s) }
+func (cp874Codec) Decode(b []byte) (string, error) { return DecodeCP874(b) }
+
+func (cp874Codec) EncodedLen(s string) int { return utf8.RuneCountInString(s) }
+
+func (cp874Codec) Encodable(r rune) bool {
+	_, ok := charmap.Windows874.EncodeRune(r)
+	return ok
+}
+
+// tis620Codec is strict TIS-620: ASCII plus Thai in 0xA1-0xFB. The
+// Windows-874 extensions are rejected on encode and invalid on decode.
+type tis620Codec struct{}
+
+func (tis620Codec) Name() string    { return CodecTIS620 }
+func (tis620Codec) Delimiter() byte { return '\n' }
+
+func (tis620Codec) EncodedLen(s string) int { return utf8.RuneCountInString(s) }
+
+func (tis620Codec) Encodable(r rune) bool {
+	return r < 0x80 || r >= 0x0E01 && r <= 0x0E3A || r >= 0x0E3F && r <= 0x0E5B
+}
+
+func (c tis620Codec) Encode(s string) ([]byte, error) {
+	out := make([]byte, 0, len(s))
+	for offset, r := range s {
+		switch {
+		case r < 0x80:
+			out = append(out, byte(r))
+		case c.Encodable(r):
+			out = append(out, byte(r-0x0E01+0xA1))
+		default:
+			return nil, &EncodeError{Rune: r, Offset: offset, Codec: CodecTIS620}
+		}
+	}
+	return out, nil
+}
+
+func (tis620Codec) Decode(b []byte) (string, error) {
+	runes := make([]rune, 0, len(b))
+	for i, c := range b {
+		switch {
+		case c < 0x80:
+			runes = append(runes, rune(c))
+		case c >= 0xA1 && c <= 0xDA, c >= 0xDF && c <= 0xFB:
+			runes = append(runes, rune(c)-0xA1+0x0E01)
+		default:
+			return "", fmt.Errorf("invalid TIS-620 byte 0x%02X at offset %d", c, i)
+		}
+	}
+	return string(runes), nil
+}
+
+// utf8Codec sends the payload unchanged.
+type utf8Codec struct{}
+
+func (utf8Codec) Name() string    { return CodecUTF8 }
+func (utf8Codec) Delimiter() byte { return '\n' }
+
+func (utf8Codec) Encodable(r rune) bool { return utf8.ValidRune(r) }
+
+// EncodedLen counts bytes: Thai characters take three each in UTF-8.
+func (utf8Codec) EncodedLen(s string) int { return len(s) }
+
+func (utf8Codec) Encode(s string) ([]byte, error) {
+	if !utf8.ValidString(s) {
+		return nil, fmt.Errorf("payload is not valid UTF-8")
+	}
+	return []byte(s), nil
+}
+
+func (utf8Codec) Decode(b []byte) (string, error) {
+	if !utf8.Valid(b) {
+		return "", fmt.Errorf("response is not valid UTF-8")
+	}
+	return string(b), nil
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// ibm838ToUnicode is the EBCDIC Thai code page (CCSID 838) indexed by byte.
+// 0xFE is unassigned in 838 (CCSID 1160 puts the euro sign there) and
+// decodes to U+FFFD.
+var ibm838ToUnicode = [256]rune{
+	0x0000, 0x0001, 0x0002, 0x0003, 0x009C, 0x0009, 0x0086, 0x007F, // 0x00
+	0x0097, 0x008D, 0x008E, 0x000B, 0x000C, 0x000D, 0x000E, 0x000F, // 0x08
+	0x0010, 0x0011, 0x0012, 0x0013, 0x009D, 0x0085, 0x0008, 0x0087, // 0x10
+	0x0018, 0x0019, 0x0092, 0x008F, 0x001C, 0x001D, 0x001E, 0x001F, // 0x18
+	0x0080, 0x0081, 0x0082, 0x0083, 0x0084, 0x000A, 0x0017, 0x001B, // 0x20
+	0x0088, 0x0089, 0x008A, 0x008B, 0x008C, 0x0005, 0x0006, 0x0007, // 0x28
+	0x0090, 0x0091, 0x0016, 0x0093, 0x0094, 0x0095, 0x0096, 0x0004, // 0x30
+	0x0098, 0x0099, 0x009A, 0x009B, 0x0014, 0x0015, 0x009E, 0x001A, // 0x38
+	0x0020, 0x00A0, 0x0E01, 0x0E02, 0x0E03, 0x0E04, 0x0E05, 0x0E06, // 0x40
+	0x0E07, 0x005B, 0x00A2, 0x002E, 0x003C, 0x0028, 0x002B, 0x007C, // 0x48
+	0x0026, 0x0E48, 0x0E08, 0x0E09, 0x0E0A, 0x0E0B, 0x0E0C, 0x0E0D, // 0x50
+	0x0E0E, 0x005D, 0x0021, 0x0024, 0x002A, 0x0029, 0x003B, 0x00AC, // 0x58
+	0x002D, 0x002F, 0x0E0F, 0x0E10, 0x0E11, 0x0E12, 0x0E13, 0x0E14, // 0x60
+	0x0E15, 0x005E, 0x00A6, 0x002C, 0x0025, 0x005F, 0x003E, 0x003F, // 0x68
+	0x0E3F, 0x0E4E, 0x0E16, 0x0E17, 0x0E18, 0x0E19, 0x0E1A, 0x0E1B, // 0x70
+	0x0E1C, 0x0060, 0x003A, 0x0023, 0x0040, 0x0027, 0x003D, 0x0022, // 0x78
+	0x0E4F, 0x0061, 0x0062, 0x0063, 0x0064, 0x0065, 0x0066, 0x0067, // 0x80
+	0x0068, 0x0069, 0x0E1D, 0x0E1E, 0x0E1F, 0x0E20, 0x0E21, 0x0E22, // 0x88
+	0x0E5A, 0x006A, 0x006B, 0x006C, 0x006D, 0x006E, 0x006F, 0x0070, // 0x90
+	0x0071, 0x0072, 0x0E23, 0x0E24, 0x0E25, 0x0E26, 0x0E27, 0x0E28, // 0x98
+	0x0E5B, 0x007E, 0x0073, 0x0074, 0x0075, 0x0076, 0x0077, 0x0078, // 0xA0
+	0x0079, 0x007A, 0x0E29, 0x0E2A, 0x0E2B, 0x0E2C, 0x0E2D, 0x0E2E, // 0xA8
+	0x0E50, 0x0E51, 0x0E52, 0x0E53, 0x0E54, 0x0E55, 0x0E56, 0x0E57, // 0xB0
+	0x0E58, 0x0E59, 0x0E2F, 0x0E30, 0x0E31, 0x0E32, 0x0E33, 0x0E34, // 0xB8
+	0x007B, 0x0041, 0x0042, 0x0043, 0x0044, 0x0045, 0x0046, 0x0047, // 0xC0
+	0x0048, 0x0049, 0x0E49, 0x0E35, 0x0E36, 0x0E37, 0x0E38, 0x0E39, // 0xC8
+	0x007D, 0x004A, 0x004B, 0x004C, 0x004D, 0x004E, 0x004F, 0x0050, // 0xD0
+	0x0051, 0x0052, 0x0E3A, 0x0E40, 0x0E41, 0x0E42, 0x0E43, 0x0E44, // 0xD8
+	0x005C, 0x0E4A, 0x0053, 0x0054, 0x0055, 0x0056, 0x0057, 0x0058, // 0xE0
+	0x0059, 0x005A, 0x0E45, 0x0E46, 0x0E47, 0x0E48, 0x0E49, 0x0E4A, // 0xE8
+	0x0030, 0x0031, 0x0032, 0x0033, 0x0034, 0x0035, 0x0036, 0x0037, // 0xF0
+	0x0038, 0x0039, 0x0E4B, 0x0E4C, 0x0E4D, 0x0E4B, 0xFFFD, 0x009F, // 0xF8
+}
+
+// unicodeToIBM838 is the reverse of ibm838ToUnicode. Four tone marks appear
+// twice in 838; the encoder uses the positions IBM's own converters pick
+// (0xED, 0xEE, 0xEF and 0xFA).
+var unicodeToIBM838 = func() map[rune]byte {
+	m := make(map[rune]byte, 256)
+	for b, r := range ibm838ToUnicode {
+		if r == 0xFFFD {
+			continue
+		}
+		if _, ok := m[r]; !ok {
+			m[r] = byte(b)
+		}
+	}
+	m[0x0E48], m[0x0E49], m[0x0E4A], m[0x0E4B] = 0xED, 0xEE, 0xEF, 0xFA
+	return m
+}()
+
+// ibm838Codec speaks native EBCDIC Thai. Responses end with the EBCDIC line
+// feed (0x25).
+type ibm838Codec struct{}
+
+func (ibm838Codec) Name() string { return CodecIBM838 }
+
+func (ibm838Codec) Delimiter() byte { return 0x25 }
+
+func (ibm838Codec) Encode(s string) ([]byte, error) {
+	out := make([]byte, 0, len(s))
+	for offset, r := range s {
+		b, ok := unicodeToIBM838[r]
+		if !ok {
+			return nil, &EncodeError{Rune: r, Offset: offset, Codec: CodecIBM838}
+		}
+		out = append(out, b)
+	}
+	return out, nil
+}
+
+func (ibm838Codec) EncodedLen(s string) int { return utf8.RuneCountInString(s) }
+
+func (ibm838Codec) Encodable(r rune) bool {
+	_, ok := unicodeToIBM838[r]
+	return ok
+}
+
+func (ibm838Codec) Decode(b []byte) (string, error) {
+	var sb strings.Builder
+	sb.Grow(len(b) * 3)
+	for _, c := range b {
+		sb.WriteRune(ibm838ToUnicode[c])
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+)
+
+// thaiCharset is every assigned character of the TIS-620 Thai block.
+func thaiCharset() string {
+	var runes []rune
+	for r := rune(0x0E01); r <= 0x0E5B; r++ {
+		if r >= 0x0E3B && r <= 0x0E3E {
+			continue
+		}
+		runes = append(runes, r)
+	}
+	return string(runes)
+}
+
+func TestCodecsRoundTripThai(t *testing.T) {
+	input := "AEON 0123 " + thaiCharset() + " สวัสดีครับ\r\n"
+	for _, name := range []string{CodecCP874, CodecTIS620, CodecIBM838, CodecUTF8} {
+		codec, err := CodecByName(name)
+		if err != nil {
+			t.Fatalf("CodecByName(%q): %v", name, err)
+		}
+		encoded, err := codec.Encode(input)
+		if err != nil {
+			t.Fatalf("%s encode: %v", name, err)
+		}
+		if encoded[len(encoded)-1] != codec.Delimiter() {
+			t.Errorf("%s: newline encodes to 0x%02X, delimiter is 0x%02X", name, encoded[len(encoded)-1], codec.Delimiter())
+		}
+		decoded, err := codec.Decode(encoded)
+		if err != nil {
+			t.Fatalf("%s decode: %v", name, err)
+		}
+		if decoded != input {
+			t.Errorf("%s round trip mismatch:\n got %q\nwant %q", name, decoded, input)
+		}
+	}
+}
+
+func TestIBM838KnownBytes(t *testing.T) {
+	codec, _ := CodecByName(CodecIBM838)
+	encoded, err := codec.Encode("A0กฮ่้๊๋\n")
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	want := []byte{0xC1, 0xF0, 0x42, 0xAF, 0xED, 0xEE, 0xEF, 0xFA, 0x25}
+	if !bytes.Equal(encoded, want) {
+		t.Fatalf("encoded % X, want % X", encoded, want)
+	}
+
+	// The duplicate tone-mark positions decode to the same characters.
+	decoded, _ := codec.Decode([]byte{0x51, 0xCA, 0xE1, 0xFD})
+	if decoded != "่้๊๋" {
+		t.Fatalf("duplicate tone marks decoded to %q", decoded)
+	}
+}
+
+func TestCodecRejectsUnencodable(t *testing.T) {
+	for _, name := range []string{CodecTIS620, CodecIBM838} {
+		codec, _ := CodecByName(name)
+		if _, err := codec.Encode("price €5"); err == nil {
+			t.Errorf("%s accepted the euro sign", name)
+		}
+	}
+	if _, err := CodecByName("ebcdic"); err == nil {
+		t.Error("unknown codec name accepted")
+	}
+}
+
+func TestRequestLengthCountsEncodedBytes(t *testing.T) {
+	for name, want := range map[string]string{CodecCP874: "00008", CodecIBM838: "00008", CodecUTF8: "00020"} {
+		codec, _ := CodecByName(name)
+		if got := RequestLength("สวัสดี12", codec); got != want {
+			t.Errorf("%s: RequestLength = %s, want %s", name, got, want)
+		}
+	}
+}
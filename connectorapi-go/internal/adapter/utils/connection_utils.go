@@ -29,3 +29,14 @@ func RandomPortFromList(portList []string) string {
 	defer rngMu.Unlock()
 	return portList[rng.Intn(len(portList))]
 }
+
+// ExcludePorts returns the ports from portList that are not in exclude.
+func ExcludePorts(portList []string, exclude map[string]bool) []string {
+	remaining := make([]string, 0, len(portList))
+	for _, p := range portList {
+		if !exclude[p] {
+			remaining = append(remaining, p)
+		}
+	}
+	return remaining
+}
@@ -4,42 +4,257 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"reflect"
+	"strings"
+	"unicode"
 
 	"golang.org/x/text/encoding/charmap"
 	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
 )
 
-// UTF8 to CP874 mapping (only for Thai characters ก - ฮ and common ASCII)
+// Fallback policies for characters that the destination's codec cannot
+// represent, set per route with EncodeFallback. Reject is the default.
+const (
+	FallbackReject        = "reject"
+	FallbackTransliterate = "transliterate"
+	FallbackReplace       = "replace"
+)
+
+// EncodeError reports a character that cannot be sent to System-I. Field is
+// the JSON path of the request field when the error comes from SanitizeFields;
+// Codec is the codec name, empty for Windows-874.
+type EncodeError struct {
+	Field  string
+	Rune   rune
+	Offset int
+	Codec  string
+}
+
+func (e *EncodeError) Error() string {
+	target := "CP874"
+	if e.Codec != "" {
+		target = strings.ToUpper(e.Codec)
+	}
+	if e.Field != "" {
+		return fmt.Sprintf("field %s: cannot encode rune '%c' (U+%04X) at offset %d to %s", e.Field, e.Rune, e.Rune, e.Offset, target)
+	}
+	return fmt.Sprintf("cannot encode rune '%c' (U+%04X) at offset %d to %s", e.Rune, e.Rune, e.Offset, target)
+}
+
+// transliterations covers characters outside Windows-874 that commonly arrive
+// from mobile keyboards and word processors. Anything not listed here falls
+// back to its NFKD compatibility form with combining marks removed.
+var transliterations = map[rune]string{
+	'\u00A9': "(C)", // copyright sign
+	'\u00AB': "\"",  // left guillemet
+	'\u00AE': "(R)", // registered sign
+	'\u00B7': ".",   // middle dot
+	'\u00BB': "\"",  // right guillemet
+	'\u00D7': "x",   // multiplication sign
+	'\u00F7': "/",   // division sign
+	'\u2010': "-",   // hyphen
+	'\u2011': "-",   // non-breaking hyphen
+	'\u2012': "-",   // figure dash
+	'\u2015': "-",   // horizontal bar
+	'\u201A': "'",   // single low-9 quotation mark
+	'\u201B': "'",   // single high-reversed-9 quotation mark
+	'\u201E': "\"",  // double low-9 quotation mark
+	'\u201F': "\"",  // double high-reversed-9 quotation mark
+	'\u2032': "'",   // prime
+	'\u2033': "\"",  // double prime
+	'\u2039': "<",   // single left angle quotation mark
+	'\u203A': ">",   // single right angle quotation mark
+	'\u2212': "-",   // minus sign
+	'\u3000': " ",   // ideographic space
+	'\u200B': "",    // zero width space
+	'\u200C': "",    // zero width non-joiner
+	'\u200D': "",    // zero width joiner
+	'\uFEFF': "",    // byte order mark
+}
+
+// Utf8ToCP874 encodes input as Windows-874, rejecting any character the code
+// page cannot represent.
 func Utf8ToCP874(input string) ([]byte, error) {
-	var result []byte
-	for _, r := range input {
-		// Handle newline separately
-		if r == '\n' {
-			result = append(result, 0x0A)
+	return EncodeCP874(input, FallbackReject)
+}
+
+// EncodeCP874 encodes input as Windows-874, applying fallback to characters
+// the code page cannot represent.
+func EncodeCP874(input string, fallback string) ([]byte, error) {
+	result := make([]byte, 0, len(input))
+	for offset, r := range input {
+		if b, ok := charmap.Windows874.EncodeRune(r); ok {
+			result = append(result, b)
 			continue
 		}
-		if r == '\r' {
-			result = append(result, 0x0D)
-			continue
+		replacement, err := fallbackRune(r, fallback, cp874Codec{})
+		if err != nil {
+			return nil, &EncodeError{Rune: r, Offset: offset}
+		}
+		for _, rr := range replacement {
+			b, _ := charmap.Windows874.EncodeRune(rr)
+			result = append(result, b)
 		}
+	}
+	return result, nil
+}
 
-		// ASCII range: pass through
-		if r <= 0x7F {
-			result = append(result, byte(r))
+// ToCP874Safe rewrites input so that every character can be encoded as
+// Windows-874, using the given fallback policy.
+func ToCP874Safe(input string, fallback string) (string, error) {
+	return ToCodecSafe(input, cp874Codec{}, fallback)
+}
+
+// ToCodecSafe rewrites input so that every character can be encoded by codec,
+// using the given fallback policy.
+func ToCodecSafe(input string, codec Codec, fallback string) (string, error) {
+	var sb strings.Builder
+	changed := false
+	for offset, r := range input {
+		if codec.Encodable(r) {
+			sb.WriteRune(r)
 			continue
 		}
+		replacement, err := fallbackRune(r, fallback, codec)
+		if err != nil {
+			return "", &EncodeError{Rune: r, Offset: offset, Codec: codec.Name()}
+		}
+		sb.WriteString(replacement)
+		changed = true
+	}
+	if !changed {
+		return input, nil
+	}
+	return sb.String(), nil
+}
+
+func fallbackRune(r rune, fallback string, codec Codec) (string, error) {
+	switch fallback {
+	case FallbackReplace:
+		return "?", nil
+	case FallbackTransliterate:
+		if s, ok := transliterate(r, codec); ok {
+			return s, nil
+		}
+		return "?", nil
+	}
+	return "", fmt.Errorf("unencodable rune U+%04X", r)
+}
 
-		// Thai range ก - ฮ (U+0E01 to U+0E5B)
-		if r >= 0x0E01 && r <= 0x0E5B {
-			cp874Byte := byte(r - 0x0E01 + 0xA1)
-			result = append(result, cp874Byte)
+// transliterate returns an equivalent of r that codec can encode, if there is
+// one.
+func transliterate(r rune, codec Codec) (string, bool) {
+	if s, ok := transliterations[r]; ok {
+		return s, true
+	}
+	var sb strings.Builder
+	for _, d := range norm.NFKD.String(string(r)) {
+		if unicode.Is(unicode.Mn, d) {
 			continue
 		}
+		if !codec.Encodable(d) {
+			return "", false
+		}
+		sb.WriteRune(d)
+	}
+	if sb.Len() == 0 {
+		return "", false
+	}
+	return sb.String(), true
+}
+
+// SanitizeCP874Fields is SanitizeFields for a Windows-874 destination.
+func SanitizeCP874Fields(v interface{}, fallback string) error {
+	return SanitizeFields(v, cp874Codec{}, fallback)
+}
 
-		// Unsupported character
-		return nil, fmt.Errorf("cannot encode rune '%c' (U+%04X) to CP874", r, r)
+// SanitizeFields applies the fallback policy to every string field of the
+// struct v points to, including nested structs and slices, so the formatted
+// request can always be encoded by codec. Under the reject policy it returns
+// an *EncodeError naming the first offending field by its JSON name.
+func SanitizeFields(v interface{}, codec Codec, fallback string) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("SanitizeFields needs a non-nil pointer, got %T", v)
 	}
-	return result, nil
+	return sanitizeValue(rv.Elem(), "", codec, fallback)
+}
+
+func sanitizeValue(v reflect.Value, path string, codec Codec, fallback string) error {
+	switch v.Kind() {
+	case reflect.String:
+		safe, err := ToCodecSafe(v.String(), codec, fallback)
+		if err != nil {
+			encErr := err.(*EncodeError)
+			encErr.Field = path
+			return encErr
+		}
+		if v.CanSet() && safe != v.String() {
+			v.SetString(safe)
+		}
+	case reflect.Ptr:
+		if !v.IsNil() {
+			return sanitizeValue(v.Elem(), path, codec, fallback)
+		}
+	case reflect.Interface:
+		// The value inside an interface cannot be set in place: sanitize a
+		// copy and store it back.
+		if v.IsNil() {
+			return nil
+		}
+		elem := reflect.New(v.Elem().Type()).Elem()
+		elem.Set(v.Elem())
+		if err := sanitizeValue(elem, path, codec, fallback); err != nil {
+			return err
+		}
+		if v.CanSet() {
+			v.Set(elem)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			elem := reflect.New(iter.Value().Type()).Elem()
+			elem.Set(iter.Value())
+			if err := sanitizeValue(elem, joinFieldPath(path, fmt.Sprint(iter.Key().Interface())), codec, fallback); err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			if err := sanitizeValue(v.Field(i), joinFieldPath(path, jsonFieldName(f)), codec, fallback); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := sanitizeValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), codec, fallback); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func jsonFieldName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return f.Name
+	}
+	return name
+}
+
+func joinFieldPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
 }
 
 func DecodeCP874(input []byte) (string, error) {
@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEncodeCP874AgreesWithDecoder(t *testing.T) {
+	input := "สวัสดี “quoted” €10… ok"
+	encoded, err := Utf8ToCP874(input)
+	if err != nil {
+		t.Fatalf("Utf8ToCP874: %v", err)
+	}
+	decoded, err := DecodeCP874(encoded)
+	if err != nil {
+		t.Fatalf("DecodeCP874: %v", err)
+	}
+	if decoded != input {
+		t.Fatalf("round trip = %q, want %q", decoded, input)
+	}
+}
+
+func TestEncodeCP874Fallback(t *testing.T) {
+	input := "１２×Café−\U0001F600"
+	if _, err := EncodeCP874(input, FallbackReject); err == nil {
+		t.Fatal("reject policy accepted unencodable input")
+	}
+
+	tests := []struct {
+		fallback string
+		want     string
+	}{
+		{FallbackReplace, "???Caf???"},
+		{FallbackTransliterate, "12xCafe-?"},
+	}
+	for _, tt := range tests {
+		encoded, err := EncodeCP874(input, tt.fallback)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.fallback, err)
+		}
+		got, _ := DecodeCP874(encoded)
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.fallback, got, tt.want)
+		}
+	}
+}
+
+func TestSanitizeCP874FieldsReportsField(t *testing.T) {
+	type item struct {
+		Remark string `json:"Remark"`
+	}
+	req := struct {
+		Name  string `json:"Name"`
+		Items []item `json:"Items"`
+	}{Name: "สมชาย", Items: []item{{Remark: "ok"}, {Remark: "bad☃"}}}
+
+	err := SanitizeCP874Fields(&req, FallbackReject)
+	var encErr *EncodeError
+	if !errors.As(err, &encErr) {
+		t.Fatalf("err = %v, want *EncodeError", err)
+	}
+	if encErr.Field != "Items[1].Remark" || encErr.Rune != '☃' {
+		t.Fatalf("got field %q rune %U", encErr.Field, encErr.Rune)
+	}
+
+	if err := SanitizeCP874Fields(&req, FallbackReplace); err != nil {
+		t.Fatalf("replace policy: %v", err)
+	}
+	if req.Items[1].Remark != "bad?" {
+		t.Fatalf("Remark = %q, want %q", req.Items[1].Remark, "bad?")
+	}
+}
+
+func TestSanitizeCP874FieldsDecodedJSON(t *testing.T) {
+	req := map[string]interface{}{
+		"Name":  "ok",
+		"Items": []interface{}{map[string]interface{}{"Remark": "bad☃"}},
+	}
+
+	err := SanitizeCP874Fields(&req, FallbackReject)
+	var encErr *EncodeError
+	if !errors.As(err, &encErr) || encErr.Field != "Items[0].Remark" {
+		t.Fatalf("err = %v, want an EncodeError for Items[0].Remark", err)
+	}
+
+	if err := SanitizeCP874Fields(&req, FallbackReplace); err != nil {
+		t.Fatalf("replace policy: %v", err)
+	}
+	got := req["Items"].([]interface{})[0].(map[string]interface{})["Remark"]
+	if got != "bad?" {
+		t.Fatalf("Remark = %q, want %q", got, "bad?")
+	}
+}
+
+func TestSanitizeFieldsUsesDestinationCodec(t *testing.T) {
+	// The euro sign is Windows-874 but neither TIS-620 nor IBM-838.
+	req := struct {
+		Name string `json:"Name"`
+	}{Name: "€5"}
+	if err := SanitizeCP874Fields(&req, FallbackReject); err != nil {
+		t.Fatalf("cp874: %v", err)
+	}
+	for _, name := range []string{CodecTIS620, CodecIBM838} {
+		codec, _ := CodecByName(name)
+		err := SanitizeFields(&req, codec, FallbackReject)
+		var encErr *EncodeError
+		if !errors.As(err, &encErr) || encErr.Field != "Name" || encErr.Codec != name {
+			t.Fatalf("%s: err = %v, want an EncodeError for Name", name, err)
+		}
+	}
+
+	codec, _ := CodecByName(CodecIBM838)
+	if err := SanitizeFields(&req, codec, FallbackReplace); err != nil {
+		t.Fatalf("replace policy: %v", err)
+	}
+	if req.Name != "?5" {
+		t.Fatalf("Name = %q, want %q", req.Name, "?5")
+	}
+}
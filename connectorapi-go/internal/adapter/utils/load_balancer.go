@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"hash/fnv"
+	"sync"
+
+	"connectorapi-go/pkg/config"
+)
+
+// Load-balancing strategies accepted in destinations_routes.json.
+const (
+	StrategyRandom           = "random"
+	StrategyRoundRobin       = "round-robin"
+	StrategyLeastOutstanding = "least-outstanding"
+	StrategyWeighted         = "weighted"
+	StrategySticky           = "sticky"
+)
+
+var (
+	lbMu        sync.Mutex
+	roundRobin  = make(map[string]uint64)
+	outstanding = make(map[string]int)
+)
+
+// PortSelector picks System-I ports for one service of one destination.
+// Key is the value hashed by the sticky strategy, normally the IDCardNo.
+type PortSelector struct {
+	IP       string
+	Service  string
+	Key      string
+	strategy string
+	weights  map[string]int
+}
+
+// NewPortSelector returns the selector configured for service on dest.
+func NewPortSelector(dest config.Destination, service string, key string) PortSelector {
+	return PortSelector{
+		IP:       dest.IP,
+		Service:  service,
+		Key:      key,
+		strategy: dest.Strategy(service),
+		weights:  dest.PortWeights[service],
+	}
+}
+
+// Pick returns a port from portList, skipping ports ejected by their circuit
+// breaker. It returns an empty string if the list is empty.
+func (s PortSelector) Pick(portList []string) string {
+	ports := AvailablePorts(s.IP, portList)
+	if len(ports) == 0 {
+		return ""
+	}
+	switch s.strategy {
+	case StrategyRoundRobin:
+		return s.roundRobin(ports)
+	case StrategyLeastOutstanding:
+		return s.leastOutstanding(ports)
+	case StrategyWeighted:
+		return s.weighted(ports)
+	case StrategySticky:
+		if s.Key != "" {
+			return s.sticky(ports)
+		}
+	}
+	return RandomPortFromList(ports)
+}
+
+func (s PortSelector) roundRobin(ports []string) string {
+	lbMu.Lock()
+	defer lbMu.Unlock()
+	counterKey := s.IP + "/" + s.Service
+	n := roundRobin[counterKey]
+	roundRobin[counterKey] = n + 1
+	return ports[n%uint64(len(ports))]
+}
+
+// leastOutstanding picks the port with the fewest requests in flight, breaking
+// ties at random so idle listeners share the load.
+func (s PortSelector) leastOutstanding(ports []string) string {
+	lbMu.Lock()
+	best := []string{}
+	min := -1
+	for _, port := range ports {
+		n := outstanding[s.IP+":"+port]
+		switch {
+		case min < 0 || n < min:
+			min = n
+			best = []string{port}
+		case n == min:
+			best = append(best, port)
+		}
+	}
+	lbMu.Unlock()
+	return RandomPortFromList(best)
+}
+
+// weighted picks at random in proportion to the weight configured with the
+// "port:weight" syntax. Ports without a weight count as 1.
+func (s PortSelector) weighted(ports []string) string {
+	total := 0
+	for _, port := range ports {
+		total += s.weight(port)
+	}
+	rngMu.Lock()
+	n := rng.Intn(total)
+	rngMu.Unlock()
+	for _, port := range ports {
+		n -= s.weight(port)
+		if n < 0 {
+			return port
+		}
+	}
+	return ports[len(ports)-1]
+}
+
+func (s PortSelector) weight(port string) int {
+	if w, ok := s.weights[port]; ok && w > 0 {
+		return w
+	}
+	return 1
+}
+
+// sticky uses rendezvous hashing so a key keeps its port while that port is
+// available, and only the keys of an ejected port move elsewhere.
+func (s PortSelector) sticky(ports []string) string {
+	best := ""
+	var bestScore uint64
+	for _, port := range ports {
+		h := fnv.New64a()
+		h.Write([]byte(s.Key))
+		h.Write([]byte{0})
+		h.Write([]byte(port))
+		if score := h.Sum64(); best == "" || score > bestScore {
+			best, bestScore = port, score
+		}
+	}
+	return best
+}
+
+// TrackOutstanding counts a request in flight to address for the
+// least-outstanding strategy. Call the returned function when it completes.
+func TrackOutstanding(address string) func() {
+	lbMu.Lock()
+	outstanding[address]++
+	lbMu.Unlock()
+	return func() {
+		lbMu.Lock()
+		outstanding[address]--
+		if outstanding[address] <= 0 {
+			delete(outstanding, address)
+		}
+		lbMu.Unlock()
+	}
+}
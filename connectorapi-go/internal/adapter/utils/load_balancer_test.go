@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"connectorapi-go/pkg/config"
+)
+
+func TestPortSelectorStrategies(t *testing.T) {
+	resetBreakers(t, config.CircuitBreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute, MaxEjectionPercent: 50})
+	ports := []string{"40110", "40111", "40112"}
+	dest := config.Destination{
+		IP: "10.0.0.9",
+		ServiceLoadBalancing: map[string]string{
+			"RR":     StrategyRoundRobin,
+			"Sticky": StrategySticky,
+			"Least":  StrategyLeastOutstanding,
+			"Weight": StrategyWeighted,
+		},
+		PortWeights: map[string]map[string]int{"Weight": {"40112": 1000000}},
+	}
+
+	rr := NewPortSelector(dest, "RR", "")
+	for i := 0; i < 6; i++ {
+		if got := rr.Pick(ports); got != ports[i%3] {
+			t.Fatalf("round-robin pick %d = %s, want %s", i, got, ports[i%3])
+		}
+	}
+
+	sticky := NewPortSelector(dest, "Sticky", "1234567890123")
+	first := sticky.Pick(ports)
+	for i := 0; i < 10; i++ {
+		if got := sticky.Pick(ports); got != first {
+			t.Fatalf("sticky pick moved from %s to %s", first, got)
+		}
+	}
+
+	done1 := TrackOutstanding("10.0.0.9:40110")
+	done2 := TrackOutstanding("10.0.0.9:40112")
+	if got := NewPortSelector(dest, "Least", "").Pick(ports); got != "40111" {
+		t.Fatalf("least-outstanding pick = %s, want 40111", got)
+	}
+	done1()
+	done2()
+
+	if got := NewPortSelector(dest, "Weight", "").Pick(ports); got != "40112" {
+		t.Fatalf("weighted pick = %s, want heavily weighted 40112", got)
+	}
+
+	BreakerRecord("10.0.0.9:"+first, "ER040")
+	if got := sticky.Pick(ports); got == first {
+		t.Fatalf("sticky pick %s should skip the ejected port", got)
+	}
+}
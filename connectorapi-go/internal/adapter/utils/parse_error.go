@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseError is a response field whose text does not match its declared type.
+// Offset is in characters from the start of the body, after the header.
+type ParseError struct {
+	Field  string `json:"field"`
+	Offset int    `json:"offset"`
+	Raw    string `json:"raw"`
+}
+
+// ParseErrors lists every malformed field of one record.
+type ParseErrors []ParseError
+
+func (e ParseErrors) Error() string {
+	parts := make([]string, len(e))
+	for i, pe := range e {
+		parts[i] = fmt.Sprintf("%s at %d: %q", pe.Field, pe.Offset, pe.Raw)
+	}
+	return "malformed fields: " + strings.Join(parts, ", ")
+}
+
+// Fields returns the names of the malformed fields.
+func (e ParseErrors) Fields() []string {
+	names := make([]string, len(e))
+	for i, pe := range e {
+		names[i] = pe.Field
+	}
+	return names
+}
@@ -6,13 +6,18 @@ import (
 	"fmt"
 )
 
+// FixedParser reads fields of a fixed-length body. Numeric fields that do
+// not parse read as 0 and are recorded; Err returns them once the record has
+// been read so the caller can decide whether a zeroed field is acceptable.
 type FixedParser struct {
 	data []rune
+	errs *ParseErrors
 }
 
 func NewFixedParser(data string) FixedParser {
 	return FixedParser{
 		data: []rune(data),
+		errs: &ParseErrors{},
 	}
 }
 
@@ -36,25 +41,53 @@ func (p FixedParser) ReadString(start, length int) string {
 }
 
 // ReadInt แปลง substring เป็น int
-func (p FixedParser) ReadInt(start, length int) int {
+func (p FixedParser) ReadInt(field string, start, length int) int {
 	s := p.ReadString(start, length)
 	if s == "" {
 		return 0
 	}
-	i, _ := strconv.Atoi(s)
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		p.fail(field, start, length)
+		return 0
+	}
 	return i
 }
 
 // ReadFloat100 แปลง substring เป็น float64 แล้วหาร 100 (รองรับ decimal 2 ตำแหน่ง)
-func (p FixedParser) ReadFloat100(start, length int) float64 {
+func (p FixedParser) ReadFloat100(field string, start, length int) float64 {
 	s := p.ReadString(start, length)
 	if s == "" {
 		return 0.0
 	}
-	i, _ := strconv.ParseInt(s, 10, 64)
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		p.fail(field, start, length)
+		return 0.0
+	}
 	return float64(i) / 100.0
 }
 
+// ReadDecimal100 is ReadFloat100 for amounts rendered with two decimals in JSON.
+func (p FixedParser) ReadDecimal100(field string, start, length int) DecimalString {
+	return DecimalString(p.ReadFloat100(field, start, length))
+}
+
+// Err returns the numeric fields that failed to parse, or nil.
+func (p FixedParser) Err() error {
+	if p.errs == nil || len(*p.errs) == 0 {
+		return nil
+	}
+	return *p.errs
+}
+
+func (p FixedParser) fail(field string, start, length int) {
+	if p.errs == nil {
+		return
+	}
+	*p.errs = append(*p.errs, ParseError{Field: field, Offset: start, Raw: string(ReadRunes(p.data, start, length))})
+}
+
 func ReadRunes(runes []rune, start int, length int) []rune {
 	if start >= len(runes) {
 		return []rune{}
@@ -77,6 +110,9 @@ func ReadBlockStr(blockRunes []rune, start int, length int) string {
 	return strings.TrimSpace(string(blockRunes[start:end]))
 }
 
+// ReadBlockInt and ReadBlockFloat100ToDecimal read leniently: text that is
+// not a number becomes 0 and nothing is recorded. Use FixedParser where a
+// corrupted field must be reported.
 func  ReadBlockInt(blockRunes []rune, start int, length int) int {
 	s := ReadBlockStr(blockRunes, start, length)
 	if s == "" {
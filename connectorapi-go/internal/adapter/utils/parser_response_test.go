@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFixedParserRecordsMalformedNumbers(t *testing.T) {
+	p := NewFixedParser("0012  1A345  00000250")
+
+	if got := p.ReadInt("Count", 0, 4); got != 12 {
+		t.Fatalf("Count = %d, want 12", got)
+	}
+	if got := p.ReadFloat100("Balance", 4, 7); got != 0 {
+		t.Fatalf("Balance = %v, want 0 for malformed text", got)
+	}
+	if got := p.ReadDecimal100("Limit", 11, 10); got != 2.5 {
+		t.Fatalf("Limit = %v, want 2.5", got)
+	}
+
+	var parseErrs ParseErrors
+	if !errors.As(p.Err(), &parseErrs) || len(parseErrs) != 1 {
+		t.Fatalf("Err() = %v, want one ParseError", p.Err())
+	}
+	if pe := parseErrs[0]; pe.Field != "Balance" || pe.Offset != 4 || pe.Raw != "  1A345" {
+		t.Fatalf("ParseError = %+v", pe)
+	}
+}
+
+func TestFixedParserBlankNumberIsNotAnError(t *testing.T) {
+	p := NewFixedParser("    ")
+	if p.ReadInt("Count", 0, 4) != 0 || p.Err() != nil {
+		t.Fatalf("blank field: Err() = %v, want nil", p.Err())
+	}
+}
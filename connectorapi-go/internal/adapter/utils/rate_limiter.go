@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"connectorapi-go/pkg/config"
+)
+
+// Limiter enforces config.RateLimit for counter keys (a client, or a client
+// on one route). Allow counts a request against every check, or against none
+// when one of them refuses it, and returns a result per check. Implementations
+// must be safe for concurrent use; the in-memory one counts per process.
+type Limiter interface {
+	Allow(checks []LimitCheck, now time.Time) []LimitResult
+}
+
+// LimitCheck is one limit a request is counted against.
+type LimitCheck struct {
+	Key   string
+	Limit config.RateLimit
+}
+
+// LimitResult is the outcome of one check of Limiter.Allow. Allowed is false
+// for every check of a refused request; Limit is set on those that refused it.
+type LimitResult struct {
+	Allowed    bool
+	Limit      string        // rate, daily or monthly when refused
+	RetryAfter time.Duration // when the refused request may be retried
+	Tokens     float64       // left in the bucket
+	Daily      int64         // requests counted today
+	Monthly    int64         // requests counted this month
+}
+
+// MemoryLimiter keeps a token bucket and the quota counters of every key in
+// memory.
+type MemoryLimiter struct {
+	mu     sync.Mutex
+	states map[string]*limitState
+}
+
+type limitState struct {
+	tokens  float64
+	last    time.Time
+	day     time.Time // start of the day counted in daily
+	month   time.Time // start of the month counted in monthly
+	daily   int64
+	monthly int64
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{states: make(map[string]*limitState)}
+}
+
+// Allow checks every limit before counting the request against any of them,
+// so a request refused by one check takes no token or quota from the others.
+func (l *MemoryLimiter) Allow(checks []LimitCheck, now time.Time) []LimitResult {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	states := make([]*limitState, len(checks))
+	results := make([]LimitResult, len(checks))
+	allowed := true
+	for i, check := range checks {
+		states[i] = l.state(check.Key, check.Limit, now)
+		results[i] = states[i].check(check.Limit, now)
+		allowed = allowed && results[i].Limit == ""
+	}
+	if !allowed {
+		return results
+	}
+	for i, check := range checks {
+		results[i] = states[i].take(check.Limit)
+	}
+	return results
+}
+
+// state returns the counters of key, with the bucket refilled and the quotas
+// reset for now.
+func (l *MemoryLimiter) state(key string, limit config.RateLimit, now time.Time) *limitState {
+	burst := float64(limit.Burst)
+	if burst == 0 {
+		burst = math.Max(1, math.Ceil(limit.RequestsPerSecond))
+	}
+	s, ok := l.states[key]
+	if !ok {
+		s = &limitState{tokens: burst, last: now}
+		l.states[key] = s
+	}
+	if limit.RequestsPerSecond > 0 {
+		s.tokens = math.Min(burst, s.tokens+now.Sub(s.last).Seconds()*limit.RequestsPerSecond)
+	}
+	s.last = now
+
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	if !s.day.Equal(day) {
+		s.day, s.daily = day, 0
+	}
+	if !s.month.Equal(month) {
+		s.month, s.monthly = month, 0
+	}
+
+	return s
+}
+
+// check reports the limit a request would reach, without counting it. Quotas
+// are checked first so a request refused for quota keeps its token.
+func (s *limitState) check(limit config.RateLimit, now time.Time) LimitResult {
+	res := LimitResult{Tokens: s.tokens, Daily: s.daily, Monthly: s.monthly}
+	switch {
+	case limit.Monthly > 0 && s.monthly >= limit.Monthly:
+		res.Limit, res.RetryAfter = "monthly", s.month.AddDate(0, 1, 0).Sub(now)
+	case limit.Daily > 0 && s.daily >= limit.Daily:
+		res.Limit, res.RetryAfter = "daily", s.day.AddDate(0, 0, 1).Sub(now)
+	case limit.RequestsPerSecond > 0 && s.tokens < 1:
+		res.Limit = "rate"
+		res.RetryAfter = time.Duration((1 - s.tokens) / limit.RequestsPerSecond * float64(time.Second))
+	}
+	return res
+}
+
+// take counts an allowed request.
+func (s *limitState) take(limit config.RateLimit) LimitResult {
+	if limit.RequestsPerSecond > 0 {
+		s.tokens--
+	}
+	s.daily++
+	s.monthly++
+	return LimitResult{Allowed: true, Tokens: s.tokens, Daily: s.daily, Monthly: s.monthly}
+}
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"connectorapi-go/pkg/config"
+)
+
+func TestMemoryLimiterRate(t *testing.T) {
+	l := NewMemoryLimiter()
+	limit := config.RateLimit{RequestsPerSecond: 2, Burst: 2}
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 2; i++ {
+		if !allowOne(l, "A", limit, now).Allowed {
+			t.Fatalf("request %d within burst refused", i)
+		}
+	}
+	res := allowOne(l, "A", limit, now)
+	if res.Allowed || res.Limit != "rate" || res.RetryAfter != 500*time.Millisecond {
+		t.Errorf("over burst: %+v", res)
+	}
+	if !allowOne(l, "B", limit, now).Allowed {
+		t.Error("other key refused")
+	}
+	if !allowOne(l, "A", limit, now.Add(500*time.Millisecond)).Allowed {
+		t.Error("refilled token refused")
+	}
+}
+
+func TestMemoryLimiterQuota(t *testing.T) {
+	l := NewMemoryLimiter()
+	limit := config.RateLimit{Daily: 2, Monthly: 3}
+	day1 := time.Date(2026, 10, 30, 22, 0, 0, 0, time.UTC)
+
+	allowOne(l, "A", limit, day1)
+	allowOne(l, "A", limit, day1)
+	res := allowOne(l, "A", limit, day1)
+	if res.Allowed || res.Limit != "daily" || res.RetryAfter != 2*time.Hour {
+		t.Errorf("over daily quota: %+v", res)
+	}
+
+	// The next day resets the daily quota but not the monthly one.
+	day2 := day1.Add(3 * time.Hour)
+	if res := allowOne(l, "A", limit, day2); !res.Allowed || res.Daily != 1 || res.Monthly != 3 {
+		t.Errorf("next day: %+v", res)
+	}
+	if res := allowOne(l, "A", limit, day2); res.Allowed || res.Limit != "monthly" || res.RetryAfter != 23*time.Hour {
+		t.Errorf("over monthly quota: %+v", res)
+	}
+
+	// So does the next month.
+	if res := allowOne(l, "A", limit, day2.Add(23*time.Hour)); !res.Allowed || res.Monthly != 1 {
+		t.Errorf("next month: %+v", res)
+	}
+}
+
+// allowOne checks a request against a single limit.
+func allowOne(l *MemoryLimiter, key string, limit config.RateLimit, now time.Time) LimitResult {
+	return l.Allow([]LimitCheck{{Key: key, Limit: limit}}, now)[0]
+}
+
+func TestMemoryLimiterRefusalTakesNothing(t *testing.T) {
+	l := NewMemoryLimiter()
+	route := LimitCheck{Key: "A POST:/Api/x", Limit: config.RateLimit{RequestsPerSecond: 1, Daily: 10}}
+	client := LimitCheck{Key: "A", Limit: config.RateLimit{Daily: 1}}
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	if res := l.Allow([]LimitCheck{route, client}, now); !res[0].Allowed || !res[1].Allowed {
+		t.Fatalf("first request: %+v", res)
+	}
+	// The client quota refuses the next request; the route keeps its counts.
+	res := l.Allow([]LimitCheck{route, client}, now.Add(time.Second))
+	if res[0].Allowed || res[0].Limit != "" || res[1].Limit != "daily" {
+		t.Errorf("over the client quota: %+v", res)
+	}
+	if res := allowOne(l, route.Key, route.Limit, now.Add(time.Second)); !res.Allowed || res.Daily != 2 {
+		t.Errorf("route after the refusal: %+v", res)
+	}
+}
@@ -57,8 +57,10 @@ func PadFloatWithZero(n float64, length int, decimalDigit int) string {
 // 	return f
 // }
 
-// BuildFixedLengthHeader constructs the fixed-length header.
-func BuildFixedLengthHeader(routeSystem, routeService, routeFormat, requestID string,  routeRequestLength string) string {
+// BuildFixedLengthHeader constructs the fixed-length header for body. The
+// request length field is the size of body on the wire once codec has encoded
+// it: one byte per character for the single-byte codecs, more for utf8.
+func BuildFixedLengthHeader(routeSystem, routeService, routeFormat, requestID string, body string, codec Codec) string {
 	now := time.Now()
 	requestDate := now.Format("20060102")
 	requestTime := now.Format("150405")
@@ -66,19 +68,21 @@ func BuildFixedLengthHeader(routeSystem, routeService, routeFormat, requestID st
 	responseCode := PadOrTruncate("", 6)
 	responseMessage := PadOrTruncate("", 50)
 
-	// const fixedHeaderLength = 10 + 15 + 3 + 20 + 8 + 6 + 5 + 6 + 50 // 123 characters
-	// totalMessageLength := len(fixedLengthData)
-	// requestLengthStr := fmt.Sprintf("%05d", totalMessageLength)
-
 	header := PadOrTruncate(routeSystem, 10) +
 		PadOrTruncate(routeService, 15) +
 		PadOrTruncate(routeFormat, 3) +
 		PadOrTruncate(requestID, 20) +
 		PadOrTruncate(requestDate, 8) +
 		PadOrTruncate(requestTime, 6) +
-		PadOrTruncate(routeRequestLength, 5) +
+		RequestLength(body, codec) +
 		responseCode +
 		responseMessage
 
 	return header
 }
+
+// RequestLength formats the header request length field for body as codec
+// encodes it.
+func RequestLength(body string, codec Codec) string {
+	return fmt.Sprintf("%05d", codec.EncodedLen(body))
+}
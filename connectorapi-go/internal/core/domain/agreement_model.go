@@ -1,12 +1,7 @@
 package domain
 
 import (
-	"time"
-
-	appError "connectorapi-go/pkg/error"
 	"connectorapi-go/internal/adapter/utils"
-
-	"github.com/gin-gonic/gin"
 )
 
 
@@ -22,18 +17,7 @@ type UpdateStatusResponse struct {
 	Agreement   string `json:"Agreement"   validate:"max=12"`
 }
 
-type UpdateStatusResult struct {
-	Response       *UpdateStatusResponse
-    AppError       *appError.AppError
-    GinCtx         *gin.Context
-    Timestamp      time.Time
-    ReqBody        interface{}
-    RespBody       interface{}
-    DomainError    *appError.AppError
-    ServiceName    string
-    UserToken      string
-    LogLine1       string
-}
+type UpdateStatusResult = Result[UpdateStatusResponse]
 
 // ---------- API AgreeMentBilling ---------
 type AgreeMentBillingRequest struct {
@@ -60,16 +44,4 @@ type AgreeMentBillingResponse struct {
 	PaymentHistory                 string              `json:"PaymentHistory"`
 }
 
-type AgreeMentBillingResult struct {
-	Response       *AgreeMentBillingResponse
-    AppError       *appError.AppError
-    GinCtx         *gin.Context
-    Timestamp      time.Time
-    ReqBody        interface{}
-    RespBody       interface{}
-    DomainError    *appError.AppError
-    ServiceName    string
-    UserToken      string
-	UserRef        string
-    LogLine1       string
-}
\ No newline at end of file
+type AgreeMentBillingResult = Result[AgreeMentBillingResponse]
\ No newline at end of file
@@ -1,12 +1,7 @@
 package domain
 
 import (
-	"time"
-
-	appError "connectorapi-go/pkg/error"
 	"connectorapi-go/internal/adapter/utils"
-
-	"github.com/gin-gonic/gin"
 )
 
 // ---------- API GetApplicationNo ---------
@@ -32,18 +27,7 @@ type GetApplicationNoResponse struct {
 	ResultDescription string `json:"ResultDescription" validate:"max=50"`
 }
 
-type GetApplicationNoResult struct {
-	Response       *GetApplicationNoResponse
-    AppError       *appError.AppError
-    GinCtx         *gin.Context
-    Timestamp      time.Time
-    ReqBody        interface{}
-    RespBody       interface{}
-    DomainError    *appError.AppError
-    ServiceName    string
-	UserRef        string
-    LogLine1       string
-}
+type GetApplicationNoResult = Result[GetApplicationNoResponse]
 
 // ---------- API SubmitCardApplication ---------
 type SubmitCardApplicationRequest struct {
@@ -89,15 +73,4 @@ type SubmitCardListRs struct {
 	PINNumber    string              `json:"PINNumber"    validate:"max=12"`
 }
 
-type SubmitCardApplicationResult struct {
-	Response       *SubmitCardApplicationResponse
-    AppError       *appError.AppError
-    GinCtx         *gin.Context
-    Timestamp      time.Time
-    ReqBody        interface{}
-    RespBody       interface{}
-    DomainError    *appError.AppError
-    ServiceName    string
-	UserRef        string
-    LogLine1       string
-}
+type SubmitCardApplicationResult = Result[SubmitCardApplicationResponse]
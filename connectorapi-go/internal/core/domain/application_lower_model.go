@@ -1,14 +1,5 @@
 package domain
 
-import (
-	"time"
-
-	appError "connectorapi-go/pkg/error"
-	// "connectorapi-go/internal/adapter/utils"
-
-	"github.com/gin-gonic/gin"
-)
-
 
 // ---------- API SubmitLoanApplication ---------
 type SubmitLoanApplicationRequest struct {
@@ -133,14 +124,8 @@ type SubmitLoanApplicationRequest struct {
 	AccountHolderName         string  `json:"accountholdername"             validate:"max=30"`
 }
 
-type SubmitLoanApplicationResult struct {
-    AppError       *appError.AppError
-    GinCtx         *gin.Context
-    Timestamp      time.Time
-    ReqBody        interface{}
-    RespBody       interface{}
-    DomainError    *appError.AppError
-    ServiceName    string
-	UserRef        string
-    LogLine1       string
-}
\ No newline at end of file
+// SubmitLoanApplicationResponse is empty: the handler answers 200 without a
+// body once System-I has taken the application.
+type SubmitLoanApplicationResponse struct{}
+
+type SubmitLoanApplicationResult = Result[SubmitLoanApplicationResponse]
@@ -1,12 +1,7 @@
 package domain
 
 import (
-	"time"
-
-	appError "connectorapi-go/pkg/error"
 	"connectorapi-go/internal/adapter/utils"
-
-	"github.com/gin-gonic/gin"
 )
 
 
@@ -68,19 +63,7 @@ type CollectionDetailAgreement struct {
 	TotalCurrentPerSUESeqNo 	utils.DecimalString `json:"TotalCurrentPerSUESeqNo"`
 }
 
-type CollectionDetailResult struct {
-	Response       *CollectionDetailResponse
-    AppError       *appError.AppError
-    GinCtx         *gin.Context
-    Timestamp      time.Time
-    ReqBody        interface{}
-    RespBody       interface{}
-    DomainError    *appError.AppError
-    ServiceName    string
-	UserToken      string
-	UserRef        string
-    LogLine1       string
-}
+type CollectionDetailResult = Result[CollectionDetailResponse]
 
 // ---------- API CollectionLog ---------
 type CollectionLogRequest struct {
@@ -101,14 +84,4 @@ type CollectionLogResponse struct {
 	AgreementNo 		string  `json:"AgreementNo"`	
 }
 
-type CollectionLogResult struct {
-	Response       *CollectionLogResponse
-    AppError       *appError.AppError
-    GinCtx         *gin.Context
-    Timestamp      time.Time
-    ReqBody        interface{}
-    RespBody       interface{}
-    DomainError    *appError.AppError
-    ServiceName    string
-    LogLine1       string
-}
+type CollectionLogResult = Result[CollectionLogResponse]
@@ -1,12 +1,7 @@
 package domain
 
 import (
-	"time"
-
-	appError "connectorapi-go/pkg/error"
 	"connectorapi-go/internal/adapter/utils"
-
-	"github.com/gin-gonic/gin"
 )
 
 // ---------- API GetCustomerInfo ---------
@@ -123,19 +118,7 @@ type GetCustomerInfoResponse003 struct {
 	HouseRegistrationCode         int 	                          `json:"HouseRegistrationCode"`
 }
 
-type GetCustomerInfoResult struct {
-	Response       interface{}
-    AppError       *appError.AppError
-    GinCtx         *gin.Context
-    Timestamp      time.Time
-    ReqBody        interface{}
-    RespBody       interface{}
-    DomainError    *appError.AppError
-    ServiceName    string
-	UserToken	   string
-	UserRef        string
-    LogLine1       string
-}
+type GetCustomerInfoResult = Result[any]
 
 // ---------- API CheckApplyCondition ---------
 type CheckApplyConditionRequest struct {
@@ -168,18 +151,7 @@ type CheckApplyConditionResponse struct {
 	ReasonDescription     string 	                      `json:"ReasonDescription"`
 }
 
-type CheckApplyConditionResult struct {
-	Response       *CheckApplyConditionResponse
-    AppError       *appError.AppError
-    GinCtx         *gin.Context
-    Timestamp      time.Time
-    ReqBody        interface{}
-    RespBody       interface{}
-    DomainError    *appError.AppError
-    ServiceName    string
-	UserRef        string
-    LogLine1       string
-}
+type CheckApplyConditionResult = Result[CheckApplyConditionResponse]
 
 // ---------- API CheckApplyCondition2ndCard ---------
 type CheckApplyCondition2ndCardRequest struct {
@@ -210,15 +182,4 @@ type CheckApply2ndCardRsOBJ struct {
 	ReasonDescription           string 	                      `json:"ReasonDescription"`
 }
 
-type CheckApplyCondition2ndCardResult struct {
-	Response       *CheckApplyCondition2ndCardResponse
-    AppError       *appError.AppError
-    GinCtx         *gin.Context
-    Timestamp      time.Time
-    ReqBody        interface{}
-    RespBody       interface{}
-    DomainError    *appError.AppError
-    ServiceName    string
-	UserRef        string
-    LogLine1       string
-}
\ No newline at end of file
+type CheckApplyCondition2ndCardResult = Result[CheckApplyCondition2ndCardResponse]
\ No newline at end of file
@@ -1,12 +1,7 @@
 package domain
 
 import (
-	"time"
-
-	appError "connectorapi-go/pkg/error"
 	// "connectorapi-go/internal/adapter/utils"
-
-	"github.com/gin-gonic/gin"
 )
 
 // ---------- API UpdateConsent ---------
@@ -37,15 +32,4 @@ type UpdateConsentResponse struct {
 	Status                      string	`json:"Status"` 
 }
 
-type UpdateConsentResult struct {
-	Response       *UpdateConsentResponse
-    AppError       *appError.AppError
-    GinCtx         *gin.Context
-    Timestamp      time.Time
-    ReqBody        interface{}
-    RespBody       interface{}
-    DomainError    *appError.AppError
-    ServiceName    string
-	UserRef        string
-    LogLine1       string
-}
\ No newline at end of file
+type UpdateConsentResult = Result[UpdateConsentResponse]
\ No newline at end of file
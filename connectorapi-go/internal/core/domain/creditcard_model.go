@@ -1,12 +1,6 @@
 package domain
 
-import (
-	"time"
-
-	appError "connectorapi-go/pkg/error"
-
-	"github.com/gin-gonic/gin"
-) 
+ 
 
 // ---------- API GetCardSales ---------
 type GetCardSalesRequest struct {
@@ -52,18 +46,7 @@ type GetCardSalesResponse struct {
 	CACardlessSaleReversalAmount        string	                      `json:"CACardlessSaleReversalAmount"` 
 }
 
-type GetCardSalesResult struct {
-	Response       *GetCardSalesResponse
-    AppError       *appError.AppError
-    GinCtx         *gin.Context
-    Timestamp      time.Time
-    ReqBody        interface{}
-    RespBody       interface{}
-    DomainError    *appError.AppError
-    ServiceName    string
-	UserRef        string
-    LogLine1       string
-}
+type GetCardSalesResult = Result[GetCardSalesResponse]
 
 // ---------- API GetBigCardInfo ---------
 type GetBigCardInfoRequest struct {
@@ -87,18 +70,7 @@ type GetBigCardInfoResponse struct {
 	DataEncrypt                      string	                      `json:"DataEncrypt"` 
 }
 
-type GetBigCardInfoResult struct {
-	Response       *GetBigCardInfoResponse
-    AppError       *appError.AppError
-    GinCtx         *gin.Context
-    Timestamp      time.Time
-    ReqBody        interface{}
-    RespBody       interface{}
-    DomainError    *appError.AppError
-    ServiceName    string
-	UserToken      string
-    LogLine1       string
-}
+type GetBigCardInfoResult = Result[GetBigCardInfoResponse]
 
 // ---------- API GetCardDelinquent ---------
 type GetCardDelinquentRequest struct {
@@ -111,15 +83,4 @@ type GetCardDelinquentResponse struct {
 	DelinquentCountAll        string `json:"DelinquentCountAll"`
 }
 
-type GetCardDelinquentResult struct {
-	Response       *GetCardDelinquentResponse
-    AppError       *appError.AppError
-    GinCtx         *gin.Context
-    Timestamp      time.Time
-    ReqBody        interface{}
-    RespBody       interface{}
-    DomainError    *appError.AppError
-    ServiceName    string
-	UserRef         string
-    LogLine1       string
-}
\ No newline at end of file
+type GetCardDelinquentResult = Result[GetCardDelinquentResponse]
\ No newline at end of file
@@ -1,12 +1,6 @@
 package domain
 
-import (
-	"time"
-
-	appError "connectorapi-go/pkg/error"
-
-	"github.com/gin-gonic/gin"
-) 
+ 
 
 // ---------- API GetCustomerInfoMobileNo ---------
 type GetCustomerInfoMobileNoRequest struct {
@@ -22,15 +16,4 @@ type GetCustomerInfoMobileNoResponse struct {
 	Fraudflag                      string 	                      `json:"fraudflag"` 
 }
 
-type GetCustomerInfoMobileNoResult struct {
-	Response       *GetCustomerInfoMobileNoResponse
-    AppError       *appError.AppError
-    GinCtx         *gin.Context
-    Timestamp      time.Time
-    ReqBody        interface{}
-    RespBody       interface{}
-    DomainError    *appError.AppError
-    ServiceName    string
-	UserRef        string
-    LogLine1       string
-}
\ No newline at end of file
+type GetCustomerInfoMobileNoResult = Result[GetCustomerInfoMobileNoResponse]
\ No newline at end of file
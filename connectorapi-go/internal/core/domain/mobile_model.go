@@ -1,12 +1,7 @@
 package domain
 
 import (
-	"time"
-
-	appError "connectorapi-go/pkg/error"
     "connectorapi-go/internal/adapter/utils"
-
-	"github.com/gin-gonic/gin"
 )
 
 // ---------- API DashboardSummary ---------
@@ -61,19 +56,7 @@ type DBDetailTermsListRq struct {
 	TermsAcceptStatus string `json:"TermsAcceptStatus" validate:"max=2"`
 }
 
-type DashboardSummaryResult struct {
-	Response       *DashboardSummaryResponse
-    AppError       *appError.AppError
-    GinCtx         *gin.Context
-    Timestamp      time.Time
-    ReqBody        interface{}
-    RespBody       interface{}
-    DomainError    *appError.AppError
-    ServiceName    string
-    UserToken      string
-	UserRef        string
-    LogLine1       string
-}
+type DashboardSummaryResult = Result[DashboardSummaryResponse]
 
 // ---------- API DashboardDetail ---------
 type DashboardDetailRequest struct {
@@ -116,19 +99,7 @@ type DBDetailListRs struct {
     ApplicationDate              int                  `json:"ApplicationDate"    validate:"lte=99999999"`
 }
 
-type DashboardDetailResult struct {
-	Response       *DashboardDetailResponse
-    AppError       *appError.AppError
-    GinCtx         *gin.Context
-    Timestamp      time.Time
-    ReqBody        interface{}
-    RespBody       interface{}
-    DomainError    *appError.AppError
-    ServiceName    string
-    UserToken      string
-	UserRef        string
-    LogLine1       string
-}
+type DashboardDetailResult = Result[DashboardDetailResponse]
 
 // ---------- API MobileFullPan ---------
 type MobileFullPanRequest struct {
@@ -167,15 +138,4 @@ type MobileCardListRs struct {
 	DigitalCardFlag  string `json:"DigitalCardFlag" validate:"max=1"`
 }
 
-type MobileFullPanResult struct {
-	Response       *MobileFullPanResponse
-    AppError       *appError.AppError
-    GinCtx         *gin.Context
-    Timestamp      time.Time
-    ReqBody        interface{}
-    RespBody       interface{}
-    DomainError    *appError.AppError
-    ServiceName    string
-	UserRef        string
-    LogLine1       string
-}
+type MobileFullPanResult = Result[MobileFullPanResponse]
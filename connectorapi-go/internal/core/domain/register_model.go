@@ -1,12 +1,6 @@
 package domain
 
-import (
-	"time"
 
-	appError "connectorapi-go/pkg/error"
-
-	"github.com/gin-gonic/gin"
-)
 
 // ---------- API CheckRegister ---------
 type CheckRegisterRequest struct {
@@ -28,18 +22,7 @@ type CheckRegisterResponse struct {
 	AgreementRegisterFlag         string 	                      `json:"AgreementRegisterFlag"`
 }
 
-type CheckRegisterResult struct {
-	Response       *CheckRegisterResponse
-    AppError       *appError.AppError
-    GinCtx         *gin.Context
-    Timestamp      time.Time
-    ReqBody        interface{}
-    RespBody       interface{}
-    DomainError    *appError.AppError
-    ServiceName    string
-	UserRef        string
-    LogLine1       string
-}
+type CheckRegisterResult = Result[CheckRegisterResponse]
 
 // ---------- API CheckRegisterSocial ---------
 type CheckRegisterSocialRequest struct {
@@ -51,15 +34,4 @@ type CheckRegisterSocialResponse struct {
 	MobileNo                      string 	                      `json:"MobileNo"`
 }
 
-type CheckRegisterSocialResult struct {
-	Response       *CheckRegisterSocialResponse
-    AppError       *appError.AppError
-    GinCtx         *gin.Context
-    Timestamp      time.Time
-    ReqBody        interface{}
-    RespBody       interface{}
-    DomainError    *appError.AppError
-    ServiceName    string
-	UserRef        string
-    LogLine1       string
-}
\ No newline at end of file
+type CheckRegisterSocialResult = Result[CheckRegisterSocialResponse]
\ No newline at end of file
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"time"
+
+	appError "connectorapi-go/pkg/error"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Result is what a System-I service call hands back to its handler. AppError
+// is answered directly; DomainError and LogLine1 go through the ELK log first.
+type Result[T any] struct {
+	Response    *T
+	AppError    *appError.AppError
+	GinCtx      *gin.Context
+	Timestamp   time.Time
+	ReqBody     interface{}
+	RespBody    interface{}
+	DomainError *appError.AppError
+	ServiceName string
+	UserToken   string
+	UserRef     string
+	LogLine1    string
+}
+
+// ConfiguredResult is the result of a route served from configuration alone;
+// the response is the record read with the route's response layout.
+type ConfiguredResult = Result[map[string]interface{}]
@@ -1,12 +1,6 @@
 package domain
 
-import (
-	"time"
 
-	appError "connectorapi-go/pkg/error"
-
-	"github.com/gin-gonic/gin"
-)
 
 // ---------- API MyCard ---------
 type MyCardRequest struct {
@@ -57,16 +51,4 @@ type MyCardListAll struct {
 	CashingLimit                  int 	    `json:"CashingLimit"`
 }
 
-type MyCardResult struct {
-	Response       interface{}
-    AppError       *appError.AppError
-    GinCtx         *gin.Context
-    Timestamp      time.Time
-    ReqBody        interface{}
-    RespBody       interface{}
-    DomainError    *appError.AppError
-    ServiceName    string
-	UserToken      string
-	UserRef        string
-    LogLine1       string
-}
\ No newline at end of file
+type MyCardResult = Result[any]
\ No newline at end of file
@@ -1,12 +1,7 @@
 package domain
 
 import (
-	"time"
-
-	appError "connectorapi-go/pkg/error"
 	"connectorapi-go/internal/adapter/utils"
-
-	"github.com/gin-gonic/gin"
 )
 
 // ---------- API GetRedbookInfo ---------
@@ -40,18 +35,7 @@ type GetRedbookInfoResponse struct {
 	NewPrice                  utils.DecimalString	          `json:"NewPrice"`
 }
 
-type GetRedbookInfoResult struct {
-	Response       *GetRedbookInfoResponse
-    AppError       *appError.AppError
-    GinCtx         *gin.Context
-    Timestamp      time.Time
-    ReqBody        interface{}
-    RespBody       interface{}
-    DomainError    *appError.AppError
-    ServiceName    string
-	UserRef        string
-    LogLine1       string
-}
+type GetRedbookInfoResult = Result[GetRedbookInfoResponse]
 
 // ---------- API GetDealerCommission ---------
 type GetDealerCommissionRequest struct {
@@ -76,18 +60,7 @@ type GetDealerCommissionResponse struct {
 	NetTotalCommission        utils.DecimalString	          `json:"NetTotalCommission"` 
 }
 
-type GetDealerCommissionResult struct {
-	Response       *GetDealerCommissionResponse
-    AppError       *appError.AppError
-    GinCtx         *gin.Context
-    Timestamp      time.Time
-    ReqBody        interface{}
-    RespBody       interface{}
-    DomainError    *appError.AppError
-    ServiceName    string
-	UserRef        string
-    LogLine1       string
-}
+type GetDealerCommissionResult = Result[GetDealerCommissionResponse]
 
 // ---------- API GetDealerAgreement ---------
 type GetDealerAgreementRequest struct {
@@ -115,15 +88,4 @@ type AgreementListobj struct {
 	Status                    string 	                      `json:"Status"`
 }
 
-type GetDealerAgreementResult struct {
-	Response       *GetDealerAgreementResponse
-    AppError       *appError.AppError
-    GinCtx         *gin.Context
-    Timestamp      time.Time
-    ReqBody        interface{}
-    RespBody       interface{}
-    DomainError    *appError.AppError
-    ServiceName    string
-	UserRef        string
-    LogLine1       string
-}
\ No newline at end of file
+type GetDealerAgreementResult = Result[GetDealerAgreementResponse]
\ No newline at end of file
@@ -0,0 +1,369 @@
+package service
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"connectorapi-go/internal/adapter/utils"
+	"connectorapi-go/pkg/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// endpoint is what a hand-written endpoint needs from its route: the
+// destination port key it is sent with and the route fields its request
+// header is built from.
+type endpoint struct {
+	service string
+	fields  []string
+}
+
+var routeHeader = []string{"System", "Service", "Format"}
+
+// endpoints lists every hand-written System-I endpoint by route key.
+var endpoints = map[string]endpoint{
+	"POST:/Api/Collection/CollectionDetail":           {"CollectionDetail", routeHeader},
+	"POST:/Api/Collection/CollectionLog":              {"CollectionLog", routeHeader},
+	"POST:/Api/Agreement/UpdateStatus":                {"UpdateAgreementStatus", routeHeader},
+	"POST:/Api/Agreement/GetBilling":                  {"AgreeMentBilling", routeHeader},
+	"POST:/Api/CreditCard/GetCardSales":               {"GetCardSales", routeHeader},
+	"POST:/Api/CreditCard/GetBigCardInfo":             {"GetBigCardInfo", routeHeader},
+	"POST:/Api/CreditCard/GetCardDelinquent":          {"GetCardDelinquent", routeHeader},
+	"POST:/Api/Common/GetCustomerInfo":                {"GetCustomerInfo", []string{"Service"}},
+	"POST:/Api/Common/CheckApplyCondition/ApplyCard":  {"CheckApplyCondition", routeHeader},
+	"POST:/Api/Common/CheckApplyCondition/SecondCard": {"CheckApplyCondition2ndCard", routeHeader},
+	"POST:/Api/SelfService/MyCard":                    {"MyCard", []string{"System", "Format"}},
+	"POST:/Api/Register/CheckRegister":                {"CheckRegister", routeHeader},
+	"POST:/Api/Register/CheckRegisterSocial":          {"CheckRegisterSocial", routeHeader},
+	"POST:/Api/customer/getcustomerinfo/mobileno":     {"GetCustomerInfoMobileNo", routeHeader},
+	"POST:/Api/Consent/UpdateConsent":                 {"UpdateConsent", []string{"System", "Service"}},
+	"POST:/Api/uhp/GetRedbookInfo":                    {"GetRedbookInfo", routeHeader},
+	"POST:/Api/uhp/GetDealerCommission":               {"GetDealerCommission", routeHeader},
+	"POST:/Api/uhp/GetDealerAgreement":                {"GetDealerAgreement", routeHeader},
+	"POST:/Api/Mobile/DashboardSummary":               {"DashboardSummary", []string{"SystemV1", "SystemV2", "Service", "FormatV1", "FormatV2"}},
+	"POST:/Api/Mobile/DashboardDetail":                {"DashboardDetail", []string{"SystemV1", "SystemV2", "Service", "FormatV1", "FormatV2"}},
+	"POST:/Api/Mobile/MobileFullPAN":                  {"MobileFullPan", routeHeader},
+	"POST:/Api/Application/GetApplicationNo":          {"GetApplicationNo", routeHeader},
+	"POST:/Api/Application/SubmitCardApplication":     {"SubmitCardApplication", routeHeader},
+	"POST:/Api/application/submitloanapplication":     {"SubmitLoanApplication", routeHeader},
+}
+
+func routeField(r config.Route, name string) string {
+	switch name {
+	case "System":
+		return r.System
+	case "SystemV1":
+		return r.SystemV1
+	case "SystemV2":
+		return r.SystemV2
+	case "Service":
+		return r.Service
+	case "Format":
+		return r.Format
+	case "FormatV1":
+		return r.FormatV1
+	case "FormatV2":
+		return r.FormatV2
+	}
+	return ""
+}
+
+// Finding is one problem found by a configuration check. A fatal finding
+// makes requests fail; the others are worth a look.
+type Finding struct {
+	Fatal   bool
+	Subject string // e.g. "route POST:/Api/Collection/CollectionLog"
+	Message string
+}
+
+func (f Finding) String() string {
+	level := "WARN "
+	if f.Fatal {
+		level = "FATAL"
+	}
+	return level + " " + f.Subject + ": " + f.Message
+}
+
+// Report is the outcome of a configuration check, sorted fatal first.
+type Report []Finding
+
+func (r *Report) fatal(subject, format string, args ...interface{}) {
+	*r = append(*r, Finding{Fatal: true, Subject: subject, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r *Report) warn(subject, format string, args ...interface{}) {
+	*r = append(*r, Finding{Subject: subject, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r Report) sorted() Report {
+	sort.SliceStable(r, func(i, j int) bool {
+		if r[i].Fatal != r[j].Fatal {
+			return r[i].Fatal
+		}
+		if r[i].Subject != r[j].Subject {
+			return r[i].Subject < r[j].Subject
+		}
+		return r[i].Message < r[j].Message
+	})
+	return r
+}
+
+// Fatal reports whether any finding is fatal.
+func (r Report) Fatal() bool {
+	for _, f := range r {
+		if f.Fatal {
+			return true
+		}
+	}
+	return false
+}
+
+// Err returns the fatal findings as one error, or nil.
+func (r Report) Err() error {
+	var msgs []string
+	for _, f := range r {
+		if f.Fatal {
+			msgs = append(msgs, f.Subject+": "+f.Message)
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s", strings.Join(msgs, "; "))
+}
+
+// String renders the report one finding per line.
+func (r Report) String() string {
+	var sb strings.Builder
+	for _, f := range r {
+		sb.WriteString(f.String())
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// servedKeys returns the route keys of the /Api endpoints in served.
+func servedKeys(served gin.RoutesInfo) map[string]bool {
+	keys := make(map[string]bool, len(served))
+	for _, r := range served {
+		if strings.HasPrefix(r.Path, "/Api/") {
+			keys[r.Method+":"+r.Path] = true
+		}
+	}
+	return keys
+}
+
+// matchesServed reports whether r applies to at least one served endpoint.
+func matchesServed(r config.Rule, handled map[string]bool) bool {
+	if handled[r.Pattern] {
+		return true
+	}
+	for key := range handled {
+		method, path, _ := strings.Cut(key, ":")
+		if r.Matches(method, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckRouting cross-references destinations and routes with each other and
+// with the endpoints the router serves (served, from gin.Engine.Routes).
+func CheckRouting(dr *config.DestinationsAndRoutes, served gin.RoutesInfo) Report {
+	var report Report
+	handled := servedKeys(served)
+
+	for name, dest := range dr.Destinations {
+		subject := "destination " + name
+		if dest.Type != "tcp" {
+			report.warn(subject, "type %q is not used by any endpoint", dest.Type)
+			continue
+		}
+		if dest.IP == "" {
+			report.fatal(subject, "no ip")
+		}
+		if _, err := utils.CodecByName(dest.Codec); err != nil {
+			report.fatal(subject, "%v", err)
+		}
+		if !knownStrategy(dest.LoadBalancing) {
+			report.fatal(subject, "unknown loadBalancing %q", dest.LoadBalancing)
+		}
+		for service, strategy := range dest.ServiceLoadBalancing {
+			if !knownStrategy(strategy) {
+				report.fatal(subject, "unknown loadBalancing %q for service %s", strategy, service)
+			}
+		}
+		for service, ports := range dest.Ports {
+			if len(ports) == 0 {
+				report.fatal(subject, "service %s has no ports", service)
+			}
+			for _, port := range ports {
+				if n, err := strconv.Atoi(port); err != nil || n <= 0 || n > 65535 {
+					report.fatal(subject, "service %s: invalid port %q", service, port)
+				}
+			}
+		}
+	}
+
+	systemI, hasSystemI := dr.Destinations[systemIDestination]
+	if !hasSystemI || systemI.Type != "tcp" {
+		report.fatal("destination "+systemIDestination, "missing, or not a tcp destination")
+	}
+	usedPorts := map[string]bool{}
+
+	for key, route := range dr.Routes {
+		subject := "route " + key
+		method, path, ok := strings.Cut(key, ":")
+		if !ok || method == "" || !strings.HasPrefix(path, "/") {
+			report.fatal(subject, "route keys look like POST:/Api/Group/Name")
+			continue
+		}
+
+		var service string
+		var fields []string
+		switch ep, known := endpoints[key]; {
+		case route.Configured():
+			service, fields = route.Name, routeHeader
+			if !handled[key] {
+				report.warn(subject, "configured route is served after the next restart")
+			}
+		case known:
+			service, fields = ep.service, ep.fields
+			if !handled[key] {
+				report.warn(subject, "no handler serves this route")
+			}
+		case handled[key]:
+			report.warn(subject, "served by a handler this check does not know; ports and header not checked")
+		default:
+			report.warn(subject, "no handler serves this route")
+		}
+
+		for _, field := range fields {
+			if routeField(route, field) == "" {
+				report.fatal(subject, "%s is empty", field)
+			}
+		}
+		if service != "" && hasSystemI {
+			usedPorts[service] = true
+			if len(systemI.Ports[service]) == 0 {
+				report.fatal(subject, "destination %s has no ports for service %s", systemIDestination, service)
+			}
+		}
+		if route.Timeout != "" {
+			if d, err := time.ParseDuration(route.Timeout); err != nil || d <= 0 {
+				report.fatal(subject, "invalid Timeout %q", route.Timeout)
+			}
+		}
+		if route.Retries < 0 {
+			report.fatal(subject, "negative Retries")
+		}
+		switch route.EncodeFallback {
+		case "", utils.FallbackReject, utils.FallbackTransliterate, utils.FallbackReplace:
+		default:
+			report.fatal(subject, "unknown EncodeFallback %q", route.EncodeFallback)
+		}
+	}
+
+	for key := range handled {
+		if _, ok := dr.Routes[key]; !ok {
+			report.fatal("route "+key, "served by a handler but missing from the routes file")
+		}
+	}
+	if hasSystemI {
+		for service := range systemI.Ports {
+			if !usedPorts[service] {
+				report.warn("destination "+systemIDestination, "ports for service %s are not used by any route", service)
+			}
+		}
+	}
+	if err := CheckConfiguredRoutes(dr.Routes); err != nil {
+		report.fatal("layouts", "%v", err)
+	}
+	for _, m := range CheckRequestLengths(dr.Routes) {
+		report.warn("route "+m.Route, "RequestLength %s does not match the body (%s); the derived length is sent", m.Configured, m.Actual)
+	}
+	return report.sorted()
+}
+
+func knownStrategy(s string) bool {
+	switch s {
+	case "", utils.StrategyRandom, utils.StrategyRoundRobin, utils.StrategyLeastOutstanding, utils.StrategyWeighted, utils.StrategySticky:
+		return true
+	}
+	return false
+}
+
+// CheckAPIKeys checks the API keys and cross-references their permissions
+// with the endpoints the router serves.
+func CheckAPIKeys(apiKeys []config.APIKey, served gin.RoutesInfo) Report {
+	var report Report
+	handled := servedKeys(served)
+	owner := map[string]string{}
+	ids := map[string]string{}
+	checked := map[string]bool{}
+	for _, k := range apiKeys {
+		subject := "api key " + k.ClientName
+		if len(k.Key) == 0 && len(k.Hashes) == 0 {
+			report.warn(subject, "no keys")
+		}
+		for _, key := range k.Key {
+			if key == "" {
+				report.warn(subject, "empty key: requests without an Api-Key are accepted for its permissions")
+			}
+			if other, dup := owner[key]; dup {
+				report.fatal(subject, "key is also used by %s", other)
+			}
+			owner[key] = k.ClientName
+			report.warn(subject, "plaintext key %s; store it as a hash (connector-api keygen --stdin)", utils.PlainKeyID(key))
+		}
+		for _, h := range k.Hashes {
+			if other, dup := ids[h.ID]; dup {
+				report.fatal(subject, "key id %s is also used by %s", h.ID, other)
+			}
+			ids[h.ID] = k.ClientName
+			if salt, err := hex.DecodeString(h.Salt); err == nil && config.HashAPIKey(salt, "") == h.Hash {
+				report.warn(subject, "key %s is empty: requests without an Api-Key are accepted for its permissions", h.ID)
+			}
+			if h.ExpiresAt != nil && !h.ExpiresAt.After(time.Now()) {
+				report.warn(subject, "key %s expired at %s", h.ID, h.ExpiresAt.Format(time.RFC3339))
+			}
+		}
+		switch k.Status {
+		case "active", "inactive":
+		default:
+			report.warn(subject, "status %q is treated as inactive", k.Status)
+		}
+		rules := k.Rules
+		if rules == nil {
+			rules = k.ResolveRules(nil)
+		}
+		for _, r := range rules {
+			ruleSubject := subject
+			if r.Source != "client" {
+				// A group's rules are reported once, not for every client using it.
+				if checked[r.String()] {
+					continue
+				}
+				checked[r.String()] = true
+				ruleSubject = "permission " + r.Source
+			}
+			if !matchesServed(r, handled) {
+				kind := "permission"
+				if r.Deny {
+					kind = "deny rule"
+				}
+				report.warn(ruleSubject, "%s %s matches no endpoint", kind, r.Pattern)
+			}
+		}
+		for route := range k.RouteLimits {
+			if !handled[route] {
+				report.warn(subject, "rate limit for %s matches no endpoint", route)
+			}
+		}
+	}
+	return report.sorted()
+}
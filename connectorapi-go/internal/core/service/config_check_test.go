@@ -0,0 +1,164 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"connectorapi-go/internal/adapter/handler/api"
+	"connectorapi-go/pkg/config"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// servedEndpoints is the router's table with no configured routes: every
+// route a hand-written handler registers.
+func servedEndpoints() gin.RoutesInfo {
+	gin.SetMode(gin.TestMode)
+	log := zap.NewNop().Sugar()
+	routing := config.NewRouting(&config.DestinationsAndRoutes{})
+	return handler.SetupRouter(log, nil, nil,
+		handler.NewCollectionHandler(nil, log, nil, nil),
+		handler.NewAgreementHandler(nil, log, nil, nil),
+		handler.NewCreditCardHandler(nil, log, nil, nil),
+		handler.NewCommonHandler(nil, log, nil, nil),
+		handler.NewSelfServiceHandler(nil, log, nil, nil),
+		handler.NewRegisterHandler(nil, log, nil, nil),
+		handler.NewCustomerLowerHandler(nil, log, nil, nil),
+		handler.NewConsentHandler(nil, log, nil, nil),
+		handler.NewUhpHandler(nil, log, nil, nil),
+		handler.NewMobileHandler(nil, log, nil, nil),
+		handler.NewApplicationCapHandler(nil, log, nil, nil),
+		handler.NewApplicationLowerHandler(nil, log, nil, nil),
+		handler.NewConfiguredHandler(nil, routing, log, nil, nil),
+	).Routes()
+}
+
+// TestCheckListsCoverServedRoutes keeps endpoints, requestBodies and
+// variableLengthRoutes in step with the handlers: a route added to a handler
+// must be added to the checks, and a route removed from every handler must be
+// removed from them.
+func TestCheckListsCoverServedRoutes(t *testing.T) {
+	served := map[string]bool{}
+	for _, r := range servedEndpoints() {
+		if !strings.HasPrefix(r.Path, "/Api/") {
+			continue
+		}
+		key := r.Method + ":" + r.Path
+		served[key] = true
+		if _, ok := endpoints[key]; !ok {
+			t.Errorf("%s is served but missing from endpoints", key)
+		}
+		if _, ok := requestBodies[key]; !ok && !variableLengthRoutes[key] {
+			t.Errorf("%s is served but missing from requestBodies and variableLengthRoutes", key)
+		}
+	}
+	for key := range endpoints {
+		if !served[key] {
+			t.Errorf("endpoints lists %s, which no handler serves", key)
+		}
+	}
+	for key := range requestBodies {
+		if !served[key] {
+			t.Errorf("requestBodies lists %s, which no handler serves", key)
+		}
+	}
+	for key := range variableLengthRoutes {
+		if !served[key] {
+			t.Errorf("variableLengthRoutes lists %s, which no handler serves", key)
+		}
+	}
+}
+
+func TestShippedConfigPassesSelfCheck(t *testing.T) {
+	dr, err := config.LoadDestinationsAndRoutes("../../../configs/destinations_routes.json")
+	if err != nil {
+		t.Fatalf("loading routes: %v", err)
+	}
+	if err := CheckRouting(dr, servedEndpoints()).Err(); err != nil {
+		t.Error(err)
+	}
+	apiKeys, err := config.LoadAPIKeys("../../../configs/apikeys.json")
+	if err != nil {
+		t.Fatalf("loading api keys: %v", err)
+	}
+	if err := CheckAPIKeys(apiKeys, servedEndpoints()).Err(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCheckRoutingFindsMistakes(t *testing.T) {
+	dr, err := config.LoadDestinationsAndRoutes("../../../configs/destinations_routes.json")
+	if err != nil {
+		t.Fatalf("loading routes: %v", err)
+	}
+	systemI := dr.Destinations["systemI"]
+	delete(systemI.Ports, "CollectionLog")
+	systemI.Codec = "ebcdic"
+	dr.Destinations["systemI"] = systemI
+
+	route := dr.Routes["POST:/Api/Collection/CollectionDetail"]
+	route.System = ""
+	route.Timeout = "soon"
+	dr.Routes["POST:/Api/Collection/CollectionDetail"] = route
+	delete(dr.Routes, "POST:/Api/uhp/GetRedbookInfo")
+	dr.Routes["POST:/Api/Collection/CollectionDetial"] = config.Route{System: "AEON_WF", Service: "INQ_CUST_COSINF", Format: "001"}
+
+	got := CheckRouting(dr, servedEndpoints()).String()
+	for _, want := range []string{
+		"FATAL destination systemI: unknown codec",
+		"FATAL route POST:/Api/Collection/CollectionLog: destination systemI has no ports for service CollectionLog",
+		"FATAL route POST:/Api/Collection/CollectionDetail: System is empty",
+		`FATAL route POST:/Api/Collection/CollectionDetail: invalid Timeout "soon"`,
+		"FATAL route POST:/Api/uhp/GetRedbookInfo: served by a handler but missing from the routes file",
+		"WARN  route POST:/Api/Collection/CollectionDetial: no handler serves this route",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("report lacks %q:\n%s", want, got)
+		}
+	}
+	if !strings.HasPrefix(got, "FATAL") {
+		t.Errorf("fatal findings are not listed first:\n%s", got)
+	}
+}
+
+func TestCheckAPIKeys(t *testing.T) {
+	got := CheckAPIKeys([]config.APIKey{
+		{ClientName: "A", Key: []string{"K1"}, Status: "active", Permissions: []string{"POST:/Api/Collection/CollectionLog"}},
+		{ClientName: "B", Key: []string{"K1"}, Status: "enabled", Permissions: []string{"POST:/Api/Collection/Nothing"},
+			RouteLimits: map[string]config.RateLimit{"POST:/Api/Collection/Nothing": {Daily: 10}}},
+	}, servedEndpoints())
+	if !got.Fatal() {
+		t.Fatalf("duplicate key not fatal:\n%s", got)
+	}
+	for _, want := range []string{"key is also used by A", `status "enabled"`, "permission POST:/Api/Collection/Nothing matches no endpoint", "rate limit for POST:/Api/Collection/Nothing matches no endpoint"} {
+		if !strings.Contains(got.String(), want) {
+			t.Errorf("report lacks %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestCheckAPIKeysRules(t *testing.T) {
+	keys, err := config.ParseAPIKeys([]byte(`{
+		"groups": {"collection": {"permissions": ["POST:/Api/Collection/*", "POST:/Api/Nothing/*"]}},
+		"clients": [
+			{"clientName": "A", "key": ["K1"], "status": "active", "groups": ["collection"], "deny": ["GET:/Api/Collection/*"]},
+			{"clientName": "B", "key": ["K2"], "status": "active", "groups": ["collection"]}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := CheckAPIKeys(keys, servedEndpoints()).String()
+	if strings.Contains(got, "POST:/Api/Collection/*") {
+		t.Errorf("wildcard permission reported:\n%s", got)
+	}
+	for _, want := range []string{"api key A: deny rule GET:/Api/Collection/* matches no endpoint", "permission group:collection: permission POST:/Api/Nothing/* matches no endpoint"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("report lacks %q:\n%s", want, got)
+		}
+	}
+	if n := strings.Count(got, "POST:/Api/Nothing/*"); n != 1 {
+		t.Errorf("group rule reported %d times:\n%s", n, got)
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"fmt"
+
+	"connectorapi-go/internal/adapter/client"
+	"connectorapi-go/internal/core/domain"
+	"connectorapi-go/internal/core/service/format"
+	"connectorapi-go/pkg/config"
+	appError "connectorapi-go/pkg/error"
+	"connectorapi-go/pkg/layout"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// configuredService serves the routes that are described only in
+// destinations_routes.json (see config.Route.Configured).
+type configuredService struct {
+	systemI
+	config *config.Config
+}
+
+// NewConfiguredService creates a new instance of configuredService.
+func NewConfiguredService(
+	cfg *config.Config,
+	logger *zap.SugaredLogger,
+	tcpClient client.TCPSocketClient,
+	routing *config.Routing,
+) *configuredService {
+	return &configuredService{
+		systemI: systemI{
+			logger:    logger,
+			tcpClient: tcpClient,
+			routing:   routing,
+		},
+		config: cfg,
+	}
+}
+
+// CheckConfiguredRoutes reports every configured route whose layouts are not
+// registered, so a typo stops the gateway at startup.
+func CheckConfiguredRoutes(routes map[string]config.Route) error {
+	for key, route := range routes {
+		if !route.Configured() {
+			continue
+		}
+		for _, name := range []string{route.RequestLayout, route.ResponseLayout} {
+			if _, ok := format.Layout(name); !ok {
+				return fmt.Errorf("route %s: unknown layout %s", key, name)
+			}
+		}
+	}
+	return nil
+}
+
+// Inquire sends the request body of a configured route to System-I with the
+// route's request layout and returns the response read with its response
+// layout.
+func (s *configuredService) Inquire(c *gin.Context, route config.Route, req layout.Record) domain.ConfiguredResult {
+	userRef, _ := req[route.UserRefField].(string)
+	return execute(c, s.systemI, &req, inquiry[layout.Record, layout.Record]{
+		service:    route.Name,
+		balanceKey: userRef,
+		userRef:    userRef,
+		validate: func(req layout.Record) *appError.AppError {
+			if _, err := format.FormatRecord(route.RequestLayout, req); err != nil {
+				s.logger.Warnw("Request does not fit the configured layout", "layout", route.RequestLayout, "error", err)
+				return &appError.AppError{
+					ErrorCode:    appError.ErrRequiedParam.ErrorCode,
+					ErrorMessage: appError.ErrRequiedParam.ErrorMessage,
+					Err:          err,
+				}
+			}
+			return nil
+		},
+		render: func(req layout.Record) (string, error) {
+			return format.FormatRecord(route.RequestLayout, req)
+		},
+		parse: func(response string) (layout.Record, error) {
+			return format.ParseRecord(route.ResponseLayout, response)
+		},
+	})
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"connectorapi-go/internal/adapter/utils"
+	"connectorapi-go/pkg/config"
+	appError "connectorapi-go/pkg/error"
+	"connectorapi-go/pkg/layout"
+)
+
+func TestConfiguredInquire(t *testing.T) {
+	route := config.Route{
+		System: "SYS", Service: "ECHO", Format: "001",
+		Name:           "Echo",
+		RequestLayout:  "CollectionLogRequest",
+		ResponseLayout: "CollectionLogResponse",
+		UserRefField:   "AgreementNo",
+	}
+	tcp := &replyClient{reply: systemIResponse("", "", "3100000000001       1234567890123456")}
+	c := echoContext(true)
+	sys := echoSystem(tcp, c)
+	sys.routing.Current().Routes[utils.GetRouteKey(c)] = route
+	s := &configuredService{systemI: sys}
+
+	res := s.Inquire(c, route, layout.Record{"AgreementNo": "1234567890123456", "RemarkCode": "R01"})
+	if res.AppError != nil || res.DomainError != nil {
+		t.Fatalf("unexpected error %+v %+v", res.AppError, res.DomainError)
+	}
+	if res.UserRef != "1234567890123456" || res.ServiceName != "Echo" {
+		t.Fatalf("result not filled: %+v", res)
+	}
+	if n := len([]rune(tcp.payload)); n != 123+649 {
+		t.Errorf("payload length = %d, want %d", n, 123+649)
+	}
+	resp := *res.Response
+	if resp["IDCardNo"] != "3100000000001" || resp["AgreementNo"] != "1234567890123456" {
+		t.Fatalf("response = %v", resp)
+	}
+
+	tcp.payload = ""
+	res = s.Inquire(c, route, layout.Record{"AgreementNo": json.Number("1.5"), "RemarkCode": []interface{}{}})
+	if res.AppError == nil || res.AppError.ErrorCode != appError.ErrRequiedParam.ErrorCode {
+		t.Fatalf("AppError = %+v, want %s", res.AppError, appError.ErrRequiedParam.ErrorCode)
+	}
+	if strings.TrimSpace(tcp.payload) != "" {
+		t.Fatal("rejected request reached System I")
+	}
+}
@@ -1,17 +1,11 @@
 package service
 
 import (
-	"fmt"
-	"strings"
-	"time"
-
 	"connectorapi-go/internal/adapter/client"
-	"connectorapi-go/internal/adapter/utils"
 	"connectorapi-go/internal/core/domain"
 	"connectorapi-go/pkg/config"
 	"connectorapi-go/internal/core/service/format"
 	appError "connectorapi-go/pkg/error"
-	elkLog "connectorapi-go/internal/adapter/client/elk"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -22,11 +16,8 @@ type consentTCPSocketClient = client.TCPSocketClient
 
 // consentService implements the business logic for customer-related features
 type consentService struct {
-	config       *config.Config
-	logger       *zap.SugaredLogger
-	tcpClient    client.TCPSocketClient
-	routes       map[string]config.Route
-	destinations map[string]config.Destination
+	systemI
+	config *config.Config
 }
 
 // NewConsentService creates a new instance of consentService.
@@ -34,15 +25,15 @@ func NewConsentService(
 	cfg *config.Config,
 	logger *zap.SugaredLogger,
 	tcpClient consentTCPSocketClient,
-	routes map[string]config.Route,
-	destinations map[string]config.Destination,
+	routing *config.Routing,
 ) *consentService {
 	return &consentService{
-		config:       cfg,
-		logger:       logger,
-		tcpClient:    tcpClient,
-		routes:       routes,
-		destinations: destinations,
+		systemI: systemI{
+			logger:    logger,
+			tcpClient: tcpClient,
+			routing:   routing,
+		},
+		config: cfg,
 	}
 }
 
@@ -58,368 +49,34 @@ func HasValidUpdateConsentItem(list []domain.ConsentListsobj) bool {
 
 // It sends a request to the TCP service and returns the response.
 func (s *consentService) UpdateConsent(c *gin.Context, updateConsentReq domain.UpdateConsentRequest) domain.UpdateConsentResult {
-	timestamp := time.Now()
-	routeKey := utils.GetRouteKey(c)
-	const destinationName = "systemI"
-	serviceName := "UpdateConsent"
-	var domainErr *appError.AppError
-	var logLine1 string
-	var Format string
-	var formatReq interface{}
-	var formatResp interface{}
-	var formatErr interface{}
-
-	reqID, _ := c.Get("Api-RequestID")
-	apiRequestID, ok := reqID.(string)
-	if !ok {
-		apiRequestID = ""
-	}
-
-	route, ok := s.routes[routeKey]
-	if !ok {
-		s.logger.Errorw("Route configuration not found for TCP service", "routeKey", routeKey)
-		return domain.UpdateConsentResult{
-			Response:    nil,
-			AppError:    appError.ErrService,
-			GinCtx:      nil,
-			Timestamp:   timestamp,
-			ReqBody:     nil,
-			RespBody:    nil,
-			DomainError: nil,
-			ServiceName: serviceName,
-			UserRef:     updateConsentReq.IDCardNo,
-			LogLine1:    "",
-		}
-	}
-
-	destination, ok := s.destinations[destinationName]
-	if !ok {
-		s.logger.Errorw("TCP Destination configuration not found", "destinationName", destinationName)
-		return domain.UpdateConsentResult{
-			Response:    nil,
-			AppError:    appError.ErrService,
-			GinCtx:      nil,
-			Timestamp:   timestamp,
-			ReqBody:     nil,
-			RespBody:    nil,
-			DomainError: nil,
-			ServiceName: serviceName,
-			UserRef:     updateConsentReq.IDCardNo,
-			LogLine1:    "",
-		}
-	}
-	if destination.Type != "tcp" {
-		s.logger.Errorw("Destination type is not TCP", "destinationName", destinationName, "type", destination.Type)
-		return domain.UpdateConsentResult{
-			Response:    nil,
-			AppError:    appError.ErrService,
-			GinCtx:      nil,
-			Timestamp:   timestamp,
-			ReqBody:     nil,
-			RespBody:    nil,
-			DomainError: nil,
-			ServiceName: serviceName,
-			UserRef:     updateConsentReq.IDCardNo,
-			LogLine1:    "",
-		}
-	}
-
-	portList, ok := destination.Ports["UpdateConsent"]
-	if !ok || len(portList) == 0 {
-		s.logger.Errorw("Invalid port configuration", "port", portList)
-		return domain.UpdateConsentResult{
-			Response:    nil,
-			AppError:    appError.ErrService,
-			GinCtx:      nil,
-			Timestamp:   timestamp,
-			ReqBody:     nil,
-			RespBody:    nil,
-			DomainError: nil,
-			ServiceName: serviceName,
-			UserRef:     updateConsentReq.IDCardNo,
-			LogLine1:    "",
-		}
-	}
-	port := utils.RandomPortFromList(portList)
-	if port == "" {
-		s.logger.Errorw("Invalid port configuration", "port", portList)
-		return domain.UpdateConsentResult{
-			Response:    nil,
-			AppError:    appError.ErrService,
-			GinCtx:      nil,
-			Timestamp:   timestamp,
-			ReqBody:     nil,
-			RespBody:    nil,
-			DomainError: nil,
-			ServiceName: serviceName,
-			UserRef:     updateConsentReq.IDCardNo,
-			LogLine1:    "",
-		}
-	}
-
-	if !HasValidUpdateConsentItem(updateConsentReq.ConsentLists) {
-		return domain.UpdateConsentResult{
-		Response:    nil,
-		AppError:    appError.ErrRequiedParam,
-		GinCtx:      nil,
-		Timestamp:   timestamp,
-		ReqBody:     nil,
-		RespBody:    nil,
-		DomainError: nil,
-		ServiceName: serviceName,
-		UserRef:     updateConsentReq.IDCardNo,
-		LogLine1:    "",
-		}
-	}
-
-	switch updateConsentReq.Channel {
-		case "L", "A", "W", "R", "O", "E":
-	
-		default:
-		s.logger.Errorw("Invalid Channel", "Channel", updateConsentReq.Channel)
-		return domain.UpdateConsentResult{
-		Response:    nil,
-		AppError:    appError.ErrApiChannel,
-		GinCtx:      nil,
-		Timestamp:   timestamp,
-		ReqBody:     nil,
-		RespBody:    nil,
-		DomainError: nil,
-		ServiceName: serviceName,
-		UserRef:     updateConsentReq.IDCardNo,
-		LogLine1:    "",
-		}
-	}
-
-	if len(updateConsentReq.ConsentLists) != updateConsentReq.TotalOfConsentCode {
-		s.logger.Errorw("Mismatch in number of cards", "Expected", updateConsentReq.TotalOfConsentCode, "Actual", len(updateConsentReq.ConsentLists),)
-		return domain.UpdateConsentResult{
-			Response:    nil,
-			AppError:    appError.ErrInvTotalOfList,
-			GinCtx:      nil,
-			Timestamp:   timestamp,
-			ReqBody:     nil,
-			RespBody:    nil,
-			DomainError: nil,
-			ServiceName: serviceName,
-			UserRef:     updateConsentReq.IDCardNo,
-			LogLine1:    "",
-			}
-	}
-
-	switch {
-    case updateConsentReq.ActionChannel == "APP" :
-	Format = "002"
-    default:
-	Format = "001"
-    }
-
-	formattedRequestID := utils.PadOrTruncate(apiRequestID, 20)
-	fixedLengthData := format.FormatUpdateConsentRequest(updateConsentReq)
-
-	requestLength := fmt.Sprintf("%05d", len(fixedLengthData))
-
-	header := utils.BuildFixedLengthHeader(
-		route.System,
-		route.Service,
-		Format,
-		formattedRequestID,
-		requestLength,
-	)
-
-	combinedPayloadString := header + fixedLengthData
-	s.logger.Info("Sending TCP request payload : ", combinedPayloadString)
-
-	tcpAddress := fmt.Sprintf("%s:%s", destination.IP, port)
-	responseStr, err := s.tcpClient.SendAndReceive(tcpAddress, combinedPayloadString)
-
-	cleanRsponseStr := strings.ReplaceAll(responseStr, "\r", "")
-	cleanRsponseStr = strings.ReplaceAll(cleanRsponseStr, "\n", "")
-
-	formatReq = map[string]string{"data": combinedPayloadString}
-	formatResp = map[string]string{"data": cleanRsponseStr}
-
-	if err != nil {
-		s.logger.Errorw("Downstream TCP service call failed", "error", err, "address", tcpAddress)
-
-		formatErr = map[string]string{"data": err.Error()}
-
-        errMsg := err.Error()
-
-        switch {
-        case strings.Contains(errMsg, "ER040"), strings.Contains(errMsg, "ER060"):
-            temp := *appError.ErrTimeOut
-            temp.StatusCode = "504"
-            domainErr = &temp
-
-        case strings.Contains(errMsg, "ER099"):
-            temp := *appError.ErrInternalServer
-            temp.StatusCode = "500"
-            domainErr = &temp
-
-        default:
-            domainErr = appError.ErrService
-        }
-
-		logLine1 = elkLog.GenerateELKLogLine(c, timestamp, formatReq, formatErr, domainErr, "", destination.IP+":"+port, serviceName, "UpdateConsent", "", updateConsentReq.IDCardNo)
-		if logLine1 == "" {
-			s.logger.Errorw("Error generating log: %v", logLine1)
-			return domain.UpdateConsentResult{
-				Response:    nil,
-				AppError:    appError.ErrInternalServer,
-				GinCtx:      nil,
-				Timestamp:   timestamp,
-				ReqBody:     nil,
-				RespBody:    nil,
-				DomainError: nil,
-				ServiceName: serviceName,
-				UserRef:     updateConsentReq.IDCardNo,
-				LogLine1:    "",
+	return execute(c, s.systemI, &updateConsentReq, inquiry[domain.UpdateConsentRequest, domain.UpdateConsentResponse]{
+		service:    "UpdateConsent",
+		balanceKey: updateConsentReq.IDCardNo,
+		userRef:    updateConsentReq.IDCardNo,
+		validate: func(req domain.UpdateConsentRequest) *appError.AppError {
+			if !HasValidUpdateConsentItem(req.ConsentLists) {
+				return appError.ErrRequiedParam
 			}
-		}
-
-		return domain.UpdateConsentResult{
-			Response:    nil,
-			AppError:    nil,
-			GinCtx:      c,
-			Timestamp:   timestamp,
-			ReqBody:     updateConsentReq,
-			RespBody:    formatErr,
-			DomainError: domainErr,
-			ServiceName: serviceName,
-			UserRef:     updateConsentReq.IDCardNo,
-			LogLine1:    logLine1,
-		}
-	}
-
-	errorCode := strings.TrimSpace(responseStr[67:73])
-	errorMessage := strings.TrimSpace(responseStr[73:123])
-	if errorCode != "" {
-		switch errorCode {
-		case "SVC105", "SVC128":
-			domainErr = appError.ErrRequiedParam
-		case "SVC117":
-			domainErr = appError.ErrUserRefOrAeonID
-		case "SVC123":
-			domainErr = appError.ErrInvDateTime
-		case "SVC122": 
-			domainErr = appError.ErrInvActChannel
-		case "SVC124": 
-			domainErr = appError.ErrInvAppNoCST
-		case "SVC126": 
-			domainErr = appError.ErrInvATMNo
-		case "SVC127": 
-			domainErr = appError.ErrInvBranchCode
-		case "SVC125": 
-			domainErr = appError.ErrInvIPAddress
-		case "SVC129":
-			domainErr = appError.ErrInvTotalOfList
-		case "SVC141": 
-			domainErr = appError.ErrInvConsentFrom
-		case "SVC142": 
-			domainErr = appError.ErrInvConsentCode
-		case "SVC144":
-			domainErr = appError.ErrInvConsentVer
-		case "SVC143": 
-			domainErr = appError.ErrInvConsentStatus
-		case "SVC902":
-			domainErr = appError.ErrSystemI 
-		default:
-			if errorCode != "" {
-				s.logger.Info("Unknown error code from System I : ", "code", errorCode, "message", errorMessage)
-				domainErr = appError.ErrSystemIUnexpect
+			switch req.Channel {
+			case "L", "A", "W", "R", "O", "E":
+			default:
+				s.logger.Errorw("Invalid Channel", "Channel", req.Channel)
+				return appError.ErrApiChannel
 			}
-		}
-		temp := *domainErr
-		temp.Code = errorCode
-		temp.Message = errorMessage
-		domainErr = &temp
-
-		logLine1 = elkLog.GenerateELKLogLine(c, timestamp, formatReq, formatResp, domainErr, "", destination.IP+":"+port, serviceName, "UpdateConsent", "", updateConsentReq.IDCardNo)
-		if logLine1 == "" {
-			s.logger.Errorw("Error generating log: %v", logLine1)
-			return domain.UpdateConsentResult{
-				Response:    nil,
-				AppError:    appError.ErrInternalServer,
-				GinCtx:      nil,
-				Timestamp:   timestamp,
-				ReqBody:     nil,
-				RespBody:    nil,
-				DomainError: nil,
-				ServiceName: serviceName,
-				UserRef:     updateConsentReq.IDCardNo,
-				LogLine1:    "",
+			if len(req.ConsentLists) != req.TotalOfConsentCode {
+				s.logger.Errorw("Mismatch in number of cards", "Expected", req.TotalOfConsentCode, "Actual", len(req.ConsentLists))
+				return appError.ErrInvTotalOfList
 			}
-		}
-
-		return domain.UpdateConsentResult{
-			Response:    nil,
-			AppError:    nil,
-			GinCtx:      c,
-			Timestamp:   timestamp,
-			ReqBody:     formatReq,
-			RespBody:    domainErr,
-			DomainError: domainErr,
-			ServiceName: serviceName,
-			UserRef:     updateConsentReq.IDCardNo,
-			LogLine1:    logLine1,
-		}
-	}
-
-	s.logger.Info("Received downstream TCP response", "response", string(responseStr))
-	updateConsentResponse, err := format.FormatUpdateConsentResponse(responseStr)
-	if err != nil {
-		s.logger.Errorw("Error map UpdateConsentResponse:", err)
-
-		formatErr = map[string]string{"data": err.Error()}
-		logLine1 = elkLog.GenerateELKLogLine(c, timestamp, formatReq, formatErr, nil, "", destination.IP+":"+port, serviceName, "UpdateConsent", "", updateConsentReq.IDCardNo)
-		if logLine1 == "" {
-			s.logger.Errorw("Error generating log: %v", logLine1)
-			return domain.UpdateConsentResult{
-				Response:    nil,
-				AppError:    appError.ErrInternalServer,
-				GinCtx:      nil,
-				Timestamp:   timestamp,
-				ReqBody:     nil,
-				RespBody:    nil,
-				DomainError: nil,
-				ServiceName: serviceName,
-				UserRef:     updateConsentReq.IDCardNo,
-				LogLine1:    "",
+			return nil
+		},
+		header: func(req domain.UpdateConsentRequest, route config.Route) (string, string, string) {
+			if req.ActionChannel == "APP" {
+				return route.System, route.Service, "002"
 			}
-		}
-	}
-
-	logLine1 = elkLog.GenerateELKLogLine(c, timestamp, formatReq, formatResp, nil, "", destination.IP+":"+port, serviceName, "UpdateConsent", "", updateConsentReq.IDCardNo)
-	if logLine1 == "" {
-		s.logger.Errorw("Error generating log: %v", logLine1)
-		return domain.UpdateConsentResult{
-			Response:    nil,
-			AppError:    appError.ErrInternalServer,
-			GinCtx:      nil,
-			Timestamp:   timestamp,
-			ReqBody:     nil,
-			RespBody:    nil,
-			DomainError: nil,
-			ServiceName: serviceName,
-			UserRef:     updateConsentReq.IDCardNo,
-			LogLine1:    "",
-		}
-	}
-
-	if domainErr != nil && domainErr.ErrorCode == "" {
-		domainErr = nil
-	}
-	return domain.UpdateConsentResult{
-		Response:    &updateConsentResponse,
-		AppError:    nil,
-		GinCtx:      c,
-		Timestamp:   timestamp,
-		ReqBody:     updateConsentReq,
-		RespBody:    formatResp,
-		DomainError: domainErr,
-		ServiceName: serviceName,
-		UserRef:     updateConsentReq.IDCardNo,
-		LogLine1:    logLine1,
-	}
-}
\ No newline at end of file
+			return route.System, route.Service, "001"
+		},
+		render:  format.FormatUpdateConsentRequest,
+		layouts: recordLayouts[domain.UpdateConsentRequest]("UpdateConsent"),
+		parse:   format.FormatUpdateConsentResponse,
+	})
+}
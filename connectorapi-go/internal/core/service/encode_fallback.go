@@ -0,0 +1,34 @@
+package service
+
+import (
+	"errors"
+
+	"connectorapi-go/internal/adapter/utils"
+	"connectorapi-go/pkg/config"
+	appError "connectorapi-go/pkg/error"
+)
+
+// sanitizeRequest applies the route's fallback policy for characters the
+// destination's codec cannot encode to every string field of req in place. It
+// returns an ErrInvCharacter naming the offending field when the policy
+// rejects a character.
+func sanitizeRequest(req interface{}, route config.Route, codec utils.Codec) *appError.AppError {
+	err := utils.SanitizeFields(req, codec, route.EncodeFallback)
+	if err == nil {
+		return nil
+	}
+	var encErr *utils.EncodeError
+	if !errors.As(err, &encErr) {
+		return &appError.AppError{
+			ErrorCode:    appError.ErrInternalServer.ErrorCode,
+			ErrorMessage: appError.ErrInternalServer.ErrorMessage,
+			Err:          err,
+		}
+	}
+	return &appError.AppError{
+		ErrorCode:    appError.ErrInvCharacter.ErrorCode,
+		ErrorMessage: appError.ErrInvCharacter.ErrorMessage + " (" + encErr.Field + ")",
+		ErrorFields:  encErr.Field,
+		Err:          err,
+	}
+}
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"connectorapi-go/internal/adapter/client"
+	"connectorapi-go/internal/adapter/utils"
+	"connectorapi-go/internal/core/domain"
+	"connectorapi-go/pkg/config"
+	"connectorapi-go/pkg/tracing"
+
+	elkLog "connectorapi-go/internal/adapter/client/elk"
+	appError "connectorapi-go/pkg/error"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.uber.org/zap"
+)
+
+// systemIDestination is the destination every System-I inquiry is sent to.
+const systemIDestination = "systemI"
+
+// responseHeaderLen is the length of the header every System-I response
+// starts with; the error code and message are at 67-73 and 73-123.
+const responseHeaderLen = 123
+
+// systemI holds what a service needs to reach System-I. Services embed it and
+// hand it to execute.
+type systemI struct {
+	logger    *zap.SugaredLogger
+	tcpClient client.TCPSocketClient
+	routing   *config.Routing
+}
+
+// inquiry describes one System-I exchange for execute. service, parse and
+// one of request or render are required; the other hooks fall back to the
+// route and the error catalog.
+type inquiry[Req, Resp any] struct {
+	service    string // destination port key, error-catalog key and ELK service name
+	logName    string // service name on the ELK line; service when empty
+	balanceKey string // key for hash-based port selection
+	userToken  string
+	userRef    string
+	// requestID rejects requests without an Api-RequestID with ErrApiRequestID
+	// before anything else is checked.
+	requestID bool
+
+	// precheck runs before the route is looked up, on the request as received.
+	precheck func(Req) *appError.AppError
+	// validate runs once the request has been sanitized; an error is answered
+	// without contacting System-I.
+	validate func(Req) *appError.AppError
+	// header returns the system, service and format codes of the request
+	// header; the route's when nil.
+	header  func(Req, config.Route) (system, service, format string)
+	request func(Req) string
+	// render is used instead of request for bodies built from record
+	// layouts, which can fail; a failure is answered as an internal error.
+	render func(Req) (string, error)
+	// layouts names the record layouts of the request and response bodies,
+	// used to mask them field by field in the logs; the route's when nil.
+	layouts func(Req) (request, response string)
+	// responseError reads the System-I error code and message from the
+	// response; the header fields when nil.
+	responseError func(response string) (code, message string)
+	// mapError turns a System-I error code into the error returned to the
+	// client and reports whether the code is known; the error catalog when nil.
+	mapError func(code, message string) (*appError.AppError, bool)
+	parse    func(response string) (Resp, error)
+}
+
+// execute sends req to System-I on the route of the current request and maps
+// the outcome to a Result: configuration and validation failures as AppError,
+// transport, System-I and strict parsing failures as DomainError with an ELK
+// line, and the parsed response otherwise. req is sanitized in place.
+func execute[Req, Resp any](c *gin.Context, sys systemI, req *Req, q inquiry[Req, Resp]) domain.Result[Resp] {
+	timestamp := time.Now()
+	routeKey := utils.GetRouteKey(c)
+	logName := q.logName
+	if logName == "" {
+		logName = q.service
+	}
+	result := domain.Result[Resp]{
+		Timestamp:   timestamp,
+		ServiceName: q.service,
+		UserToken:   q.userToken,
+		UserRef:     q.userRef,
+	}
+	reject := func(appErr *appError.AppError) domain.Result[Resp] {
+		result.AppError = appErr
+		return result
+	}
+	span, endSpan := startServiceSpan(c, q.service)
+	defer func() {
+		if appErr := result.AppError; appErr != nil {
+			span.SetAttributes(tracing.ErrorCodeKey.String(appErr.ErrorCode))
+			span.SetStatus(codes.Error, appErr.ErrorMessage)
+		} else if domainErr := result.DomainError; domainErr != nil {
+			span.SetAttributes(tracing.ErrorCodeKey.String(domainErr.ErrorCode))
+			span.SetStatus(codes.Error, domainErr.ErrorMessage)
+		}
+		endSpan()
+	}()
+
+	reqID, _ := c.Get("Api-RequestID")
+	apiRequestID, ok := reqID.(string)
+	if !ok && q.requestID {
+		return reject(appError.ErrApiRequestID)
+	}
+	if q.precheck != nil {
+		if appErr := q.precheck(*req); appErr != nil {
+			return reject(appErr)
+		}
+	}
+
+	dr := sys.routing.Current()
+	route, ok := dr.Routes[routeKey]
+	if !ok {
+		sys.logger.Errorw("Route configuration not found for TCP service", "routeKey", routeKey)
+		return reject(appError.ErrService)
+	}
+	destination, ok := dr.Destinations[systemIDestination]
+	if !ok {
+		sys.logger.Errorw("TCP Destination configuration not found", "destinationName", systemIDestination)
+		return reject(appError.ErrService)
+	}
+	if destination.Type != "tcp" {
+		sys.logger.Errorw("Destination type is not TCP", "destinationName", systemIDestination, "type", destination.Type)
+		return reject(appError.ErrService)
+	}
+	portList, ok := destination.Ports[q.service]
+	if !ok || len(portList) == 0 {
+		sys.logger.Errorw("Invalid port configuration", "port", portList)
+		return reject(appError.ErrService)
+	}
+	selector := utils.NewPortSelector(destination, q.service, q.balanceKey)
+	port := selector.Pick(portList)
+	if port == "" {
+		sys.logger.Errorw("Invalid port configuration", "port", portList)
+		return reject(appError.ErrService)
+	}
+
+	codec, err := utils.CodecByName(destination.Codec)
+	if err != nil {
+		sys.logger.Errorw("Invalid destination codec", "destinationName", systemIDestination, "error", err)
+		return reject(appError.ErrService)
+	}
+	if charErr := sanitizeRequest(req, route, codec); charErr != nil {
+		sys.logger.Warnw("Request contains characters that cannot be encoded for System-I", "error", charErr.Err)
+		return reject(charErr)
+	}
+	if q.validate != nil {
+		if appErr := q.validate(*req); appErr != nil {
+			return reject(appErr)
+		}
+	}
+
+	system, service, formatCode := route.System, route.Service, route.Format
+	if q.header != nil {
+		system, service, formatCode = q.header(*req, route)
+	}
+	span.SetAttributes(
+		tracing.SystemKey.String(system),
+		tracing.ServiceKey.String(service),
+		tracing.FormatKey.String(formatCode),
+	)
+	var fixedLengthData string
+	if q.render != nil {
+		body, err := q.render(*req)
+		if err != nil {
+			sys.logger.Errorw("Failed to format the request body", "service", q.service, "error", err)
+			return reject(&appError.AppError{
+				ErrorCode:    appError.ErrInternalServer.ErrorCode,
+				ErrorMessage: appError.ErrInternalServer.ErrorMessage,
+				Err:          err,
+			})
+		}
+		fixedLengthData = body
+	} else {
+		fixedLengthData = q.request(*req)
+	}
+	header := utils.BuildFixedLengthHeader(
+		system,
+		service,
+		formatCode,
+		utils.PadOrTruncate(apiRequestID, 20),
+		fixedLengthData,
+		codec,
+	)
+	combinedPayloadString := header + fixedLengthData
+	requestLayout, responseLayout := route.RequestLayout, route.ResponseLayout
+	if q.layouts != nil {
+		requestLayout, responseLayout = q.layouts(*req)
+	}
+	c.Set(elkLog.RequestLayoutKey, requestLayout)
+	c.Set(elkLog.ResponseLayoutKey, responseLayout)
+	sys.logger.Info("Sending TCP request payload : ", elkLog.MaskPayload(c, elkLog.RequestLayoutKey, combinedPayloadString))
+
+	ctx, cancel := routeContext(c, route)
+	defer cancel()
+	responseStr, port, err := sendWithFailover(ctx, sys.tcpClient, sys.logger, selector, portList, port, route, combinedPayloadString)
+	tcpAddress := fmt.Sprintf("%s:%s", destination.IP, port)
+	span.SetAttributes(semconv.ServerPort(portNumber(port)))
+	if code := responseCode(responseStr); err == nil && code != "" {
+		span.SetAttributes(tracing.ResponseKey.String(code))
+	}
+
+	cleanRsponseStr := strings.ReplaceAll(responseStr, "\r", "")
+	cleanRsponseStr = strings.ReplaceAll(cleanRsponseStr, "\n", "")
+	formatReq := map[string]string{"data": combinedPayloadString}
+	formatResp := map[string]string{"data": cleanRsponseStr}
+
+	// logged finishes a request that reached (or tried to reach) System-I:
+	// the ELK line records elkResp. A line that cannot be built is logged and
+	// left out; the request is answered all the same.
+	logged := func(elkResp interface{}, domainErr *appError.AppError) {
+		result.LogLine1 = elkLog.GenerateELKLogLine(c, timestamp, formatReq, elkResp, domainErr, "", tcpAddress, q.service, logName, q.userToken, q.userRef)
+		if result.LogLine1 == "" {
+			sys.logger.Errorw("Error generating ELK log line", "service", q.service)
+		}
+		result.GinCtx = c
+		result.DomainError = domainErr
+	}
+
+	if err != nil {
+		sys.logger.Errorw("Downstream TCP service call failed", "error", err, "address", tcpAddress)
+		formatErr := map[string]string{"data": err.Error()}
+		logged(formatErr, transportError(err))
+		result.ReqBody, result.RespBody = *req, formatErr
+		return result
+	}
+
+	if len(responseStr) < responseHeaderLen {
+		sys.logger.Errorw("System-I response is shorter than its header", "length", len(responseStr), "address", tcpAddress)
+		logged(formatResp, appError.ErrSystemIResponse)
+		result.ReqBody, result.RespBody = formatReq, appError.ErrSystemIResponse
+		return result
+	}
+	readError := q.responseError
+	if readError == nil {
+		readError = headerError
+	}
+	if errorCode, errorMessage := readError(responseStr); errorCode != "" {
+		mapError := q.mapError
+		if mapError == nil {
+			mapError = func(code, message string) (*appError.AppError, bool) {
+				return appError.ResolveSystemI(q.service, responseFormat(responseStr), code, message)
+			}
+		}
+		domainErr, known := mapError(errorCode, errorMessage)
+		if !known {
+			sys.logger.Info("Unknown error code from System I : ", "code", errorCode, "message", errorMessage)
+		}
+		logged(formatResp, domainErr)
+		result.ReqBody, result.RespBody = formatReq, domainErr
+		return result
+	}
+
+	sys.logger.Info("Received downstream TCP response", "response", elkLog.MaskPayload(c, elkLog.ResponseLayoutKey, responseStr))
+	response, err := q.parse(responseStr)
+	if parseErr := strictParseError(route, err); parseErr != nil {
+		sys.logger.Errorw("Rejected malformed System-I response", "error", err, "address", tcpAddress)
+		logged(parseFailureBody(cleanRsponseStr, parseErr), parseErr)
+		result.ReqBody, result.RespBody = formatReq, parseErr
+		return result
+	}
+	if err != nil {
+		sys.logger.Errorw("Error map "+q.service+" response:", "error", err)
+	}
+
+	logged(formatResp, nil)
+	result.Response = &response
+	result.ReqBody, result.RespBody = *req, formatResp
+	return result
+}
+
+// recordLayouts names the embedded layouts <name>Request and <name>Response
+// for inquiry.layouts.
+func recordLayouts[Req any](name string) func(Req) (string, string) {
+	return func(Req) (string, string) {
+		return name + "Request", name + "Response"
+	}
+}
+
+// headerError reads the error code and message System-I puts in the response
+// header. execute has checked that response holds a whole header.
+func headerError(response string) (string, string) {
+	return strings.TrimSpace(response[67:73]), strings.TrimSpace(response[73:responseHeaderLen])
+}
+
+// responseFormat reads the format of a System-I response from its header.
+// execute has checked that response holds a whole header.
+func responseFormat(response string) string {
+	return strings.TrimSpace(response[25:28])
+}
+
+// transportError maps a failed System-I exchange to the error returned to the
+// client.
+func transportError(err error) *appError.AppError {
+	errMsg := err.Error()
+	switch {
+	case strings.Contains(errMsg, "ER040"), strings.Contains(errMsg, "ER060"):
+		temp := *appError.ErrTimeOut
+		temp.StatusCode = "504"
+		return &temp
+	case strings.Contains(errMsg, "ER099"):
+		temp := *appError.ErrInternalServer
+		temp.StatusCode = "500"
+		return &temp
+	default:
+		return appError.ErrService
+	}
+}
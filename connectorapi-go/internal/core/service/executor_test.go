@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"connectorapi-go/internal/adapter/client"
+	"connectorapi-go/internal/adapter/utils"
+	"connectorapi-go/pkg/config"
+	appError "connectorapi-go/pkg/error"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type echoRequest struct {
+	IDCardNo string
+}
+
+// replyClient answers every request with reply, or fails with err.
+type replyClient struct {
+	reply   string
+	err     error
+	payload string
+}
+
+func (f *replyClient) SendAndReceive(address, payload string) (string, error) {
+	return f.SendAndReceiveContext(context.Background(), address, payload)
+}
+
+func (f *replyClient) SendAndReceiveContext(ctx context.Context, address, payload string) (string, error) {
+	f.payload = payload
+	return f.reply, f.err
+}
+
+// systemIResponse builds a response with the given header error and body.
+func systemIResponse(code, message, body string) string {
+	return strings.Repeat(" ", 67) + pad(code, 6) + pad(message, 50) + body
+}
+
+func pad(s string, n int) string {
+	return s + strings.Repeat(" ", n-len(s))
+}
+
+func echoContext(requestID bool) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("POST", "/Api/Echo", nil)
+	if requestID {
+		c.Set("Api-RequestID", "REQ1")
+	}
+	return c
+}
+
+func echoSystem(tcp client.TCPSocketClient, c *gin.Context) systemI {
+	return systemI{
+		logger:    zap.NewNop().Sugar(),
+		tcpClient: tcp,
+		routing: config.NewRouting(&config.DestinationsAndRoutes{
+			Routes: map[string]config.Route{
+				utils.GetRouteKey(c): {System: "SYS", Service: "ECHO", Format: "001"},
+			},
+			Destinations: map[string]config.Destination{
+				systemIDestination: {Type: "tcp", IP: "10.0.0.1", Ports: map[string][]string{"Echo": {"40110"}}},
+			},
+		}),
+	}
+}
+
+func echoInquiry() inquiry[echoRequest, string] {
+	return inquiry[echoRequest, string]{
+		service: "Echo",
+		userRef: "ID1",
+		request: func(req echoRequest) string { return req.IDCardNo },
+		parse:   func(response string) (string, error) { return strings.TrimSpace(response[123:]), nil },
+	}
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name      string
+		tcp       *replyClient
+		edit      func(*inquiry[echoRequest, string])
+		wantApp   string
+		wantErr   string
+		wantReply string
+
+		noRequestID bool
+	}{
+		{
+			name:      "success",
+			tcp:       &replyClient{reply: systemIResponse("", "", "PONG")},
+			wantReply: "PONG",
+		},
+		{
+			name:    "system I error code",
+			tcp:     &replyClient{reply: systemIResponse("SVC999", "unknown", "")},
+			wantErr: appError.ErrSystemIUnexpect.ErrorCode,
+		},
+		{
+			name: "mapped error code",
+			tcp:  &replyClient{reply: systemIResponse("SVC001", "blocked", "")},
+			edit: func(q *inquiry[echoRequest, string]) {
+				q.mapError = func(code, message string) (*appError.AppError, bool) {
+					return &appError.AppError{ErrorCode: code, ErrorMessage: message}, true
+				}
+			},
+			wantErr: "SVC001",
+		},
+		{
+			name:    "response shorter than the header",
+			tcp:     &replyClient{reply: "SHORT"},
+			wantErr: appError.ErrSystemIResponse.ErrorCode,
+		},
+		{
+			name:    "transport timeout",
+			tcp:     &replyClient{err: &client.SendError{Code: "ER060", Message: "read timeout"}},
+			wantErr: appError.ErrTimeOut.ErrorCode,
+		},
+		{
+			name: "validation",
+			tcp:  &replyClient{},
+			edit: func(q *inquiry[echoRequest, string]) {
+				q.validate = func(echoRequest) *appError.AppError { return appError.ErrRequiedParam }
+			},
+			wantApp: appError.ErrRequiedParam.ErrorCode,
+		},
+		{
+			name: "request body fails to render",
+			tcp:  &replyClient{},
+			edit: func(q *inquiry[echoRequest, string]) {
+				q.render = func(echoRequest) (string, error) { return "", errors.New("unknown layout") }
+			},
+			wantApp: appError.ErrInternalServer.ErrorCode,
+		},
+		{
+			name: "missing request id",
+			tcp:  &replyClient{},
+			edit: func(q *inquiry[echoRequest, string]) {
+				q.requestID = true
+			},
+			noRequestID: true,
+			wantApp:     appError.ErrApiRequestID.ErrorCode,
+		},
+		{
+			name: "header override",
+			tcp:  &replyClient{reply: systemIResponse("", "", "PONG")},
+			edit: func(q *inquiry[echoRequest, string]) {
+				q.header = func(_ echoRequest, route config.Route) (string, string, string) {
+					return route.System, "ECHO_V2", "002"
+				}
+			},
+			wantReply: "PONG",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := echoContext(!tt.noRequestID)
+			q := echoInquiry()
+			if tt.edit != nil {
+				tt.edit(&q)
+			}
+			res := execute(c, echoSystem(tt.tcp, c), &echoRequest{IDCardNo: "1234"}, q)
+
+			if tt.wantApp != "" {
+				if res.AppError == nil || res.AppError.ErrorCode != tt.wantApp {
+					t.Fatalf("AppError = %+v, want %s", res.AppError, tt.wantApp)
+				}
+				if tt.tcp.payload != "" {
+					t.Fatal("rejected request reached System I")
+				}
+				return
+			}
+			if res.AppError != nil {
+				t.Fatalf("unexpected AppError %+v", res.AppError)
+			}
+			if res.LogLine1 == "" || res.UserRef != "ID1" || res.ServiceName != q.service {
+				t.Fatalf("result not filled: %+v", res)
+			}
+			if !strings.HasSuffix(tt.tcp.payload, "1234") {
+				t.Errorf("payload %q does not end with the request body", tt.tcp.payload)
+			}
+			if tt.wantErr != "" {
+				if res.DomainError == nil || res.DomainError.ErrorCode != tt.wantErr || res.Response != nil {
+					t.Fatalf("DomainError = %+v, want %s", res.DomainError, tt.wantErr)
+				}
+				return
+			}
+			if res.DomainError != nil || res.Response == nil || *res.Response != tt.wantReply {
+				t.Fatalf("got response %v, error %+v", res.Response, res.DomainError)
+			}
+			if q.header != nil && !strings.Contains(tt.tcp.payload, "ECHO_V2        002") {
+				t.Errorf("header override not applied: %q", tt.tcp.payload)
+			}
+		})
+	}
+}
+
+func TestExecuteReadsCurrentRouting(t *testing.T) {
+	tcp := &replyClient{reply: systemIResponse("", "", "PONG")}
+	c := echoContext(true)
+	sys := echoSystem(tcp, c)
+
+	sys.routing.Store(&config.DestinationsAndRoutes{Destinations: sys.routing.Current().Destinations})
+	if res := execute(c, sys, &echoRequest{}, echoInquiry()); res.AppError != appError.ErrService {
+		t.Fatalf("AppError = %+v after the route was removed, want ErrService", res.AppError)
+	}
+	if tcp.payload != "" {
+		t.Fatal("request without a route reached System I")
+	}
+}
+
+func TestExecuteResolvesByResponseFormat(t *testing.T) {
+	catalog, err := appError.ParseCatalog([]byte(`{"services": {
+		"Echo": {"SVC117": "ErrUserRefOrAeonID"},
+		"Echo/004": {"SVC117": "ErrInvIDCardNo"}
+	}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	appError.SetCatalog(catalog)
+	defer appError.SetCatalog(&appError.Catalog{})
+
+	for format, want := range map[string]*appError.AppError{"001": appError.ErrUserRefOrAeonID, "004": appError.ErrInvIDCardNo} {
+		reply := systemIResponse("SVC117", "not found", "")
+		reply = reply[:25] + format + reply[28:]
+		c := echoContext(true)
+		res := execute(c, echoSystem(&replyClient{reply: reply}, c), &echoRequest{IDCardNo: "1234"}, echoInquiry())
+		if res.DomainError == nil || res.DomainError.ErrorCode != want.ErrorCode {
+			t.Errorf("format %s: DomainError = %+v, want %s", format, res.DomainError, want.ErrorCode)
+		}
+	}
+}
@@ -67,20 +67,20 @@ func FormatAgreeMentBillingResponse(raw string) (domain.AgreeMentBillingResponse
 
 	parser := utils.NewFixedParser(data)
 
-	dueDate                          := parser.ReadInt(0,8)
-	settlementDate                   := parser.ReadInt(8,8)
-	billingAmount                    := parser.ReadFloat100(16,11)
-	minPaymentAmount                 := parser.ReadFloat100(27,11)
-	fullPaymentAmount                := parser.ReadFloat100(38,11)
-	unbilledAmount                   := parser.ReadFloat100(49,11)
-	creditShoppingFloorLimit         := parser.ReadFloat100(60,11)
-	creditShoppingOutstanding        := parser.ReadFloat100(71,11)
-	creditShoppingAvailableLimit     := parser.ReadFloat100(82,11)
-	creditCashingFloorLimit          := parser.ReadFloat100(93,11)
-	creditCashingOutstanding         := parser.ReadFloat100(104,11)
-	creditCashingAvailableLimit      := parser.ReadFloat100(115,11)
-	installmentNo                    := parser.ReadInt(126,3)
-	installmentCurrent               := parser.ReadInt(129,3)
+	dueDate                          := parser.ReadInt("DueDate", 0,8)
+	settlementDate                   := parser.ReadInt("SettlementDate", 8,8)
+	billingAmount                    := parser.ReadFloat100("BillingAmount", 16,11)
+	minPaymentAmount                 := parser.ReadFloat100("MinPaymentAmount", 27,11)
+	fullPaymentAmount                := parser.ReadFloat100("FullPaymentAmount", 38,11)
+	unbilledAmount                   := parser.ReadFloat100("UnbilledAmount", 49,11)
+	creditShoppingFloorLimit         := parser.ReadFloat100("CreditShoppingFloorLimit", 60,11)
+	creditShoppingOutstanding        := parser.ReadFloat100("CreditShoppingOutstanding", 71,11)
+	creditShoppingAvailableLimit     := parser.ReadFloat100("CreditShoppingAvailableLimit", 82,11)
+	creditCashingFloorLimit          := parser.ReadFloat100("CreditCashingFloorLimit", 93,11)
+	creditCashingOutstanding         := parser.ReadFloat100("CreditCashingOutstanding", 104,11)
+	creditCashingAvailableLimit      := parser.ReadFloat100("CreditCashingAvailableLimit", 115,11)
+	installmentNo                    := parser.ReadInt("InstallmentNo", 126,3)
+	installmentCurrent               := parser.ReadInt("InstallmentCurrent", 129,3)
 	paymentHistory                   := parser.ReadString(132,36)
 
 	return domain.AgreeMentBillingResponse{
@@ -99,5 +99,5 @@ func FormatAgreeMentBillingResponse(raw string) (domain.AgreeMentBillingResponse
 		InstallmentNo:                installmentNo,
 		InstallmentCurrent:           installmentCurrent,
 		PaymentHistory:               paymentHistory,
-	}, nil
+	}, parser.Err()
 } 
\ No newline at end of file
@@ -112,7 +112,7 @@ func FormatSubmitCardApplicationResponse(raw string) (domain.SubmitCardApplicati
 	programID         := parser.ReadString(62,10)
 	resultCode        := parser.ReadString(72,2)
 	resultDescription := parser.ReadString(74,50)
-	totalCard         := parser.ReadInt(124,2)
+	totalCard         := parser.ReadInt("TotalApplyCard", 124,2)
 	cardStart         := 126
 
 	const cardLen = 103
@@ -135,7 +135,7 @@ func FormatSubmitCardApplicationResponse(raw string) (domain.SubmitCardApplicati
 			ReasonCode:   utils.ReadBlockStr(blockRunes, 19, 2),
 			Remark1:      utils.ReadBlockStr(blockRunes, 21, 30),
 			Remark2:      utils.ReadBlockStr(blockRunes, 51, 30),
-			MaximumLimit: utils.ReadBlockFloat100ToDecimal(blockRunes, 81, 10),
+			MaximumLimit: parser.ReadDecimal100(fmt.Sprintf("SubmitCardListRs[%d].MaximumLimit", i), start+81, 10),
 			PINNumber:    utils.ReadBlockStr(blockRunes, 91, 12),
 		})
 	}
@@ -151,5 +151,5 @@ func FormatSubmitCardApplicationResponse(raw string) (domain.SubmitCardApplicati
 		ResultDescription: resultDescription,
 		TotalApplyCard:    totalCard,
 		SubmitCardListRs:  cards,
-	}, nil
+	}, parser.Err()
 }
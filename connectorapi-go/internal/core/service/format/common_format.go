@@ -115,34 +115,34 @@ func FormatGetCustomerInfoResponse003(raw string) (domain.GetCustomerInfoRespons
 	namePreFixEN                     := parser.ReadString(22, 20)
 	customerNameENG                  := parser.ReadString(42, 30)
 	customerNameTH                   := parser.ReadString(72, 30)
-	age                              := parser.ReadInt(102, 3)
-	birthdate                        := parser.ReadInt(105, 8)
-	gender                           := parser.ReadInt(113, 1)
-	marriageStatus                   := parser.ReadInt(114, 1)
+	age                              := parser.ReadInt("Age", 102, 3)
+	birthdate                        := parser.ReadInt("Birthdate", 105, 8)
+	gender                           := parser.ReadInt("Gender", 113, 1)
+	marriageStatus                   := parser.ReadInt("MarriageStatus", 114, 1)
 	educationCode                    := parser.ReadString(115, 2)
 	educationDescription             := parser.ReadString(117, 50)
-	homeStatus                       := parser.ReadInt(167, 1)
+	homeStatus                       := parser.ReadInt("HomeStatus", 167, 1)
 	livingPeriod                     := parser.ReadString(168, 4)
-	stayWith                         := parser.ReadInt(172, 3)
+	stayWith                         := parser.ReadInt("StayWith", 172, 3)
 	homeAddress                      := parser.ReadString(175, 100)
-	homeZip                          := parser.ReadInt(275, 5)
+	homeZip                          := parser.ReadInt("HomeZip", 275, 5)
 	homePhone                        := parser.ReadString(280, 10)
 	homePhoneExtension               := parser.ReadString(290, 5)
 	officeName                       := parser.ReadString(295, 50)
 	officeSection                    := parser.ReadString(345, 30)
 	officeAddress                    := parser.ReadString(375, 100)
-	officeZip                        := parser.ReadInt(475, 5)
+	officeZip                        := parser.ReadInt("OfficeZip", 475, 5)
 	officePhone                      := parser.ReadString(480, 10)
 	officeExtension                  := parser.ReadString(490, 5)
 	businessType                     := parser.ReadString(495, 2)
 	businessTypeDescription          := parser.ReadString(497, 50)
-	jobTypeCode                      := parser.ReadInt(547, 2)
+	jobTypeCode                      := parser.ReadInt("JobTypeCode", 547, 2)
 	jobTypeSubCode                   := parser.ReadString(549, 2)
 	otherJobDescription              := parser.ReadString(551, 50)
 	workingPeriod                    := parser.ReadString(601, 4)
 	employmentStatus                 := parser.ReadString(605, 2)
-	salary                           := parser.ReadFloat100(607, 11)
-	otherIncome                      := parser.ReadFloat100(618, 11)
+	salary                           := parser.ReadFloat100("Salary", 607, 11)
+	otherIncome                      := parser.ReadFloat100("OtherIncome", 618, 11)
 	otherIncomeResource              := parser.ReadString(629, 1)
 	otherIncomeResourceDescription   := parser.ReadString(630, 20)
 	sourceOfOtherIncomeCountry       := parser.ReadString(650, 3)
@@ -158,12 +158,12 @@ func FormatGetCustomerInfoResponse003(raw string) (domain.GetCustomerInfoRespons
 	referencePhone                   := parser.ReadString(823, 10)
 	referenceExtension               := parser.ReadString(833, 4)
 	houseRegistrationHome            := parser.ReadString(837, 100)
-	houseRegistrationHomeZip         := parser.ReadInt(937, 5)
+	houseRegistrationHomeZip         := parser.ReadInt("HouseRegistrationHomeZip", 937, 5)
 	debtReferenceName                := parser.ReadString(942, 30)
 	debtReferenceRelationship        := parser.ReadString(972, 15)
-	debtReferencePhone               := parser.ReadInt(987, 11)
+	debtReferencePhone               := parser.ReadInt("DebtReferencePhone", 987, 11)
 	debtReferencePhoneExtension      := parser.ReadString(998, 5)
-	debtReferenceMobilePhone         := parser.ReadInt(1003, 11)
+	debtReferenceMobilePhone         := parser.ReadInt("DebtReferenceMobilePhone", 1003, 11)
 	paymentType                      := parser.ReadString(1014, 2)
 	autoPayBankName                  := parser.ReadString(1016, 30)
 	autoPayAccountNo                 := parser.ReadString(1046, 10)
@@ -188,7 +188,7 @@ func FormatGetCustomerInfoResponse003(raw string) (domain.GetCustomerInfoRespons
 	officeDistrict                   := parser.ReadString(1425, 25)
 	officeProvince                   := parser.ReadString(1450, 20)
 	officeMobilePhone                := parser.ReadString(1470, 10)
-	houseRegistrationCode            := parser.ReadInt(1480, 1)
+	houseRegistrationCode            := parser.ReadInt("HouseRegistrationCode", 1480, 1)
 
 	return domain.GetCustomerInfoResponse003{
 		IDCardNo:                         idCardNo,
@@ -271,7 +271,7 @@ func FormatGetCustomerInfoResponse003(raw string) (domain.GetCustomerInfoRespons
 		OfficeProvince:                   officeProvince,
 		OfficeMobilePhone:                officeMobilePhone,
 		HouseRegistrationCode:            houseRegistrationCode,
-	}, nil
+	}, parser.Err()
 }
 
 // Converts CheckApplyConditionRequest to a fixed-length string.
@@ -367,14 +367,8 @@ func FormatCheckApplyCondition2ndCardResponse(raw string) (domain.CheckApplyCond
 		return strings.TrimSpace(string(rs))
 	}
 
-	readInt := func(start, length int) int {
-		s := readString(start, length)
-		if s == "" {
-			return 0
-		}
-		i, _ := strconv.Atoi(s)
-		return i
-	}
+	parser := utils.NewFixedParser(body)
+	readInt := parser.ReadInt
 
 	// readFloat := func(start, length int) float64 {
 	// 	s := readString(start, length)
@@ -386,11 +380,11 @@ func FormatCheckApplyCondition2ndCardResponse(raw string) (domain.CheckApplyCond
 	// }
 
 	idCardNo         := readString(0, 20)
-	maximumCR        := readInt(20, 2)
-	haveCardCR       := readInt(22, 2)
-	maximumYC        := readInt(24, 2)
-	haveCardYC       := readInt(26, 2)
-	totalOfApplyCard := readInt(28, 2)
+	maximumCR        := readInt("MaximumCR", 20, 2)
+	haveCardCR       := readInt("HaveCardCR", 22, 2)
+	maximumYC        := readInt("MaximumYC", 24, 2)
+	haveCardYC       := readInt("HaveCardYC", 26, 2)
+	totalOfApplyCard := readInt("TotalOfApplyCard", 28, 2)
 
 	const agreementLen = 56
 	agreementStart := 30
@@ -447,5 +441,5 @@ func FormatCheckApplyCondition2ndCardResponse(raw string) (domain.CheckApplyCond
 		HaveCardYC:                haveCardYC,
 		TotalOfApplyCard:          totalOfApplyCard,
 		CheckApply2ndCardList:     agreements,
-	}, nil
+	}, parser.Err()
 }
\ No newline at end of file
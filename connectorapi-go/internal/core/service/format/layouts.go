@@ -0,0 +1,123 @@
+package format
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+
+	"connectorapi-go/internal/adapter/utils"
+	"connectorapi-go/pkg/layout"
+)
+
+// headerLen is the length of the fixed System-I header in front of every body.
+const headerLen = 123
+
+//go:embed layouts/*.yaml
+var layoutFiles embed.FS
+
+// layouts holds the declarative record layouts in layouts/. They are part of
+// the binary; a broken file is kept in layoutsErr for CheckLayouts, so the
+// gateway stops at startup rather than on the first request that needs it.
+var layouts, layoutsErr = func() (*layout.Registry, error) {
+	r := layout.NewRegistry()
+	if err := r.LoadFS(layoutFiles, "layouts"); err != nil {
+		return r, fmt.Errorf("loading record layouts: %w", err)
+	}
+	return r, nil
+}()
+
+// CheckLayouts reports an embedded layout file that did not load.
+func CheckLayouts() error {
+	return layoutsErr
+}
+
+// Layout returns the record layout registered under name.
+func Layout(name string) (*layout.Layout, bool) {
+	return layouts.Get(name)
+}
+
+// lookupLayout returns the named layout, or an error if none is registered.
+func lookupLayout(name string) (*layout.Layout, error) {
+	l, ok := layouts.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown layout %s", name)
+	}
+	return l, nil
+}
+
+// marshalLayout renders v with the named layout. The layouts are embedded and
+// covered by tests, so an error here is a programming error; it is returned
+// for the executor to answer as an internal error.
+func marshalLayout(name string, v interface{}) (string, error) {
+	l, err := lookupLayout(name)
+	if err != nil {
+		return "", err
+	}
+	return l.Marshal(v)
+}
+
+// unmarshalLayout strips the System-I header from raw and reads the body into
+// v. Numeric fields that do not parse are reported as utils.ParseErrors, in
+// which case v is still filled in.
+func unmarshalLayout(name string, raw string, v interface{}) error {
+	if len(raw) <= headerLen {
+		return fmt.Errorf("raw data too short for header, length=%d", len(raw))
+	}
+	l, err := lookupLayout(name)
+	if err != nil {
+		return err
+	}
+	return parseErrors(l.Unmarshal(raw[headerLen:], v))
+}
+
+// parseErrors reports the fields a layout could not read as utils.ParseErrors,
+// the form strict parsing checks for.
+func parseErrors(err error) error {
+	var fieldErrs layout.FieldErrors
+	if errors.As(err, &fieldErrs) {
+		parseErrs := make(utils.ParseErrors, len(fieldErrs))
+		for i, fe := range fieldErrs {
+			parseErrs[i] = utils.ParseError{Field: fe.Field, Offset: fe.Offset, Raw: fe.Raw}
+		}
+		return parseErrs
+	}
+	return err
+}
+
+// LoadLayouts adds the layout files in dir to the embedded layouts, so
+// configured routes can use records that are not compiled in. A name that is
+// already taken is an error.
+func LoadLayouts(dir string) error {
+	return layouts.LoadFS(os.DirFS(dir), ".")
+}
+
+// FormatRecord renders a request decoded from JSON with the named layout.
+func FormatRecord(name string, r layout.Record) (string, error) {
+	l, err := lookupLayout(name)
+	if err != nil {
+		return "", err
+	}
+	return l.MarshalRecord(r)
+}
+
+// ParseRecord strips the System-I header from raw and reads the body with the
+// named layout, reporting malformed numeric fields like unmarshalLayout.
+func ParseRecord(name string, raw string) (layout.Record, error) {
+	if len(raw) <= headerLen {
+		return nil, fmt.Errorf("raw data too short for header, length=%d", len(raw))
+	}
+	l, err := lookupLayout(name)
+	if err != nil {
+		return nil, err
+	}
+	r, err := l.UnmarshalRecord(raw[headerLen:])
+	return r, parseErrors(err)
+}
+
+// malformed reports whether err only lists fields that did not parse, so the
+// record itself was read.
+func malformed(err error) bool {
+	var parseErrs utils.ParseErrors
+	return errors.As(err, &parseErrs)
+}
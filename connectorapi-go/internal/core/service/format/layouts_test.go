@@ -0,0 +1,78 @@
+package format
+
+import (
+	"strings"
+	"testing"
+
+	"connectorapi-go/internal/core/domain"
+)
+
+func TestEmbeddedLayoutsLoad(t *testing.T) {
+	for _, name := range []string{
+		"CollectionDetailRequest", "CollectionDetailResponse", "CollectionLogRequest", "CollectionLogResponse",
+		"UpdateConsentRequest", "UpdateConsentResponse",
+		"DashboardSummaryRequest", "DashboardSummaryResponse", "DashboardDetailRequest", "DashboardDetailResponse",
+		"MobileFullPanRequest", "MobileFullPanResponse",
+	} {
+		if _, ok := Layout(name); !ok {
+			t.Errorf("layout %s is not registered", name)
+		}
+	}
+}
+
+func TestCollectionLogUsesLayout(t *testing.T) {
+	body, err := FormatCollectionLogRequest(domain.CollectionLogRequest{AgreementNo: "1234567890123456", RemarkCode: "R01", LogRemark1: "ลูกค้าติดต่อกลับ"})
+	if err != nil {
+		t.Fatalf("FormatCollectionLogRequest: %v", err)
+	}
+	if n := len([]rune(body)); n != 649 {
+		t.Fatalf("request length = %d, want 649", n)
+	}
+
+	raw := strings.Repeat("H", headerLen) + "3100000000001       1234567890123456"
+	resp, err := FormatCollectionLogResponse(raw)
+	if err != nil {
+		t.Fatalf("FormatCollectionLogResponse: %v", err)
+	}
+	if resp.IDCardNo != "3100000000001" || resp.AgreementNo != "1234567890123456" {
+		t.Fatalf("response = %+v", resp)
+	}
+
+	if _, err := FormatCollectionLogResponse(strings.Repeat("H", headerLen) + "short"); err == nil {
+		t.Fatal("short body parsed without error")
+	}
+}
+
+func TestMarshalUnknownLayoutIsAnError(t *testing.T) {
+	if _, err := marshalLayout("NoSuchRequest", domain.CollectionLogRequest{}); err == nil {
+		t.Fatal("unknown layout marshalled without error")
+	}
+}
+
+func TestDashboardDetailReadsRemainPaymentPerCard(t *testing.T) {
+	block := func(remainMin, remainFull string) string {
+		// Card number, a Thai card name and the other fields up to
+		// PaidAmount, then the remain-payment amounts and the rest.
+		b := "4000111122223333" + "บัตรเครดิต" + strings.Repeat(" ", 20) + strings.Repeat("0", 34+33)
+		return b + remainMin + remainFull + strings.Repeat("0", 114)
+	}
+	body := "AEON0001" + strings.Repeat(" ", 12) + "20261018" + "00" +
+		block("00000001000", "00000002000") + block("00000003000", "00000004000")
+	resp, err := FormatDashboardDetailResponse(strings.Repeat("H", headerLen)+body, false)
+	if err != nil {
+		t.Fatalf("FormatDashboardDetailResponse: %v", err)
+	}
+	if len(resp.DashboardDetailList) != 2 {
+		t.Fatalf("%d cards, want 2", len(resp.DashboardDetailList))
+	}
+	for i, want := range [][2]float64{{10, 20}, {30, 40}} {
+		card := resp.DashboardDetailList[i]
+		if card.CardName != "บัตรเครดิต" {
+			t.Errorf("card %d name = %q", i, card.CardName)
+		}
+		if card.RemainMinimumPayment == nil || float64(*card.RemainMinimumPayment) != want[0] ||
+			card.RemainFullPayment == nil || float64(*card.RemainFullPayment) != want[1] {
+			t.Errorf("card %d remain = %v, %v; want %v", i, card.RemainMinimumPayment, card.RemainFullPayment, want)
+		}
+	}
+}
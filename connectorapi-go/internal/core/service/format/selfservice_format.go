@@ -53,17 +53,11 @@ func FormatMyCardResponseNormal(raw string) (domain.MyCardResponseNormal, error)
 		return strings.TrimSpace(string(rs))
 	}
 
-	readInt := func(start, length int) int {
-		s := readString(start, length)
-		if s == "" {
-			return 0
-		}
-		i, _ := strconv.Atoi(s)
-		return i
-	}
+	parser := utils.NewFixedParser(body)
+	readInt := parser.ReadInt
 
 	idCardNo                  := readString(0, 20)
-	totalCreditCard           := readInt(20, 4)
+	totalCreditCard           := readInt("TotalCreditCard", 20, 4)
 
 	const agreementLen = 61
 	agreementStart := 24
@@ -129,7 +123,7 @@ func FormatMyCardResponseNormal(raw string) (domain.MyCardResponseNormal, error)
 		IDCardNo:                 idCardNo,
 		TotalCreditCard:          totalCreditCard,
 		CardList:                 agreements,
-	}, nil
+	}, parser.Err()
 }
 
 func FormatMyCardResponseAll(raw string) (domain.MyCardResponseAll, error) {
@@ -156,19 +150,13 @@ func FormatMyCardResponseAll(raw string) (domain.MyCardResponseAll, error) {
 		return strings.TrimSpace(string(rs))
 	}
 	
-	readInt := func(start, length int) int {
-		s := readString(start, length)
-		if s == "" {
-			return 0
-		}
-		i, _ := strconv.Atoi(s)
-		return i
-	}
+	parser := utils.NewFixedParser(body)
+	readInt := parser.ReadInt
 
 	idCardNo               := readString(0, 20)
 	customerNameEN         := readString(20, 30)
 	customerNameTH         := readString(50, 30)
-	totalCreditCard     := readInt(80, 3)
+	totalCreditCard     := readInt("TotalCreditCard", 80, 3)
 
 	const agreementLen = 68
 	agreementStart := 83
@@ -192,13 +180,8 @@ func FormatMyCardResponseAll(raw string) (domain.MyCardResponseAll, error) {
 		return strings.TrimSpace(string(blockRunes[startField:end]))
 	}
 
-	readBlockInt := func(startField, length int) int {
-		s := readBlockStr(startField, length)
-		if s == "" {
-			return 0
-		}
-		i, _ := strconv.Atoi(s)
-		return i
+	readBlockInt := func(field string, startField, length int) int {
+		return parser.ReadInt(fmt.Sprintf("CardList[%d].%s", i, field), start+startField, length)
 	}
 
 	// Raw values
@@ -215,14 +198,14 @@ func FormatMyCardResponseAll(raw string) (domain.MyCardResponseAll, error) {
 		ProductType:         readBlockStr(18, 2),
 		CardType:            readBlockStr(20, 1),
 		CardStatus:          readBlockStr(21, 1),
-		ExpireDate:          readBlockInt(22, 8),
+		ExpireDate:          readBlockInt("ExpireDate", 22, 8),
 		HoldCode:            readBlockStr(30, 2),
 		RetreatCode:         readBlockStr(32, 1),
 		SendMode:            readBlockStr(33, 1),
-		FirstEmbossDate:     readBlockInt(34, 8),
-		FirstConfirmDate:    readBlockInt(42, 8),
-		ShoppingLimit:       readBlockInt(50, 9),
-		CashingLimit:        readBlockInt(59, 9),
+		FirstEmbossDate:     readBlockInt("FirstEmbossDate", 34, 8),
+		FirstConfirmDate:    readBlockInt("FirstConfirmDate", 42, 8),
+		ShoppingLimit:       readBlockInt("ShoppingLimit", 50, 9),
+		CashingLimit:        readBlockInt("CashingLimit", 59, 9),
 	})
 }
 
@@ -232,5 +215,5 @@ func FormatMyCardResponseAll(raw string) (domain.MyCardResponseAll, error) {
 		CustomerNameTH:           customerNameTH,
 		TotalCreditCard:          totalCreditCard,
 		CardList:                 agreements,
-	}, nil
+	}, parser.Err()
 }
\ No newline at end of file
@@ -2,7 +2,6 @@ package format
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 	//"bytes"
 
@@ -44,17 +43,17 @@ func FormatGetRedbookInfoResponse(raw string) (domain.GetRedbookInfoResponse, er
 	marketingCode  := parser.ReadString(8, 10)
 	brand          := parser.ReadString(18, 30)
 	model          := parser.ReadString(48, 30)
-	carYear        := parser.ReadInt(78, 4)
-	carMonth       := parser.ReadInt(82, 2)
+	carYear        := parser.ReadInt("CarYear", 78, 4)
+	carMonth       := parser.ReadInt("CarMonth", 82, 2)
 	subModel       := parser.ReadString(84, 100)
-	effectiveYear  := parser.ReadInt(184, 4)
-	effectiveMonth := parser.ReadInt(188, 2)
+	effectiveYear  := parser.ReadInt("EffectiveYear", 184, 4)
+	effectiveMonth := parser.ReadInt("EffectiveMonth", 188, 2)
 	vehicleCode    := parser.ReadString(190, 8)
-	avgWholesale   := parser.ReadFloat100(198, 8)
-	avgRetail      := parser.ReadFloat100(206, 8)
-	goodWholesale  := parser.ReadFloat100(214, 8)
-	goodRetail     := parser.ReadFloat100(222, 8)
-	newPrice       := parser.ReadFloat100(230, 8)
+	avgWholesale   := parser.ReadFloat100("AvgWholesale", 198, 8)
+	avgRetail      := parser.ReadFloat100("AvgRetail", 206, 8)
+	goodWholesale  := parser.ReadFloat100("GoodWholesale", 214, 8)
+	goodRetail     := parser.ReadFloat100("GoodRetail", 222, 8)
+	newPrice       := parser.ReadFloat100("NewPrice", 230, 8)
 
 	return domain.GetRedbookInfoResponse{
 		AgentCode:      agentCode,
@@ -72,7 +71,7 @@ func FormatGetRedbookInfoResponse(raw string) (domain.GetRedbookInfoResponse, er
 		GoodWholesale:  utils.DecimalString(goodWholesale),
 		GoodRetail:     utils.DecimalString(goodRetail),
 		NewPrice:       utils.DecimalString(newPrice),
-	}, nil
+	}, parser.Err()
 }
 
 // Converts GetDealerCommissionRequest to a fixed-length string.
@@ -105,13 +104,13 @@ func FormatGetDealerCommissionResponse(raw string) (domain.GetDealerCommissionRe
 	agreementNo          := parser.ReadString(18, 12)
 	commissionCode       := parser.ReadString(30, 8)
 	agentCategory        := parser.ReadString(38, 2)
-	totalCommission      := parser.ReadFloat100(40, 9)
-	vatRate              := parser.ReadFloat100(49, 4)
-	vat                  := parser.ReadFloat100(53, 9)
-	grandTotalCommission := parser.ReadFloat100(62, 9)
-	whtRate              := parser.ReadFloat100(71, 4)
-	whtTax               := parser.ReadFloat100(75, 9)
-	netTotalCommission   := parser.ReadFloat100(84, 9)
+	totalCommission      := parser.ReadFloat100("TotalCommission", 40, 9)
+	vatRate              := parser.ReadFloat100("VATRate", 49, 4)
+	vat                  := parser.ReadFloat100("VAT", 53, 9)
+	grandTotalCommission := parser.ReadFloat100("GrandTotalCommission", 62, 9)
+	whtRate              := parser.ReadFloat100("WHTRate", 71, 4)
+	whtTax               := parser.ReadFloat100("WHTTax", 75, 9)
+	netTotalCommission   := parser.ReadFloat100("NetTotalCommission", 84, 9)
 
 	return domain.GetDealerCommissionResponse{
 		AgentCode:            agentCode,
@@ -126,7 +125,7 @@ func FormatGetDealerCommissionResponse(raw string) (domain.GetDealerCommissionRe
 		WHTRate:              utils.DecimalString(whtRate),
 		WHTTax:               utils.DecimalString(whtTax),
 		NetTotalCommission:   utils.DecimalString(netTotalCommission),
-	}, nil
+	}, parser.Err()
 }
 
 // Converts GetDealerAgreementRequest to a fixed-length string.
@@ -164,14 +163,8 @@ func FormatGetDealerAgreementResponse(raw string) (domain.GetDealerAgreementResp
 		return strings.TrimSpace(string(rs))
 	}
 
-	readInt := func(start, length int) int {
-		s := readString(start, length)
-		if s == "" {
-			return 0
-		}
-		i, _ := strconv.Atoi(s)
-		return i
-	}
+	parser := utils.NewFixedParser(body)
+	readInt := parser.ReadInt
 
 	// readFloat := func(start, length int) float64 {
 	// 	s := readString(start, length)
@@ -184,10 +177,10 @@ func FormatGetDealerAgreementResponse(raw string) (domain.GetDealerAgreementResp
 
 	agentCode            := readString(0, 8)
 	marketingCode        := readString(8, 10)
-	transactionDateFrom  := readInt(18, 8)
-	transactionDateTo    := readInt(26, 8)
+	transactionDateFrom  := readInt("TransactionDateFrom", 18, 8)
+	transactionDateTo    := readInt("TransactionDateTo", 26, 8)
 	agreementNo          := readString(34, 12)
-	totalAgreement       := readInt(46, 3)
+	totalAgreement       := readInt("TotalAgreement", 46, 3)
 
 	const agreementLen = 76
 	agreementStart := 49
@@ -211,13 +204,8 @@ func FormatGetDealerAgreementResponse(raw string) (domain.GetDealerAgreementResp
 			return strings.TrimSpace(string(blockRunes[startField:end]))
 		}
 
-		readBlockInt := func(startField, length int) int {
-			s := readBlockStr(startField, length)
-			if s == "" {
-				return 0
-			}
-			i, _ := strconv.Atoi(s)
-			return i
+		readBlockInt := func(field string, startField, length int) int {
+			return parser.ReadInt(fmt.Sprintf("AgreementList[%d].%s", i, field), start+startField, length)
 		}
 
 	// 	readBlockFloat := func(startField, length int) utils.DecimalString {
@@ -230,7 +218,7 @@ func FormatGetDealerAgreementResponse(raw string) (domain.GetDealerAgreementResp
 
 		agreements = append(agreements, domain.AgreementListobj{
 			AgreementNo:             readBlockStr(0, 12),
-			TransactionDate:         readBlockInt(12, 8),
+			TransactionDate:         readBlockInt("TransactionDate", 12, 8),
 			CustomerName:            readBlockStr(20, 50),
 			Status:                  readBlockStr(70, 6),
 		})
@@ -244,5 +232,5 @@ func FormatGetDealerAgreementResponse(raw string) (domain.GetDealerAgreementResp
 		AgreementNo:                agreementNo,
 		TotalAgreement:             totalAgreement,
 		AgreementList:              agreements,
-	}, nil
+	}, parser.Err()
 }
\ No newline at end of file
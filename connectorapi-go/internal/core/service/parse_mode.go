@@ -0,0 +1,68 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"connectorapi-go/internal/adapter/utils"
+	"connectorapi-go/pkg/config"
+	appError "connectorapi-go/pkg/error"
+)
+
+// strictParseError returns the error to fail the request with when the route
+// parses strictly and err lists malformed fields that are not in the route's
+// LenientFields. Lenient routes keep the zeroed values, as before.
+func strictParseError(route config.Route, err error) *appError.AppError {
+	if !route.StrictParsing() {
+		return nil
+	}
+	var parseErrs utils.ParseErrors
+	if !errors.As(err, &parseErrs) {
+		return nil
+	}
+	var rejected utils.ParseErrors
+	for _, pe := range parseErrs {
+		if !lenientField(route.LenientFields, pe.Field) {
+			rejected = append(rejected, pe)
+		}
+	}
+	if len(rejected) == 0 {
+		return nil
+	}
+	return &appError.AppError{
+		ErrorCode:    appError.ErrSystemIResponse.ErrorCode,
+		ErrorMessage: appError.ErrSystemIResponse.ErrorMessage,
+		ErrorFields:  strings.Join(rejected.Fields(), ","),
+		Err:          rejected,
+	}
+}
+
+// lenientField matches a field path against the route's LenientFields. Group
+// fields match without their index, so "CardList.ExpireDate" covers every
+// "CardList[n].ExpireDate".
+func lenientField(lenient []string, field string) bool {
+	name := field
+	for strings.Contains(name, "[") {
+		open := strings.Index(name, "[")
+		end := strings.Index(name[open:], "]")
+		if end < 0 {
+			break
+		}
+		name = name[:open] + name[open+end+1:]
+	}
+	for _, l := range lenient {
+		if l == field || l == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFailureBody is what ELK records for a rejected response: the raw
+// record and the fields that did not parse.
+func parseFailureBody(raw string, parseErr *appError.AppError) map[string]interface{} {
+	return map[string]interface{}{
+		"data":        raw,
+		"parseErrors": parseErr.Err,
+	}
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"testing"
+
+	"connectorapi-go/internal/adapter/utils"
+	"connectorapi-go/pkg/config"
+	appError "connectorapi-go/pkg/error"
+)
+
+func TestStrictParseError(t *testing.T) {
+	err := utils.ParseErrors{
+		{Field: "CardList[0].ExpireDate", Offset: 105, Raw: "2025130X"},
+		{Field: "TotalCreditCard", Offset: 80, Raw: "0A1"},
+	}
+
+	if got := strictParseError(config.Route{}, err); got != nil {
+		t.Fatalf("lenient route rejected the response: %v", got)
+	}
+
+	strict := config.Route{ParseMode: "strict"}
+	got := strictParseError(strict, err)
+	if got == nil || got.ErrorCode != appError.ErrSystemIResponse.ErrorCode {
+		t.Fatalf("strict route: %v, want %s", got, appError.ErrSystemIResponse.ErrorCode)
+	}
+	if got.ErrorFields != "CardList[0].ExpireDate,TotalCreditCard" {
+		t.Fatalf("ErrorFields = %q", got.ErrorFields)
+	}
+
+	strict.LenientFields = []string{"CardList.ExpireDate"}
+	got = strictParseError(strict, err)
+	if got == nil || got.ErrorFields != "TotalCreditCard" {
+		t.Fatalf("with LenientFields: %v, want only TotalCreditCard", got)
+	}
+
+	strict.LenientFields = append(strict.LenientFields, "TotalCreditCard")
+	if got := strictParseError(strict, err); got != nil {
+		t.Fatalf("all fields lenient: %v, want nil", got)
+	}
+	if got := strictParseError(strict, nil); got != nil {
+		t.Fatalf("no error: %v, want nil", got)
+	}
+}
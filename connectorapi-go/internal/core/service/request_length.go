@@ -0,0 +1,164 @@
+package service
+
+import (
+	"sort"
+
+	"connectorapi-go/internal/adapter/utils"
+	"connectorapi-go/internal/core/domain"
+	"connectorapi-go/internal/core/service/format"
+	"connectorapi-go/pkg/config"
+)
+
+// sampleCodec measures the empty sample bodies, which are ASCII and so the
+// same length in every codec.
+var sampleCodec, _ = utils.CodecByName(utils.CodecCP874)
+
+// requestBodies renders an empty request for every route whose body has a
+// fixed length, once per request variant the service can send. The header
+// request length is derived from the real body at send time; these samples
+// only exist to cross-check the RequestLength values in the routes file.
+var requestBodies = map[string]func() ([]string, error){
+	"POST:/Api/Collection/CollectionDetail": func() ([]string, error) {
+		return rendered(func() (string, error) { return format.FormatCollectionDetailRequest(domain.CollectionDetailRequest{}) })
+	},
+	"POST:/Api/Collection/CollectionLog": func() ([]string, error) {
+		return rendered(func() (string, error) { return format.FormatCollectionLogRequest(domain.CollectionLogRequest{}) })
+	},
+	"POST:/Api/Agreement/UpdateStatus": func() ([]string, error) {
+		return []string{format.FormatUpdateStatusRequest(domain.UpdateStatusRequest{})}, nil
+	},
+	"POST:/Api/Agreement/GetBilling": func() ([]string, error) {
+		return []string{format.FormatAgreeMentBillingRequest(domain.AgreeMentBillingRequest{})}, nil
+	},
+	"POST:/Api/CreditCard/GetCardSales": func() ([]string, error) {
+		return []string{format.FormatGetCardSalesRequest(domain.GetCardSalesRequest{})}, nil
+	},
+	"POST:/Api/CreditCard/GetBigCardInfo": func() ([]string, error) {
+		return []string{format.FormatGetBigCardInfoRequest(domain.GetBigCardInfoRequest{})}, nil
+	},
+	"POST:/Api/CreditCard/GetCardDelinquent": func() ([]string, error) {
+		return []string{format.FormatGetCardDelinquentRequest(domain.GetCardDelinquentRequest{})}, nil
+	},
+	"POST:/Api/Common/GetCustomerInfo": func() ([]string, error) {
+		return []string{
+			format.FormatGetCustomerInfoRequest001And003(domain.GetCustomerInfoRequest{}, ""),
+			format.FormatGetCustomerInfoRequest004(domain.GetCustomerInfoRequest{}),
+		}, nil
+	},
+	"POST:/Api/SelfService/MyCard": func() ([]string, error) {
+		return []string{
+			format.FormatMyCardRequestNormal(domain.MyCardRequest{}),
+			format.FormatMyCardRequestAll(domain.MyCardRequest{}),
+		}, nil
+	},
+	"POST:/Api/Register/CheckRegister": func() ([]string, error) {
+		return []string{format.FormatCheckRegisterRequest(domain.CheckRegisterRequest{})}, nil
+	},
+	"POST:/Api/Register/CheckRegisterSocial": func() ([]string, error) {
+		return []string{format.FormatCheckRegisterSocialRequest(domain.CheckRegisterSocialRequest{})}, nil
+	},
+	"POST:/Api/customer/getcustomerinfo/mobileno": func() ([]string, error) {
+		return []string{format.FormatGetCustomerInfoMobileNoRequest(domain.GetCustomerInfoMobileNoRequest{})}, nil
+	},
+	"POST:/Api/uhp/GetRedbookInfo": func() ([]string, error) {
+		return []string{format.FormatGetRedbookInfoRequest(domain.GetRedbookInfoRequest{})}, nil
+	},
+	"POST:/Api/uhp/GetDealerCommission": func() ([]string, error) {
+		return []string{format.FormatGetDealerCommissionRequest(domain.GetDealerCommissionRequest{})}, nil
+	},
+	"POST:/Api/uhp/GetDealerAgreement": func() ([]string, error) {
+		return []string{format.FormatGetDealerAgreementRequest(domain.GetDealerAgreementRequest{})}, nil
+	},
+	"POST:/Api/Mobile/DashboardSummary": func() ([]string, error) {
+		return rendered(
+			func() (string, error) {
+				return format.FormatDashboardSummaryRequest(true, domain.DashboardSummaryRequest{})
+			},
+			func() (string, error) {
+				return format.FormatDashboardSummaryRequest(false, domain.DashboardSummaryRequest{})
+			},
+		)
+	},
+	"POST:/Api/Mobile/DashboardDetail": func() ([]string, error) {
+		return rendered(
+			func() (string, error) {
+				return format.FormatDashboardDetailRequest(true, domain.DashboardDetailRequest{})
+			},
+			func() (string, error) {
+				return format.FormatDashboardDetailRequest(false, domain.DashboardDetailRequest{})
+			},
+		)
+	},
+	"POST:/Api/Mobile/MobileFullPAN": func() ([]string, error) {
+		return rendered(func() (string, error) { return format.FormatMobileFullPanRequest(domain.MobileFullPanFormatRequest{}) })
+	},
+	"POST:/Api/application/submitloanapplication": func() ([]string, error) {
+		return []string{format.FormatSubmitLoanApplicationRequest(domain.SubmitLoanApplicationRequest{})}, nil
+	},
+}
+
+// rendered collects the bodies of formatters that can fail, stopping at the
+// first error.
+func rendered(formatters ...func() (string, error)) ([]string, error) {
+	bodies := make([]string, 0, len(formatters))
+	for _, f := range formatters {
+		body, err := f()
+		if err != nil {
+			return nil, err
+		}
+		bodies = append(bodies, body)
+	}
+	return bodies, nil
+}
+
+// variableLengthRoutes carry repeating groups, so no single RequestLength can
+// describe them.
+var variableLengthRoutes = map[string]bool{
+	"POST:/Api/Common/CheckApplyCondition/ApplyCard":  true,
+	"POST:/Api/Common/CheckApplyCondition/SecondCard": true,
+	"POST:/Api/Consent/UpdateConsent":                 true,
+	"POST:/Api/Application/GetApplicationNo":          true,
+	"POST:/Api/Application/SubmitCardApplication":     true,
+}
+
+// RequestLengthMismatch is a route whose configured RequestLength disagrees
+// with the body its formatter produces.
+type RequestLengthMismatch struct {
+	Route      string
+	Configured string
+	Actual     string // derived length, "variable", or the formatter's error
+}
+
+// CheckRequestLengths compares every configured RequestLength with the length
+// derived from the route's formatter. Routes that leave RequestLength empty
+// are not checked. The configured value is never sent; a mismatch means the
+// routes file is out of date.
+func CheckRequestLengths(routes map[string]config.Route) []RequestLengthMismatch {
+	var mismatches []RequestLengthMismatch
+	for key, route := range routes {
+		if route.RequestLength == "" {
+			continue
+		}
+		if variableLengthRoutes[key] {
+			mismatches = append(mismatches, RequestLengthMismatch{Route: key, Configured: route.RequestLength, Actual: "variable"})
+			continue
+		}
+		bodies, ok := requestBodies[key]
+		if !ok {
+			continue
+		}
+		samples, err := bodies()
+		if err != nil {
+			mismatches = append(mismatches, RequestLengthMismatch{Route: key, Configured: route.RequestLength, Actual: err.Error()})
+			continue
+		}
+		for _, body := range samples {
+			if actual := utils.RequestLength(body, sampleCodec); actual != route.RequestLength {
+				mismatches = append(mismatches, RequestLengthMismatch{Route: key, Configured: route.RequestLength, Actual: actual})
+				break
+			}
+		}
+	}
+	sort.Slice(mismatches, func(i, j int) bool { return mismatches[i].Route < mismatches[j].Route })
+	return mismatches
+}
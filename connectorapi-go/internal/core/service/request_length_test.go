@@ -0,0 +1,40 @@
+package service
+
+import (
+	"testing"
+
+	"connectorapi-go/pkg/config"
+)
+
+func TestConfiguredRequestLengthsMatchFormatters(t *testing.T) {
+	dr, err := config.LoadDestinationsAndRoutes("../../../configs/destinations_routes.json")
+	if err != nil {
+		t.Fatalf("loading routes: %v", err)
+	}
+	for _, m := range CheckRequestLengths(dr.Routes) {
+		t.Errorf("%s: RequestLength %s, formatter produces %s", m.Route, m.Configured, m.Actual)
+	}
+	for key := range requestBodies {
+		if _, ok := dr.Routes[key]; !ok {
+			t.Errorf("request body sample for unknown route %s", key)
+		}
+	}
+}
+
+func TestCheckRequestLengthsReportsMismatch(t *testing.T) {
+	routes := map[string]config.Route{
+		"POST:/Api/Collection/CollectionDetail": {RequestLength: "00021"},
+		"POST:/Api/Consent/UpdateConsent":       {RequestLength: "00100"},
+		"POST:/Api/Collection/CollectionLog":    {},
+	}
+	got := CheckRequestLengths(routes)
+	if len(got) != 2 {
+		t.Fatalf("mismatches = %+v, want 2", got)
+	}
+	if got[0].Route != "POST:/Api/Collection/CollectionDetail" || got[0].Actual != "00050" {
+		t.Errorf("mismatch[0] = %+v", got[0])
+	}
+	if got[1].Actual != "variable" {
+		t.Errorf("mismatch[1] = %+v, want variable", got[1])
+	}
+}
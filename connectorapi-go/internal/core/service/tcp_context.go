@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+
+	"connectorapi-go/pkg/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// routeContext derives the context for a System-I call from the inbound request,
+// so a client that disconnects releases its socket, and applies the route's
+// Timeout from destinations_routes.json when one is configured.
+func routeContext(c *gin.Context, route config.Route) (context.Context, context.CancelFunc) {
+	ctx := context.Background()
+	if c != nil && c.Request != nil {
+		ctx = c.Request.Context()
+	}
+	if timeout := route.RequestTimeout(); timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
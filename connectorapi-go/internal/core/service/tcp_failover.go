@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"connectorapi-go/internal/adapter/client"
+	"connectorapi-go/internal/adapter/utils"
+	"connectorapi-go/pkg/config"
+
+	"go.uber.org/zap"
+)
+
+// sendWithFailover sends payload to firstPort and, when the attempt fails in a
+// way that is safe to repeat, retries on other ports from portList, chosen by
+// the same selector, until the route's Retries budget is spent. It returns the port that produced the
+// final result so the caller can log it.
+//
+// Connect failures (ER040) never reached System-I and are always retried.
+// Once a request may have been written, only routes marked Idempotent are
+// retried, and only for broken connections (ER060); timeouts are not
+// retried because the host may still be processing the first request.
+func sendWithFailover(
+	ctx context.Context,
+	tcpClient client.TCPSocketClient,
+	logger *zap.SugaredLogger,
+	selector utils.PortSelector,
+	portList []string,
+	firstPort string,
+	route config.Route,
+	payload string,
+) (string, string, error) {
+	port := firstPort
+	tried := map[string]bool{}
+	for attempt := 0; ; attempt++ {
+		tried[port] = true
+		address := fmt.Sprintf("%s:%s", selector.IP, port)
+		spanCtx, span := startTCPSpan(ctx, address, attempt)
+		responseStr, err := tcpClient.SendAndReceiveContext(spanCtx, address, payload)
+		endTCPSpan(span, responseStr, err)
+		if err == nil || attempt >= route.Retries || ctx.Err() != nil || !retryable(err, route) {
+			return responseStr, port, err
+		}
+
+		next := selector.Pick(utils.ExcludePorts(portList, tried))
+		if next == "" {
+			// Every port has been tried once; start over on the full list.
+			next = selector.Pick(portList)
+			tried = map[string]bool{}
+		}
+		logger.Warnw("Retrying System-I request on another port",
+			"error", err, "failedPort", port, "nextPort", next, "attempt", attempt+1, "service", route.Service)
+		port = next
+	}
+}
+
+// retryable decides whether a failed attempt may be sent again.
+func retryable(err error, route config.Route) bool {
+	switch client.ErrorCode(err) {
+	case "ER040":
+		return !client.RequestWritten(err)
+	case "ER060":
+		return route.Idempotent
+	default:
+		return false
+	}
+}
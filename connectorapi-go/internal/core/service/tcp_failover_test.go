@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"connectorapi-go/internal/adapter/client"
+	"connectorapi-go/internal/adapter/utils"
+	"connectorapi-go/pkg/config"
+
+	"go.uber.org/zap"
+)
+
+// scriptedClient fails every address listed in failures with the given error.
+type scriptedClient struct {
+	failures map[string]error
+	calls    []string
+}
+
+func (f *scriptedClient) SendAndReceive(address, payload string) (string, error) {
+	return f.SendAndReceiveContext(context.Background(), address, payload)
+}
+
+func (f *scriptedClient) SendAndReceiveContext(ctx context.Context, address, payload string) (string, error) {
+	f.calls = append(f.calls, address)
+	if err, ok := f.failures[address]; ok {
+		return "", err
+	}
+	return "OK", nil
+}
+
+func TestSendWithFailover(t *testing.T) {
+	connectErr := &client.SendError{Code: "ER040", Message: "connection refused"}
+	resetErr := &client.SendError{Code: "ER060", Message: "connection reset", Written: true}
+
+	tests := []struct {
+		name      string
+		route     config.Route
+		failure   error
+		wantCalls int
+		wantErr   bool
+	}{
+		{"connect error fails over", config.Route{Retries: 2}, connectErr, 2, false},
+		{"no budget no retry", config.Route{}, connectErr, 1, true},
+		{"written request on non-idempotent route", config.Route{Retries: 2}, resetErr, 1, true},
+		{"written request on idempotent route", config.Route{Retries: 2, Idempotent: true}, resetErr, 2, false},
+		{"unknown error is never retried", config.Route{Retries: 2, Idempotent: true}, errors.New("boom"), 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &scriptedClient{failures: map[string]error{"10.0.0.1:40110": tt.failure}}
+			resp, port, err := sendWithFailover(context.Background(), fake, zap.NewNop().Sugar(),
+				utils.PortSelector{IP: "10.0.0.1"}, []string{"40110", "40111"}, "40110", tt.route, "payload")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(fake.calls) != tt.wantCalls {
+				t.Fatalf("calls = %v, want %d", fake.calls, tt.wantCalls)
+			}
+			if !tt.wantErr && (resp != "OK" || port != "40111") {
+				t.Errorf("got resp %q from port %s, want OK from 40111", resp, port)
+			}
+			if tt.wantErr && !strings.HasSuffix(fake.calls[0], ":40110") {
+				t.Errorf("first call went to %s", fake.calls[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+
+	"connectorapi-go/internal/adapter/client"
+	"connectorapi-go/internal/adapter/utils"
+	"connectorapi-go/pkg/tracing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startServiceSpan starts the span of a service call under the span of the
+// inbound request and makes it current on c until end is called, so the TCP
+// spans started from routeContext become its children.
+func startServiceSpan(c *gin.Context, service string) (span trace.Span, end func()) {
+	if c == nil || c.Request == nil {
+		_, span = tracing.Tracer().Start(context.Background(), "service "+service)
+		return span, func() { span.End() }
+	}
+	req := c.Request
+	ctx, span := tracing.Tracer().Start(req.Context(), "service "+service,
+		trace.WithAttributes(tracing.RouteKey.String(utils.GetRouteKey(c))))
+	c.Request = req.WithContext(ctx)
+	return span, func() {
+		span.End()
+		c.Request = req
+	}
+}
+
+// startTCPSpan starts the client span of one System-I exchange with address.
+func startTCPSpan(ctx context.Context, address string, attempt int) (context.Context, trace.Span) {
+	host, port, _ := net.SplitHostPort(address)
+	return tracing.Tracer().Start(ctx, "tcp SendAndReceive",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.ServerAddress(host),
+			semconv.ServerPort(portNumber(port)),
+			tracing.TCPAttemptKey.Int(attempt),
+		))
+}
+
+// endTCPSpan records the outcome of an exchange on its span and ends it.
+func endTCPSpan(span trace.Span, response string, err error) {
+	if err != nil {
+		span.SetAttributes(tracing.TCPErrorKey.String(client.ErrorCode(err)))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, client.ErrorCode(err))
+	} else if code := responseCode(response); code != "" {
+		span.SetAttributes(tracing.ResponseKey.String(code))
+	}
+	span.End()
+}
+
+// responseCode returns the SVC error code of a System-I response header, or
+// "" when there is none or the response is too short to hold one.
+func responseCode(response string) string {
+	if len(response) < 73 {
+		return ""
+	}
+	return strings.TrimSpace(response[67:73])
+}
+
+func portNumber(port string) int {
+	n, _ := strconv.Atoi(port)
+	return n
+}
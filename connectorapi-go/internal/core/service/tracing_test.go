@@ -0,0 +1,79 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"connectorapi-go/internal/adapter/utils"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func spanAttr(s sdktrace.ReadOnlySpan, key string) attribute.Value {
+	for _, kv := range s.Attributes() {
+		if string(kv.Key) == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestExecuteSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(prev)
+
+	c := echoContext(true)
+	ctx, server := provider.Tracer("test").Start(c.Request.Context(), "POST /Api/Echo", trace.WithSpanKind(trace.SpanKindServer))
+	c.Request = c.Request.WithContext(ctx)
+	tcp := &replyClient{reply: systemIResponse("SVC001", "blocked", "")}
+
+	res := execute(c, echoSystem(tcp, c), &echoRequest{IDCardNo: "1234"}, echoInquiry())
+	server.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans, want tcp, service and server", len(spans))
+	}
+	tcpSpan, serviceSpan := spans[0], spans[1]
+	if serviceSpan.Name() != "service Echo" || serviceSpan.Parent().SpanID() != server.SpanContext().SpanID() {
+		t.Errorf("service span %q has parent %v", serviceSpan.Name(), serviceSpan.Parent().SpanID())
+	}
+	if tcpSpan.SpanKind() != trace.SpanKindClient || tcpSpan.Parent().SpanID() != serviceSpan.SpanContext().SpanID() {
+		t.Errorf("tcp span %q is not a client child of the service span", tcpSpan.Name())
+	}
+	for key, want := range map[string]string{
+		"systemi.system":        "SYS",
+		"systemi.service":       "ECHO",
+		"systemi.format":        "001",
+		"systemi.response_code": "SVC001",
+		"connector.route":       utils.GetRouteKey(c),
+	} {
+		if got := spanAttr(serviceSpan, key).Emit(); got != want {
+			t.Errorf("service span %s = %q, want %q", key, got, want)
+		}
+	}
+	if got := spanAttr(serviceSpan, "server.port").AsInt64(); got != 40110 {
+		t.Errorf("service span server.port = %d", got)
+	}
+	if got := spanAttr(tcpSpan, "systemi.response_code").Emit(); got != "SVC001" {
+		t.Errorf("tcp span response code = %q", got)
+	}
+	if serviceSpan.Status().Code.String() != "Error" {
+		t.Errorf("service span status = %v, want Error for a System-I error", serviceSpan.Status())
+	}
+
+	// The ELK line carries the trace, and the request is back on the server span.
+	if traceID := server.SpanContext().TraceID().String(); !strings.Contains(res.LogLine1, `"TraceID":"`+traceID+`"`) {
+		t.Errorf("ELK line without trace %s: %s", traceID, res.LogLine1)
+	}
+	if trace.SpanFromContext(c.Request.Context()) != server {
+		t.Error("request context not restored after the service span")
+	}
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net"
+	"slices"
+	"strings"
+	"time"
+)
+
+// APIKeyHash is an API key stored as a salted SHA-256 hash. The key is only
+// accepted between NotBefore and ExpiresAt (either may be unset), so a
+// client's key can be rotated by adding the new hash before the old one
+// expires.
+type APIKeyHash struct {
+	ID        string     `yaml:"id" json:"id"` // names the key in logs, metrics and /status/apikeys
+	Salt      string     `yaml:"salt" json:"salt"`
+	Hash      string     `yaml:"hash" json:"hash"`
+	NotBefore *time.Time `yaml:"notBefore" json:"notBefore,omitempty"`
+	ExpiresAt *time.Time `yaml:"expiresAt" json:"expiresAt,omitempty"`
+}
+
+// HashAPIKey returns the hex SHA-256 of the salt bytes followed by key.
+func HashAPIKey(salt []byte, key string) string {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(key))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Check reports whether the hash entry is well formed.
+func (h APIKeyHash) Check() error {
+	if h.ID == "" {
+		return fmt.Errorf("hash entry has no id")
+	}
+	if salt, err := hex.DecodeString(h.Salt); err != nil || len(salt) == 0 {
+		return fmt.Errorf("key %s: salt is not hex", h.ID)
+	}
+	if hash, err := hex.DecodeString(h.Hash); err != nil || len(hash) != sha256.Size {
+		return fmt.Errorf("key %s: hash is not a hex SHA-256", h.ID)
+	}
+	if h.NotBefore != nil && h.ExpiresAt != nil && !h.ExpiresAt.After(*h.NotBefore) {
+		return fmt.Errorf("key %s: expiresAt is not after notBefore", h.ID)
+	}
+	return nil
+}
+
+// RateLimit caps the requests of a client. RequestsPerSecond and Burst size
+// a token bucket; Daily and Monthly are quotas reset at local midnight and on
+// the first of the month. Zero fields are not enforced.
+type RateLimit struct {
+	RequestsPerSecond float64 `yaml:"requestsPerSecond" json:"requestsPerSecond,omitempty"`
+	Burst             int     `yaml:"burst" json:"burst,omitempty"`
+	Daily             int64   `yaml:"daily" json:"daily,omitempty"`
+	Monthly           int64   `yaml:"monthly" json:"monthly,omitempty"`
+}
+
+// Check reports whether the limit is well formed.
+func (l RateLimit) Check() error {
+	if l.RequestsPerSecond < 0 || l.Burst < 0 || l.Daily < 0 || l.Monthly < 0 {
+		return fmt.Errorf("rate limit values must not be negative")
+	}
+	if l.Burst > 0 && l.RequestsPerSecond == 0 {
+		return fmt.Errorf("burst without requestsPerSecond")
+	}
+	return nil
+}
+
+// ParseCIDRs parses AllowedCIDRs; a single IP stands for itself.
+func (k APIKey) ParseCIDRs() ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(k.AllowedCIDRs))
+	for _, s := range k.AllowedCIDRs {
+		if ip := net.ParseIP(s); ip != nil {
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, fmt.Errorf("allowedCIDRs: %q is not an IP or CIDR", s)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// Restriction returns the allowlist that refuses a request from ip with the
+// given Api-Channel and Api-DeviceOS: "ip", "channel" or "deviceOS", or ""
+// when the request may proceed. Networks must be filled in.
+func (k APIKey) Restriction(ip net.IP, channel, deviceOS string) string {
+	if len(k.Networks) > 0 {
+		allowed := false
+		for _, n := range k.Networks {
+			if ip != nil && n.Contains(ip) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return "ip"
+		}
+	}
+	if len(k.AllowedChannels) > 0 && !slices.Contains(k.AllowedChannels, channel) {
+		return "channel"
+	}
+	if len(k.AllowedDeviceOS) > 0 && !slices.ContainsFunc(k.AllowedDeviceOS, func(os string) bool { return strings.EqualFold(os, deviceOS) }) {
+		return "deviceOS"
+	}
+	return ""
+}
+
+const apiKeyAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+// GenerateAPIKey returns a new random 32-character key and its hash entry.
+func GenerateAPIKey(id string, notBefore, expiresAt *time.Time) (string, APIKeyHash, error) {
+	key := make([]byte, 32)
+	for i := range key {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(apiKeyAlphabet))))
+		if err != nil {
+			return "", APIKeyHash{}, err
+		}
+		key[i] = apiKeyAlphabet[n.Int64()]
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", APIKeyHash{}, err
+	}
+	return string(key), APIKeyHash{
+		ID:        id,
+		Salt:      hex.EncodeToString(salt),
+		Hash:      HashAPIKey(salt, string(key)),
+		NotBefore: notBefore,
+		ExpiresAt: expiresAt,
+	}, nil
+}
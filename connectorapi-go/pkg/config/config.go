@@ -2,7 +2,13 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
+	"net"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -14,26 +20,315 @@ type Config struct {
 	Destinations map[string]Destination `yaml:"destinations" json:"destinations"`
 	Routes       map[string]Route       `yaml:"routes" json:"routes"`
 	ELKPath      string                 `yaml:"elkPath"`
+	ELK          ELKConfig              `yaml:"elk"`
+	TCPClient    TCPClientConfig        `yaml:"tcpClient"`
+	ErrorCatalog ErrorCatalogConfig     `yaml:"errorCatalog"`
+	LayoutDir    string                 `yaml:"layoutDir"` // extra record layouts for configured routes
+	Reload       ReloadConfig           `yaml:"reload"`
+	Tracing      TracingConfig          `yaml:"tracing"`
 }
 type ServerConfig struct {
 	Port string `yaml:"port"`
 	Mode string `yaml:"mode"`
+	// TrustedProxies may set X-Forwarded-For / X-Real-IP for the client IP
+	// used in logs and API key allowlists; none are trusted when empty.
+	TrustedProxies []string `yaml:"trustedProxies"`
 }
 type LoggerConfig struct {
 	Level  string `yaml:"level"`
 	Format string `yaml:"format"`
 }
+// TCPClientConfig controls the System-I socket client and its connection pool.
+type TCPClientConfig struct {
+	DialTimeout      time.Duration `yaml:"dialTimeout"`
+	ReadWriteTimeout time.Duration `yaml:"readWriteTimeout"`
+	Pool             TCPPoolConfig `yaml:"pool"`
+	CircuitBreaker   CircuitBreakerConfig `yaml:"circuitBreaker"`
+}
+
+// CircuitBreakerConfig controls per-port outlier ejection. A port is ejected
+// after FailureThreshold consecutive connect/read/write failures and probed
+// again after OpenTimeout (multiplied by the number of consecutive ejections).
+type CircuitBreakerConfig struct {
+	Disabled           bool          `yaml:"disabled"`
+	FailureThreshold   int           `yaml:"failureThreshold"`
+	OpenTimeout        time.Duration `yaml:"openTimeout"`
+	MaxEjectionPercent int           `yaml:"maxEjectionPercent"`
+}
+
+// TCPPoolConfig sizes the per-address connection pool. MaxOpen 0 disables pooling.
+type TCPPoolConfig struct {
+	MinIdle     int           `yaml:"minIdle"`
+	MaxOpen     int           `yaml:"maxOpen"`
+	IdleTimeout time.Duration `yaml:"idleTimeout"`
+	MaxLifetime time.Duration `yaml:"maxLifetime"`
+	HealthCheck bool          `yaml:"healthCheck"`
+}
+// ELKConfig controls the background writer of the ELK log files in ELKPath.
+// Zero values take the defaults of elk.NewWriter.
+type ELKConfig struct {
+	QueueSize     int           `yaml:"queueSize"`     // requests buffered for the writer
+	BatchSize     int           `yaml:"batchSize"`     // lines written per flush at most
+	FlushInterval time.Duration `yaml:"flushInterval"` // longest a line waits in the buffer
+	MaxSizeMB     int           `yaml:"maxSizeMB"`     // rotate the day's file past this size; 0 rotates daily only
+	MaxAge        time.Duration `yaml:"maxAge"`        // delete rotated files older than this; 0 keeps them
+	MaxFiles      int           `yaml:"maxFiles"`      // keep at most this many rotated files; 0 keeps all
+	Compress      bool          `yaml:"compress"`      // gzip rotated files
+	// OnFull is "drop" (default) to drop lines when the queue is full or the
+	// disk write fails, or "block" to make requests wait and retry the write.
+	OnFull string `yaml:"onFull"`
+	// Masking hides personal and card data in the logged messages.
+	Masking MaskingConfig `yaml:"masking"`
+	// Sinks ship the log lines to a log store besides the files in ELKPath.
+	Sinks []SinkConfig `yaml:"sinks"`
+	// DisableFiles stops writing the files, for a deployment where no
+	// Filebeat reads them; it needs a remote sink.
+	DisableFiles bool `yaml:"disableFiles"`
+}
+
+// Check reports invalid masking and sink settings.
+func (e ELKConfig) Check() error {
+	if err := e.Masking.Check(); err != nil {
+		return err
+	}
+	names := map[string]bool{}
+	for i, s := range e.Sinks {
+		if err := s.Check(); err != nil {
+			return fmt.Errorf("sink %d: %w", i, err)
+		}
+		if names[s.SinkName()] {
+			return fmt.Errorf("sink %d: duplicate name %q", i, s.SinkName())
+		}
+		names[s.SinkName()] = true
+	}
+	if e.DisableFiles {
+		remote := false
+		for _, s := range e.Sinks {
+			if s.Type == SinkFile {
+				return fmt.Errorf("disableFiles is set but a %q sink is listed", SinkFile)
+			}
+			remote = true
+		}
+		if !remote {
+			return fmt.Errorf("disableFiles is set but no remote sink is listed")
+		}
+	}
+	return nil
+}
+
+// Sink types.
+const (
+	SinkFile          = "file"          // the LOG<yyyymmdd>.txt files in ELKPath
+	SinkLogstash      = "logstash"      // JSON lines over TCP or UDP
+	SinkElasticsearch = "elasticsearch" // the _bulk API, one index per day
+)
+
+// SinkConfig is one destination of the ELK log. Remote sinks get each line as
+// a JSON document with an @timestamp; a batch they cannot take is retried
+// with a doubling backoff and then spooled to disk until they are back.
+// Zero values take the defaults of the elk package.
+type SinkConfig struct {
+	Type string `yaml:"type"`
+	Name string `yaml:"name"` // in logs, metrics and the spool path; defaults to Type
+
+	Network string `yaml:"network"` // logstash: "tcp" (default) or "udp"
+	Address string `yaml:"address"` // logstash: host:port
+
+	URL      string `yaml:"url"`               // elasticsearch: base URL, e.g. http://es:9200
+	Index    string `yaml:"index"`             // elasticsearch: index prefix; lines go to <index>-yyyy.MM.dd
+	Username string `yaml:"username"`          // elasticsearch: basic auth
+	Password string `yaml:"password" json:"-"` // kept out of the startup log
+
+	Timeout      time.Duration `yaml:"timeout"`      // per connect, write or bulk request
+	MaxRetries   int           `yaml:"maxRetries"`   // retries of a batch before it is spooled; -1 for none
+	RetryBackoff time.Duration `yaml:"retryBackoff"` // first wait, doubled per retry
+	MaxBackoff   time.Duration `yaml:"maxBackoff"`   // longest wait, also between spool replays
+	SpoolDir     string        `yaml:"spoolDir"`     // defaults to <elkPath>/spool/<name>
+	SpoolMaxMB   int           `yaml:"spoolMaxMB"`   // oldest batches are dropped past this; 0 is unlimited
+}
+
+// SinkName returns Name, or Type when it is not set.
+func (s SinkConfig) SinkName() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.Type
+}
+
+// Check reports an unknown type or a missing destination.
+func (s SinkConfig) Check() error {
+	switch s.Type {
+	case SinkFile:
+	case SinkLogstash:
+		if s.Network != "" && s.Network != "tcp" && s.Network != "udp" {
+			return fmt.Errorf("logstash: unknown network %q", s.Network)
+		}
+		if _, _, err := net.SplitHostPort(s.Address); err != nil {
+			return fmt.Errorf("logstash: address: %w", err)
+		}
+	case SinkElasticsearch:
+		if !strings.HasPrefix(s.URL, "http://") && !strings.HasPrefix(s.URL, "https://") {
+			return fmt.Errorf("elasticsearch: url %q is not http(s)", s.URL)
+		}
+	default:
+		return fmt.Errorf("unknown type %q", s.Type)
+	}
+	return nil
+}
+
+// Masking actions.
+const (
+	MaskAll   = "mask"  // replace every character with *
+	MaskLast4 = "last4" // keep the last four characters
+	MaskHash  = "hash"  // replace with a SHA-256 digest, so equal values still match
+	MaskDrop  = "drop"  // leave the field out (blank it in fixed-length data)
+	MaskNone  = "none"  // log the field as it is
+)
+
+// MaskingConfig is the field-level masking policy of the ELK log. Fields maps
+// a field name, matched without regard to case in JSON bodies and in the
+// record layouts of fixed-length data, to an action; Routes overrides it for
+// single routes, by METHOD:/path. Built-in defaults cover card, ID, account
+// and contact fields; Disabled turns masking off altogether.
+type MaskingConfig struct {
+	Disabled bool                         `yaml:"disabled"`
+	Fields   map[string]string            `yaml:"fields"`
+	Routes   map[string]map[string]string `yaml:"routes"`
+	// NoLayout handles fixed-length data of routes without a record layout:
+	// "digits" (default) masks runs of 13 to 19 digits, such as card and ID
+	// card numbers, keeping the last four; "drop" logs only the header;
+	// "none" logs it as it is.
+	NoLayout string `yaml:"noLayout"`
+}
+
+// Check reports unknown actions.
+func (m MaskingConfig) Check() error {
+	check := func(where string, fields map[string]string) error {
+		for name, action := range fields {
+			switch action {
+			case MaskAll, MaskLast4, MaskHash, MaskDrop, MaskNone:
+			default:
+				return fmt.Errorf("%s field %s: unknown action %q", where, name, action)
+			}
+		}
+		return nil
+	}
+	if err := check("masking", m.Fields); err != nil {
+		return err
+	}
+	for route, fields := range m.Routes {
+		if err := check("route "+route, fields); err != nil {
+			return err
+		}
+	}
+	switch m.NoLayout {
+	case "", "digits", MaskDrop, MaskNone:
+	default:
+		return fmt.Errorf("noLayout: unknown action %q", m.NoLayout)
+	}
+	return nil
+}
+
+// TracingConfig controls OpenTelemetry tracing. Exporter is "otlp" (OTLP over
+// HTTP), "stdout" (spans printed as JSON, for local testing) or empty to
+// record no spans; incoming W3C traceparent headers are honoured either way.
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter"`
+	Endpoint    string  `yaml:"endpoint"`    // OTLP collector host:port; OTEL_EXPORTER_OTLP_ENDPOINT or localhost:4318 when empty
+	Insecure    bool    `yaml:"insecure"`    // plain HTTP to the collector
+	SampleRatio float64 `yaml:"sampleRatio"` // share of new traces recorded; 0 records all
+	ServiceName string  `yaml:"serviceName"` // connectorapi-go when empty
+}
+
+// ErrorCatalogConfig locates the System-I response-code catalog. The file is
+// checked for changes every ReloadInterval, or every Reload.Interval when
+// that is 0, and reloaded on SIGHUP.
+type ErrorCatalogConfig struct {
+	Path           string        `yaml:"path"`
+	ReloadInterval time.Duration `yaml:"reloadInterval"`
+}
+// ReloadConfig controls hot reloading of apikeys.json,
+// destinations_routes.json and the error catalog. The files are checked for
+// changes every Interval (0 disables polling); SIGHUP reloads them at any
+// time.
+type ReloadConfig struct {
+	Interval time.Duration `yaml:"interval"`
+}
 type APIKey struct {
-	Key         []string   `yaml:"key"`
+	Key         []string   `yaml:"key"` // plaintext keys; prefer Hashes
+	Hashes      []APIKeyHash `yaml:"hashes"`
 	ClientName  string   `yaml:"clientName"`
 	Status      string   `yaml:"status"`
 	Permissions []string `yaml:"permissions"`
+	Deny        []string `yaml:"deny"`   // rules refused even if a permission matches
+	Groups      []string `yaml:"groups"` // names of PermissionGroups whose rules apply
+
+	// RateLimit applies to all requests of the client; RouteLimits adds
+	// limits for single routes, by METHOD:/path.
+	RateLimit   *RateLimit           `yaml:"rateLimit"`
+	RouteLimits map[string]RateLimit `yaml:"routeLimits"`
+
+	// Where the keys may be used from; an empty list allows anything.
+	AllowedCIDRs    []string `yaml:"allowedCIDRs"` // networks or single IPs
+	AllowedChannels []string `yaml:"allowedChannels"` // Api-Channel values (L, F, A, W, R, B, V, E)
+	AllowedDeviceOS []string `yaml:"allowedDeviceOS"` // Api-DeviceOS values
+	// Networks are AllowedCIDRs parsed by ParseAPIKeys.
+	Networks []*net.IPNet `yaml:"-" json:"-"`
+
+	// Rules are the client's permissions, deny rules and those of its groups,
+	// resolved by ParseAPIKeys.
+	Rules []Rule `yaml:"-" json:"-"`
 }
 type Destination struct {
 	Type   string              `json:"type"`
 	IP     string              `json:"ip"`
 	Ports  map[string][]string `json:"ports"`
 	APIKey string              `json:"apiKey"`
+	Codec  string              `json:"codec,omitempty"` // cp874 (default), tis620, ibm838 or utf8
+
+	// LoadBalancing is the default port strategy for every service of the
+	// destination (random when empty); ServiceLoadBalancing overrides it per service.
+	LoadBalancing        string            `json:"loadBalancing,omitempty"`
+	ServiceLoadBalancing map[string]string `json:"serviceLoadBalancing,omitempty"`
+
+	// PortWeights holds the weights written as "port:weight" in Ports, by
+	// service then port. It is filled in by LoadDestinationsAndRoutes.
+	PortWeights map[string]map[string]int `json:"-"`
+}
+
+// Strategy returns the load-balancing strategy for service.
+func (d Destination) Strategy(service string) string {
+	if s, ok := d.ServiceLoadBalancing[service]; ok && s != "" {
+		return s
+	}
+	return d.LoadBalancing
+}
+
+// splitPortWeights strips the optional ":weight" suffix from every port entry
+// and records the weights separately.
+func (d *Destination) splitPortWeights() error {
+	for service, specs := range d.Ports {
+		ports := make([]string, 0, len(specs))
+		for _, spec := range specs {
+			port, weight, found := strings.Cut(spec, ":")
+			if found {
+				w, err := strconv.Atoi(weight)
+				if err != nil || w <= 0 {
+					return fmt.Errorf("invalid weight in port %q for service %s", spec, service)
+				}
+				if d.PortWeights == nil {
+					d.PortWeights = make(map[string]map[string]int)
+				}
+				if d.PortWeights[service] == nil {
+					d.PortWeights[service] = make(map[string]int)
+				}
+				d.PortWeights[service][port] = w
+			}
+			ports = append(ports, port)
+		}
+		d.Ports[service] = ports
+	}
+	return nil
 }
 type Route struct {
 	System  		string `json:"System"`
@@ -43,7 +338,106 @@ type Route struct {
 	Format  		string `json:"Format"`
 	FormatV1  		string `json:"FormatV1"`
 	FormatV2  		string `json:"FormatV2"`
-	RequestLength   string `json:"RequestLength"`
+	RequestLength   string `json:"RequestLength"` // optional; only cross-checked at startup, the header length is derived from the body
+	Timeout         string `json:"Timeout,omitempty"`
+	Retries         int    `json:"Retries,omitempty"`
+	Idempotent      bool   `json:"Idempotent,omitempty"`
+	EncodeFallback  string `json:"EncodeFallback,omitempty"` // reject (default), transliterate or replace
+	ParseMode       string   `json:"ParseMode,omitempty"`     // lenient (default) or strict
+	LenientFields   []string `json:"LenientFields,omitempty"` // fields still read leniently when ParseMode is strict
+
+	// A route with a RequestLayout needs no handler of its own: it is served
+	// by the configured-route handler, which validates the JSON body with
+	// Validation, sends it with RequestLayout and answers with the body read
+	// by ResponseLayout. Name is the service name for ports, the error
+	// catalog and ELK; UserRefField names the request field logged as UserRef
+	// and used as the load-balancing key.
+	Name           string               `json:"Name,omitempty"`
+	RequestLayout  string               `json:"RequestLayout,omitempty"`
+	ResponseLayout string               `json:"ResponseLayout,omitempty"`
+	Validation     map[string]FieldRule `json:"Validation,omitempty"`
+	UserRefField   string               `json:"UserRefField,omitempty"`
+}
+
+// FieldRule checks one top-level field of a configured route's request body.
+type FieldRule struct {
+	Required  bool     `json:"Required,omitempty"`
+	MaxLength int      `json:"MaxLength,omitempty"` // in characters
+	Pattern   string   `json:"Pattern,omitempty"`   // regular expression the whole value must match
+	Enum      []string `json:"Enum,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// Configured reports whether the route is served from configuration alone.
+func (r Route) Configured() bool {
+	return r.RequestLayout != ""
+}
+
+// Match reports whether value satisfies the rule's pattern and enum. Required
+// and MaxLength are checked by the caller, which knows how the value was sent.
+func (f FieldRule) Match(value string) bool {
+	if f.pattern != nil && !f.pattern.MatchString(value) {
+		return false
+	}
+	if len(f.Enum) == 0 {
+		return true
+	}
+	for _, e := range f.Enum {
+		if e == value {
+			return true
+		}
+	}
+	return false
+}
+
+// checkConfigured validates the configuration-only part of a route and
+// compiles its patterns.
+func (r *Route) checkConfigured(key string) error {
+	if !r.Configured() {
+		if r.ResponseLayout != "" || len(r.Validation) > 0 {
+			return fmt.Errorf("ResponseLayout and Validation need a RequestLayout")
+		}
+		return nil
+	}
+	method, path, ok := strings.Cut(key, ":")
+	if !ok || method == "" || !strings.HasPrefix(path, "/Api/") {
+		return fmt.Errorf("configured route keys look like POST:/Api/Group/Name")
+	}
+	if r.Name == "" || r.ResponseLayout == "" {
+		return fmt.Errorf("configured route needs a Name and a ResponseLayout")
+	}
+	for field, rule := range r.Validation {
+		if rule.Pattern != "" {
+			re, err := regexp.Compile("^(?:" + rule.Pattern + ")$")
+			if err != nil {
+				return fmt.Errorf("field %s: %w", field, err)
+			}
+			rule.pattern = re
+			r.Validation[field] = rule
+		}
+	}
+	return nil
+}
+
+// StrictParsing reports whether a malformed numeric field in a System-I
+// response fails the request instead of reading as zero.
+func (r Route) StrictParsing() bool {
+	return r.ParseMode == "strict"
+}
+
+// RequestTimeout returns the per-route System-I budget, or 0 when the route
+// does not set one. ParseDestinationsAndRoutes rejects a Timeout that
+// time.ParseDuration cannot read.
+func (r Route) RequestTimeout() time.Duration {
+	if r.Timeout == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(r.Timeout)
+	if err != nil {
+		return 0
+	}
+	return d
 }
 type DestinationsAndRoutes struct {
 	Destinations map[string]Destination `json:"destinations"`
@@ -59,6 +453,9 @@ func Load(path string) (*Config, error) {
 	if err = yaml.Unmarshal(data, &config); err != nil {
 		return nil, err
 	}
+	if err = config.ELK.Check(); err != nil {
+		return nil, fmt.Errorf("elk: %w", err)
+	}
 	return &config, nil
 }
 
@@ -67,10 +464,38 @@ func LoadAPIKeys(path string) ([]APIKey, error) {
 	if err != nil {
 		return nil, err
 	}
-	var apiKeys []APIKey
-	if err := json.Unmarshal(data, &apiKeys); err != nil {
+	return ParseAPIKeys(data)
+}
+
+// ParseAPIKeys reads the contents of apikeys.json: either an array of
+// clients or an object with "groups" and "clients".
+func ParseAPIKeys(data []byte) ([]APIKey, error) {
+	apiKeys, err := parseAPIKeysFile(data)
+	if err != nil {
 		return nil, err
 	}
+	for i, k := range apiKeys {
+		for _, h := range k.Hashes {
+			if err := h.Check(); err != nil {
+				return nil, fmt.Errorf("client %s: %w", k.ClientName, err)
+			}
+		}
+		if k.RateLimit != nil {
+			if err := k.RateLimit.Check(); err != nil {
+				return nil, fmt.Errorf("client %s: %w", k.ClientName, err)
+			}
+		}
+		for route, l := range k.RouteLimits {
+			if err := l.Check(); err != nil {
+				return nil, fmt.Errorf("client %s: route %s: %w", k.ClientName, route, err)
+			}
+		}
+		networks, err := k.ParseCIDRs()
+		if err != nil {
+			return nil, fmt.Errorf("client %s: %w", k.ClientName, err)
+		}
+		apiKeys[i].Networks = networks
+	}
 	return apiKeys, nil
 }
 
@@ -79,9 +504,36 @@ func LoadDestinationsAndRoutes(path string) (*DestinationsAndRoutes, error) {
 	if err != nil {
 		return nil, err
 	}
+	return ParseDestinationsAndRoutes(data)
+}
+
+// ParseDestinationsAndRoutes reads and checks the contents of
+// destinations_routes.json.
+func ParseDestinationsAndRoutes(data []byte) (*DestinationsAndRoutes, error) {
 	var dr DestinationsAndRoutes
 	if err := json.Unmarshal(data, &dr); err != nil {
 		return nil, err
 	}
+	for name, dest := range dr.Destinations {
+		if err := dest.splitPortWeights(); err != nil {
+			return nil, fmt.Errorf("destination %s: %w", name, err)
+		}
+		dr.Destinations[name] = dest
+	}
+	for key, route := range dr.Routes {
+		switch route.ParseMode {
+		case "", "lenient", "strict":
+		default:
+			return nil, fmt.Errorf("route %s: unknown ParseMode %q", key, route.ParseMode)
+		}
+		if route.Timeout != "" {
+			if d, err := time.ParseDuration(route.Timeout); err != nil || d <= 0 {
+				return nil, fmt.Errorf("route %s: Timeout %q is not a positive duration such as \"15s\"", key, route.Timeout)
+			}
+		}
+		if err := route.checkConfigured(key); err != nil {
+			return nil, fmt.Errorf("route %s: %w", key, err)
+		}
+	}
 	return &dr, nil
 }
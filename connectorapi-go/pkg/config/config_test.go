@@ -0,0 +1,24 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseDestinationsAndRoutesTimeout(t *testing.T) {
+	routes := func(timeout string) []byte {
+		return []byte(`{"routes":{"POST:/Api/Echo":{"System":"SYS","Service":"ECHO","Format":"001","Timeout":"` + timeout + `"}}}`)
+	}
+	dr, err := ParseDestinationsAndRoutes(routes("15s"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := dr.Routes["POST:/Api/Echo"].RequestTimeout(); got.Seconds() != 15 {
+		t.Errorf("RequestTimeout = %v", got)
+	}
+	for _, bad := range []string{"15", "15 s", "-1s", "0s"} {
+		if _, err := ParseDestinationsAndRoutes(routes(bad)); err == nil || !strings.Contains(err.Error(), "Timeout") {
+			t.Errorf("Timeout %q: err = %v", bad, err)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultDir is the configuration directory used when none is given.
+const DefaultDir = "./configs"
+
+// Files names the configuration files of one environment in Dir. With no Env
+// they are config.yaml, apikeys.json, destinations_routes.json and
+// error_catalog.json; with Env "uat" the config and routes files are
+// config.uat.yaml and destinations_routes.uat.json, and the others are
+// apikeys.uat.json etc. when those exist, the shared files otherwise.
+type Files struct {
+	Dir string
+	Env string
+}
+
+// Config returns the path of the environment's config file. It is never
+// shared, so a mistyped environment does not start with another's settings.
+func (f Files) Config() string { return f.path("config", ".yaml", false) }
+
+// APIKeys returns the path of the environment's API key file.
+func (f Files) APIKeys() string { return f.path("apikeys", ".json", true) }
+
+// Routes returns the path of the environment's destinations and routes file.
+// Like the config file it is never shared: it names the System-I hosts, and
+// another environment's would send requests to the wrong one.
+func (f Files) Routes() string { return f.path("destinations_routes", ".json", false) }
+
+// ErrorCatalog returns the path of the environment's error catalog, used when
+// errorCatalog.path is not set.
+func (f Files) ErrorCatalog() string { return f.path("error_catalog", ".json", true) }
+
+// Shared reports whether path is a shared file the environment falls back to
+// for want of its own.
+func (f Files) Shared(path string) bool {
+	if f.Env == "" {
+		return false
+	}
+	for _, base := range []string{"apikeys", "error_catalog"} {
+		if path == f.path(base, ".json", true) && path != f.path(base, ".json", false) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Files) path(base, ext string, shared bool) string {
+	dir := f.Dir
+	if dir == "" {
+		dir = DefaultDir
+	}
+	if f.Env == "" {
+		return filepath.Join(dir, base+ext)
+	}
+	p := filepath.Join(dir, base+"."+f.Env+ext)
+	if shared {
+		if _, err := os.Stat(p); err != nil {
+			return filepath.Join(dir, base+ext)
+		}
+	}
+	return p
+}
+
+// EnvPrefix starts every environment variable that overrides a setting.
+const EnvPrefix = "CONNECTOR_"
+
+// envSettings maps the environment variables ApplyEnv reads to the settings
+// they replace.
+var envSettings = map[string]func(c *Config, v string){
+	"PORT":          func(c *Config, v string) { c.Server.Port = v },
+	"MODE":          func(c *Config, v string) { c.Server.Mode = v },
+	"LOG_LEVEL":     func(c *Config, v string) { c.Logger.Level = v },
+	"ELK_PATH":      func(c *Config, v string) { c.ELKPath = v },
+	"ERROR_CATALOG": func(c *Config, v string) { c.ErrorCatalog.Path = v },
+	"LAYOUT_DIR":    func(c *Config, v string) { c.LayoutDir = v },
+
+	"TRACING_EXPORTER": func(c *Config, v string) { c.Tracing.Exporter = v },
+	"TRACING_ENDPOINT": func(c *Config, v string) { c.Tracing.Endpoint = v },
+}
+
+// ApplyEnv overrides settings from CONNECTOR_PORT, CONNECTOR_MODE,
+// CONNECTOR_LOG_LEVEL, CONNECTOR_ELK_PATH, CONNECTOR_ERROR_CATALOG,
+// CONNECTOR_LAYOUT_DIR, CONNECTOR_TRACING_EXPORTER and
+// CONNECTOR_TRACING_ENDPOINT, read with lookup (os.LookupEnv), and returns
+// the names of the variables that were set.
+func (c *Config) ApplyEnv(lookup func(string) (string, bool)) []string {
+	var applied []string
+	for name, set := range envSettings {
+		if v, ok := lookup(EnvPrefix + name); ok && v != "" {
+			set(c, v)
+			applied = append(applied, EnvPrefix+name)
+		}
+	}
+	sort.Strings(applied)
+	return applied
+}
+
+// ApplyEnv overrides the IP of every destination named in a
+// CONNECTOR_DEST_<NAME>_IP variable (NAME upper-cased, e.g.
+// CONNECTOR_DEST_SYSTEMI_IP), read with lookup, and returns the names of the
+// variables that were set.
+func (dr *DestinationsAndRoutes) ApplyEnv(lookup func(string) (string, bool)) []string {
+	var applied []string
+	for name, dest := range dr.Destinations {
+		variable := EnvPrefix + "DEST_" + strings.ToUpper(name) + "_IP"
+		if v, ok := lookup(variable); ok && v != "" {
+			dest.IP = v
+			dr.Destinations[name] = dest
+			applied = append(applied, variable)
+		}
+	}
+	sort.Strings(applied)
+	return applied
+}
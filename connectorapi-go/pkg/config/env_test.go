@@ -0,0 +1,65 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "apikeys.uat.json"), []byte("[]"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	prod := Files{Dir: dir}
+	if got := prod.Config(); got != filepath.Join(dir, "config.yaml") {
+		t.Errorf("Config() = %s", got)
+	}
+	uat := Files{Dir: dir, Env: "uat"}
+	for got, want := range map[string]string{
+		uat.Config():  "config.uat.yaml",
+		uat.APIKeys():      "apikeys.uat.json",
+		uat.Routes():       "destinations_routes.uat.json",
+		uat.ErrorCatalog(): "error_catalog.json",
+	} {
+		if got != filepath.Join(dir, want) {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	}
+	if uat.Shared(uat.APIKeys()) || !uat.Shared(uat.ErrorCatalog()) || prod.Shared(prod.ErrorCatalog()) {
+		t.Error("Shared does not single out the shared catalog of uat")
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	env := map[string]string{
+		"CONNECTOR_PORT":            "9090",
+		"CONNECTOR_ELK_PATH":        "/var/log/elk/",
+		"CONNECTOR_DEST_SYSTEMI_IP": "10.1.1.1",
+		"CONNECTOR_MODE":            "",
+	}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	cfg := &Config{Server: ServerConfig{Port: "8082", Mode: "release"}}
+	applied := cfg.ApplyEnv(lookup)
+	if cfg.Server.Port != "9090" || cfg.ELKPath != "/var/log/elk/" || cfg.Server.Mode != "release" {
+		t.Fatalf("config = %+v", cfg)
+	}
+	if want := []string{"CONNECTOR_ELK_PATH", "CONNECTOR_PORT"}; !reflect.DeepEqual(applied, want) {
+		t.Errorf("applied = %v, want %v", applied, want)
+	}
+
+	dr := &DestinationsAndRoutes{Destinations: map[string]Destination{
+		"systemI": {Type: "tcp", IP: "192.168.129.2"},
+		"other":   {Type: "tcp", IP: "192.168.129.3"},
+	}}
+	dr.ApplyEnv(lookup)
+	if dr.Destinations["systemI"].IP != "10.1.1.1" || dr.Destinations["other"].IP != "192.168.129.3" {
+		t.Fatalf("destinations = %+v", dr.Destinations)
+	}
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// PermissionGroup is a named set of rules defined once in apikeys.json and
+// referenced by clients through APIKey.Groups.
+type PermissionGroup struct {
+	Permissions []string `yaml:"permissions"`
+	Deny        []string `yaml:"deny"`
+}
+
+// apiKeysFile is the object form of apikeys.json; the file may also be a bare
+// array of clients.
+type apiKeysFile struct {
+	Groups  map[string]PermissionGroup `yaml:"groups"`
+	Clients []APIKey                   `yaml:"clients"`
+}
+
+// Rule is one permission or deny rule in force for a client.
+//
+// Pattern is METHOD:/path. METHOD may be * for any method, a path segment may
+// use the wildcards of path.Match (* matches one whole segment) and a final
+// ** segment matches the rest of the path, including nothing.
+type Rule struct {
+	Pattern string
+	Deny    bool
+	Source  string // "client" or "group:<name>"
+}
+
+// String describes the rule for logs, e.g. "deny POST:/Api/Mobile/* (group:mobile)".
+func (r Rule) String() string {
+	verb := "allow"
+	if r.Deny {
+		verb = "deny"
+	}
+	return verb + " " + r.Pattern + " (" + r.Source + ")"
+}
+
+// Matches reports whether the rule applies to a request.
+func (r Rule) Matches(method, fullPath string) bool {
+	return MatchPermission(r.Pattern, method, fullPath)
+}
+
+// MatchPermission reports whether pattern (see Rule) covers method and fullPath.
+func MatchPermission(pattern, method, fullPath string) bool {
+	m, p, ok := strings.Cut(pattern, ":")
+	if !ok || (m != "*" && m != method) {
+		return false
+	}
+	if !strings.ContainsAny(p, "*?[\\") {
+		return p == fullPath
+	}
+	want := strings.Split(p, "/")
+	got := strings.Split(fullPath, "/")
+	for i, seg := range want {
+		if seg == "**" && i == len(want)-1 {
+			return len(got) >= i
+		}
+		if i >= len(got) {
+			return false
+		}
+		if ok, _ := path.Match(seg, got[i]); !ok {
+			return false
+		}
+	}
+	return len(got) == len(want)
+}
+
+// CheckPermission reports whether pattern is a well-formed rule.
+func CheckPermission(pattern string) error {
+	m, p, ok := strings.Cut(pattern, ":")
+	if !ok || m == "" || !strings.HasPrefix(p, "/") {
+		return fmt.Errorf("rule %q is not METHOD:/path", pattern)
+	}
+	segs := strings.Split(p, "/")
+	for i, seg := range segs {
+		if seg == "**" {
+			if i != len(segs)-1 {
+				return fmt.Errorf("rule %q: ** is only allowed as the last segment", pattern)
+			}
+			continue
+		}
+		if _, err := path.Match(seg, ""); err != nil {
+			return fmt.Errorf("rule %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// parseAPIKeysFile decodes either form of apikeys.json, checks every rule and
+// group reference and resolves the rules of every client.
+func parseAPIKeysFile(data []byte) ([]APIKey, error) {
+	var file apiKeysFile
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "{") {
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, err
+		}
+	} else if err := json.Unmarshal(data, &file.Clients); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(file.Groups))
+	for name := range file.Groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		g := file.Groups[name]
+		for _, p := range append(append([]string{}, g.Permissions...), g.Deny...) {
+			if err := CheckPermission(p); err != nil {
+				return nil, fmt.Errorf("group %s: %w", name, err)
+			}
+		}
+	}
+
+	for i := range file.Clients {
+		k := &file.Clients[i]
+		for _, p := range append(append([]string{}, k.Permissions...), k.Deny...) {
+			if err := CheckPermission(p); err != nil {
+				return nil, fmt.Errorf("client %s: %w", k.ClientName, err)
+			}
+		}
+		for _, name := range k.Groups {
+			if _, ok := file.Groups[name]; !ok {
+				return nil, fmt.Errorf("client %s: unknown permission group %q", k.ClientName, name)
+			}
+		}
+		k.Rules = k.ResolveRules(file.Groups)
+	}
+	return file.Clients, nil
+}
+
+// ResolveRules returns the rules of the client in the order they are tried:
+// its own deny rules, those of its groups, then its own permissions and those
+// of its groups. Groups missing from groups are skipped.
+func (k APIKey) ResolveRules(groups map[string]PermissionGroup) []Rule {
+	out := rules(k.Deny, true, "client")
+	for _, name := range k.Groups {
+		out = append(out, rules(groups[name].Deny, true, "group:"+name)...)
+	}
+	out = append(out, rules(k.Permissions, false, "client")...)
+	for _, name := range k.Groups {
+		out = append(out, rules(groups[name].Permissions, false, "group:"+name)...)
+	}
+	return out
+}
+
+func rules(patterns []string, deny bool, source string) []Rule {
+	out := make([]Rule, 0, len(patterns))
+	for _, p := range patterns {
+		out = append(out, Rule{Pattern: p, Deny: deny, Source: source})
+	}
+	return out
+}
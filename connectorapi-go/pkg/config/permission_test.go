@@ -0,0 +1,62 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchPermission(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, method, path string
+		want                  bool
+	}{
+		{"POST:/Api/Mobile/DashboardSummary", "POST", "/Api/Mobile/DashboardSummary", true},
+		{"POST:/Api/Mobile/DashboardSummary", "GET", "/Api/Mobile/DashboardSummary", false},
+		{"POST:/Api/Mobile/*", "POST", "/Api/Mobile/DashboardSummary", true},
+		{"POST:/Api/Mobile/*", "POST", "/Api/Mobile/Card/List", false},
+		{"POST:/Api/Mobile/Dashboard*", "POST", "/Api/Mobile/DashboardDetail", true},
+		{"*:/Api/Mobile/*", "GET", "/Api/Mobile/DashboardSummary", true},
+		{"POST:/Api/**", "POST", "/Api/Mobile/Card/List", true},
+		{"POST:/Api/**", "POST", "/Api", true},
+		{"POST:/Api/**", "POST", "/Other/Mobile", false},
+	} {
+		if got := MatchPermission(tc.pattern, tc.method, tc.path); got != tc.want {
+			t.Errorf("MatchPermission(%q, %s %s) = %v", tc.pattern, tc.method, tc.path, got)
+		}
+	}
+}
+
+func TestParseAPIKeysGroups(t *testing.T) {
+	keys, err := ParseAPIKeys([]byte(`{
+		"groups": {
+			"mobile": {"permissions": ["POST:/Api/Mobile/*"], "deny": ["POST:/Api/Mobile/Admin*"]}
+		},
+		"clients": [
+			{"clientName": "MobileApp", "status": "active", "key": ["K"], "groups": ["mobile"],
+			 "permissions": ["POST:/Api/Agreement/UpdateStatus"], "deny": ["POST:/Api/Mobile/DashboardDetail"]}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range keys[0].Rules {
+		got = append(got, r.String())
+	}
+	want := []string{
+		"deny POST:/Api/Mobile/DashboardDetail (client)",
+		"deny POST:/Api/Mobile/Admin* (group:mobile)",
+		"allow POST:/Api/Agreement/UpdateStatus (client)",
+		"allow POST:/Api/Mobile/* (group:mobile)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("rules =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if _, err := ParseAPIKeys([]byte(`{"clients": [{"clientName": "A", "groups": ["nope"]}]}`)); err == nil || !strings.Contains(err.Error(), `unknown permission group "nope"`) {
+		t.Errorf("unknown group: err = %v", err)
+	}
+	if _, err := ParseAPIKeys([]byte(`[{"clientName": "A", "permissions": ["POST:/Api/**/x"]}]`)); err == nil {
+		t.Error("** in the middle of a rule accepted")
+	}
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// Routing holds the destinations and routes in force. A request reads one
+// snapshot with Current; Store swaps in a new one for the requests after it.
+type Routing struct {
+	current atomic.Pointer[DestinationsAndRoutes]
+}
+
+// NewRouting returns a Routing serving dr.
+func NewRouting(dr *DestinationsAndRoutes) *Routing {
+	r := &Routing{}
+	r.Store(dr)
+	return r
+}
+
+// Current returns the snapshot in force. It must not be modified.
+func (r *Routing) Current() *DestinationsAndRoutes {
+	return r.current.Load()
+}
+
+// Store replaces the snapshot in force.
+func (r *Routing) Store(dr *DestinationsAndRoutes) {
+	r.current.Store(dr)
+}
+
+// Addresses returns the ip:port of every listener of every destination.
+func (dr *DestinationsAndRoutes) Addresses() map[string]bool {
+	addresses := make(map[string]bool)
+	for _, dest := range dr.Destinations {
+		for _, ports := range dest.Ports {
+			for _, port := range ports {
+				addresses[net.JoinHostPort(dest.IP, port)] = true
+			}
+		}
+	}
+	return addresses
+}
+
+// Version returns a short hash identifying the contents of a configuration
+// file, for logs and metrics.
+func Version(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:6])
+}
+
+// WatchFile calls onChange whenever the modification time or size of path
+// changes, checking every interval until stop is closed. Changes made before
+// WatchFile returns are not noticed.
+func WatchFile(path string, interval time.Duration, stop <-chan struct{}, onChange func()) {
+	var lastMod time.Time
+	var lastSize int64
+	if fi, err := os.Stat(path); err == nil {
+		lastMod, lastSize = fi.ModTime(), fi.Size()
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			fi, err := os.Stat(path)
+			if err != nil || (fi.ModTime().Equal(lastMod) && fi.Size() == lastSize) {
+				continue
+			}
+			lastMod, lastSize = fi.ModTime(), fi.Size()
+			onChange()
+		}
+	}()
+}
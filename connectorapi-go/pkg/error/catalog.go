@@ -0,0 +1,245 @@
+package error
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"sync/atomic"
+)
+
+// byName lets the error catalog refer to the errors above by variable name.
+var byName = map[string]*AppError{
+	"ErrService":          ErrService,
+	"ErrUnauthorized":     ErrUnauthorized,
+	"ErrTimeOut":          ErrTimeOut,
+	"ErrMember":           ErrMember,
+	"ErrSystemI":          ErrSystemI,
+	"ErrSystemIUnexpect":  ErrSystemIUnexpect,
+	"ErrSystemIResponse":  ErrSystemIResponse,
+	"ErrMemberUnexpect":   ErrMemberUnexpect,
+	"ErrRateLimited":      ErrRateLimited,
+	"ErrQuotaExceeded":    ErrQuotaExceeded,
+	"ErrIPNotAllowed":     ErrIPNotAllowed,
+	"ErrChannelNotAllowed":  ErrChannelNotAllowed,
+	"ErrDeviceOSNotAllowed": ErrDeviceOSNotAllowed,
+	"ErrInternalServer":   ErrInternalServer,
+	"ErrInternalLength":   ErrInternalLength,
+	"ErrRequiedParam":     ErrRequiedParam,
+	"ErrInvChannel":       ErrInvChannel,
+	"ErrApiChannel":       ErrApiChannel,
+	"ErrInvMode":          ErrInvMode,
+	"ErrAeonID":           ErrAeonID,
+	"ErrUserRefOrAeonID":  ErrUserRefOrAeonID,
+	"ErrInvDateTime":      ErrInvDateTime,
+	"ErrStatus":           ErrStatus,
+	"ErrInvTotalOfList":   ErrInvTotalOfList,
+	"ErrApiRequestID":     ErrApiRequestID,
+	"ErrApiDeviceOS":      ErrApiDeviceOS,
+	"ErrInvCharacter":     ErrInvCharacter,
+	"ErrConNotPass":       ErrConNotPass,
+	"ErrConNotPassCust":   ErrConNotPassCust,
+	"ErrNoMatchProduct":   ErrNoMatchProduct,
+	"ErrInvAgentCode":     ErrInvAgentCode,
+	"ErrInvCode":          ErrInvCode,
+	"ErrIDCardNotFound":   ErrIDCardNotFound,
+	"ErrAgreement":        ErrAgreement,
+	"ErrAgreementInAct":   ErrAgreementInAct,
+	"ErrSUEInfoNotFound":  ErrSUEInfoNotFound,
+	"ErrAgrNotFound":      ErrAgrNotFound,
+	"ErrInvCreditCard":    ErrInvCreditCard,
+	"ErrInvBusCode":       ErrInvBusCode,
+	"ErrInvCardCode":      ErrInvCardCode,
+	"ErrInvCardNo":        ErrInvCardNo,
+	"ErrInvCardStatus":    ErrInvCardStatus,
+	"ErrBigCardNotFound":  ErrBigCardNotFound,
+	"ErrInvIDCardNo":      ErrInvIDCardNo,
+	"ErrInvMobileNo":      ErrInvMobileNo,
+	"ErrInvHBDFormat":     ErrInvHBDFormat,
+	"ErrInvSupHBDFormat":  ErrInvSupHBDFormat,
+	"ErrInvMailTo":        ErrInvMailTo,
+	"ErrInvGender":        ErrInvGender,
+	"ErrInvAppNo":         ErrInvAppNo,
+	"ErrInvAppChannel":    ErrInvAppChannel,
+	"ErrInvViCardFlag":    ErrInvViCardFlag,
+	"ErrInvAppDateFormat": ErrInvAppDateFormat,
+	"ErrInvSourceCode":    ErrInvSourceCode,
+	"ErrInvCardAppType":   ErrInvCardAppType,
+	"ErrInvAppDate":       ErrInvAppDate,
+	"ErrDupAppNo":         ErrDupAppNo,
+	"ErrInvBranchCode":    ErrInvBranchCode,
+	"ErrInvATMNo":         ErrInvATMNo,
+	"ErrInvOTPType":       ErrInvOTPType,
+	"ErrInvSNSNo":         ErrInvSNSNo,
+	"ErrCardNotAva":       ErrCardNotAva,
+	"ErrInvConsentFrom":   ErrInvConsentFrom,
+	"ErrInvConsentCode":   ErrInvConsentCode,
+	"ErrInvConsentVer":    ErrInvConsentVer,
+	"ErrInvConsentStatus": ErrInvConsentStatus,
+	"ErrInvIPAddress":     ErrInvIPAddress,
+	"ErrInvActChannel":    ErrInvActChannel,
+	"ErrInvAppNoCST":      ErrInvAppNoCST,
+	"ErrNotfoundConsent":  ErrNotfoundConsent,
+	"ErrDataNotFound":     ErrDataNotFound,
+	"ErrComCodeNotFound":  ErrComCodeNotFound,
+	"ErrNotAuthor":        ErrNotAuthor,
+	"ErrAgentNotMatch":    ErrAgentNotMatch,
+	"ErrAlready":          ErrAlready,
+	"ErrCheckerNotMatch":  ErrCheckerNotMatch,
+	"ErrAgreeNotFound":    ErrAgreeNotFound,
+	"ErrAgentNotFound":    ErrAgentNotFound,
+	"ErrInvDate":          ErrInvDate,
+}
+
+// Lookup returns the AppError declared in this package under name.
+func Lookup(name string) (*AppError, bool) {
+	e, ok := byName[name]
+	return e, ok
+}
+
+// CatalogEntry maps one System-I response code to an AppError. In the
+// catalog file an entry is either the name of an error in this package
+// ("ErrInvIDCardNo") or an object that names one, or defines a new
+// ErrorCode/ErrorMessage pair, optionally with the HTTP status to answer with.
+type CatalogEntry struct {
+	Error        string `json:"error,omitempty"`
+	ErrorCode    string `json:"errorCode,omitempty"`
+	ErrorMessage string `json:"errorMessage,omitempty"`
+	Status       int    `json:"status,omitempty"` // 0 keeps the handler's default for the error code
+
+	appErr *AppError
+}
+
+func (e *CatalogEntry) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(`"`)) {
+		*e = CatalogEntry{}
+		return json.Unmarshal(data, &e.Error)
+	}
+	type entry CatalogEntry
+	var v entry
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*e = CatalogEntry(v)
+	return nil
+}
+
+func (e *CatalogEntry) resolve() error {
+	switch {
+	case e.Error != "" && e.ErrorCode != "":
+		return fmt.Errorf("set either error or errorCode, not both")
+	case e.Error != "":
+		appErr, ok := Lookup(e.Error)
+		if !ok {
+			return fmt.Errorf("unknown error %q", e.Error)
+		}
+		e.appErr = appErr
+	case e.ErrorCode != "":
+		e.appErr = &AppError{ErrorCode: e.ErrorCode, ErrorMessage: e.ErrorMessage}
+	default:
+		return fmt.Errorf("entry names no error")
+	}
+	if e.Status != 0 && (e.Status < 400 || e.Status > 599) {
+		return fmt.Errorf("status %d is not an HTTP error status", e.Status)
+	}
+	return nil
+}
+
+// build returns the error for a System-I code and message: a copy of the
+// entry's AppError carrying the raw code and message for the ELK log.
+func (e *CatalogEntry) build(code, message string) *AppError {
+	temp := *e.appErr
+	temp.Code = code
+	temp.Message = message
+	if e.Status != 0 {
+		temp.StatusCode = strconv.Itoa(e.Status)
+	}
+	return &temp
+}
+
+// Catalog maps System-I response codes to AppErrors. Services entries are
+// keyed by service name, or by service and response format for codes whose
+// meaning depends on the format ("GetCustomerInfo/004"); the format entry wins
+// over the service one, which wins over Global. Unknown answers every code
+// none lists (ErrSystemIUnexpect when unset).
+type Catalog struct {
+	Global   map[string]CatalogEntry            `json:"global"`
+	Services map[string]map[string]CatalogEntry `json:"services"`
+	Unknown  *CatalogEntry                      `json:"unknown,omitempty"`
+}
+
+// ParseCatalog reads a catalog and checks that every entry resolves.
+func ParseCatalog(data []byte) (*Catalog, error) {
+	var c Catalog
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	for code, e := range c.Global {
+		if err := e.resolve(); err != nil {
+			return nil, fmt.Errorf("global %s: %w", code, err)
+		}
+		c.Global[code] = e
+	}
+	for service, codes := range c.Services {
+		for code, e := range codes {
+			if err := e.resolve(); err != nil {
+				return nil, fmt.Errorf("service %s %s: %w", service, code, err)
+			}
+			codes[code] = e
+		}
+	}
+	if c.Unknown != nil {
+		if err := c.Unknown.resolve(); err != nil {
+			return nil, fmt.Errorf("unknown: %w", err)
+		}
+	}
+	return &c, nil
+}
+
+// LoadCatalog reads and validates the catalog file at path.
+func LoadCatalog(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCatalog(data)
+}
+
+// Resolve maps a System-I code returned by service in a response of format,
+// which may be empty. The bool reports whether the catalog knows the code; an
+// unknown code still gets the Unknown error.
+func (c *Catalog) Resolve(service, format, code, message string) (*AppError, bool) {
+	if e, ok := c.Services[service+"/"+format][code]; ok && format != "" {
+		return e.build(code, message), true
+	}
+	if e, ok := c.Services[service][code]; ok {
+		return e.build(code, message), true
+	}
+	if e, ok := c.Global[code]; ok {
+		return e.build(code, message), true
+	}
+	if c.Unknown != nil {
+		return c.Unknown.build(code, message), false
+	}
+	temp := *ErrSystemIUnexpect
+	temp.Code = code
+	temp.Message = message
+	return &temp, false
+}
+
+var current atomic.Pointer[Catalog]
+
+func init() {
+	current.Store(&Catalog{})
+}
+
+// SetCatalog replaces the catalog used by ResolveSystemI.
+func SetCatalog(c *Catalog) {
+	current.Store(c)
+}
+
+// ResolveSystemI maps a System-I code with the current catalog.
+func ResolveSystemI(service, format, code, message string) (*AppError, bool) {
+	return current.Load().Resolve(service, format, code, message)
+}
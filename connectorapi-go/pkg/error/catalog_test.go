@@ -0,0 +1,81 @@
+package error
+
+import (
+	"testing"
+)
+
+const testCatalog = `{
+	"global": {"SVC902": "ErrSystemI"},
+	"services": {
+		"MyCard": {
+			"SVC117": "ErrInvIDCardNo",
+			"SVC902": {"error": "ErrService", "status": 503}
+		},
+		"GetCardSales": {
+			"SVC401": {"errorCode": "CRC020", "errorMessage": "Card blocked", "status": 409}
+		}
+	}
+}`
+
+func TestCatalogResolve(t *testing.T) {
+	c, err := ParseCatalog([]byte(testCatalog))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, known := c.Resolve("MyCard", "", "SVC117", "ID card invalid")
+	if !known || got.ErrorCode != ErrInvIDCardNo.ErrorCode || got.Code != "SVC117" || got.Message != "ID card invalid" {
+		t.Fatalf("service entry: %+v known=%v", got, known)
+	}
+	if ErrInvIDCardNo.Code != "" {
+		t.Fatal("Resolve modified the shared AppError")
+	}
+
+	got, _ = c.Resolve("MyCard", "", "SVC902", "")
+	if got.ErrorCode != ErrService.ErrorCode || got.StatusCode != "503" {
+		t.Fatalf("service entry should win over global: %+v", got)
+	}
+	got, _ = c.Resolve("GetCardSales", "", "SVC902", "")
+	if got.ErrorCode != ErrSystemI.ErrorCode || got.StatusCode != "" {
+		t.Fatalf("global entry: %+v", got)
+	}
+
+	got, known = c.Resolve("GetCardSales", "", "SVC401", "blocked")
+	if !known || got.ErrorCode != "CRC020" || got.ErrorMessage != "Card blocked" || got.StatusCode != "409" {
+		t.Fatalf("inline entry: %+v", got)
+	}
+
+	got, known = c.Resolve("MyCard", "", "SVC999", "new code")
+	if known || got.ErrorCode != ErrSystemIUnexpect.ErrorCode || got.Code != "SVC999" {
+		t.Fatalf("unknown code: %+v known=%v", got, known)
+	}
+}
+
+func TestParseCatalogRejectsBadEntries(t *testing.T) {
+	for name, data := range map[string]string{
+		"unknown name": `{"global": {"SVC1": "ErrNoSuchError"}}`,
+		"both kinds":   `{"global": {"SVC1": {"error": "ErrSystemI", "errorCode": "X"}}}`,
+		"empty entry":  `{"services": {"MyCard": {"SVC1": {}}}}`,
+		"bad status":   `{"global": {"SVC1": {"error": "ErrSystemI", "status": 200}}}`,
+	} {
+		if _, err := ParseCatalog([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestShippedCatalogLoads(t *testing.T) {
+	c, err := LoadCatalog("../../configs/error_catalog.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, known := c.Resolve("CheckRegister", "", "SVC266", ""); !known || got.ErrorCode != ErrConNotPassCust.ErrorCode {
+		t.Fatalf("CheckRegister SVC266: %+v", got)
+	}
+	// SVC117 names the ID card only in the format 004 response of GetCustomerInfo.
+	for format, want := range map[string]*AppError{"001": ErrUserRefOrAeonID, "003": ErrUserRefOrAeonID, "004": ErrInvIDCardNo} {
+		if got, known := c.Resolve("GetCustomerInfo", format, "SVC117", ""); !known || got.ErrorCode != want.ErrorCode {
+			t.Errorf("GetCustomerInfo/%s SVC117: %+v, want %s", format, got, want.ErrorCode)
+		}
+	}
+}
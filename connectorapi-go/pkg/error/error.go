@@ -31,7 +31,13 @@ var (
 	ErrMember           = &AppError{ErrorCode: "SYS005", ErrorMessage: "Member Service System Unavailable"}
 	ErrSystemI  		= &AppError{ErrorCode: "SYS008", ErrorMessage: "System-I Unavailable"}
 	ErrSystemIUnexpect	= &AppError{ErrorCode: "SYS009", ErrorMessage: "System-I Unexpected error occurred"}
+	ErrSystemIResponse  = &AppError{ErrorCode: "SYS010", ErrorMessage: "System-I response could not be parsed"}
 	ErrMemberUnexpect	= &AppError{ErrorCode: "SYS012", ErrorMessage: "Member Service System Unexpected Error"}
+	ErrRateLimited      = &AppError{ErrorCode: "SYS013", ErrorMessage: "Too many requests", StatusCode: "429"}
+	ErrQuotaExceeded    = &AppError{ErrorCode: "SYS014", ErrorMessage: "Request quota exceeded", StatusCode: "429"}
+	ErrIPNotAllowed     = &AppError{ErrorCode: "SYS015", ErrorMessage: "Client IP not allowed for this Api-Key", StatusCode: "403"}
+	ErrChannelNotAllowed  = &AppError{ErrorCode: "SYS016", ErrorMessage: "Api-Channel not allowed for this Api-Key", StatusCode: "403"}
+	ErrDeviceOSNotAllowed = &AppError{ErrorCode: "SYS017", ErrorMessage: "Api-DeviceOS not allowed for this Api-Key", StatusCode: "403"}
 	ErrInternalServer   = &AppError{ErrorCode: "SYS500", ErrorMessage: "An unexpected internal error occurred"}
 	ErrInternalLength   = &AppError{ErrorCode: "SYS500", ErrorMessage: "An unexpected internal error occurred: max length"}
 
@@ -46,6 +52,7 @@ var (
 	ErrInvTotalOfList  	= &AppError{ErrorCode: "COM016", ErrorMessage: "Invalid Total of List."}
 	ErrApiRequestID     = &AppError{ErrorCode: "COM033", ErrorMessage: "Invalid Api-RequestID"}
 	ErrApiDeviceOS      = &AppError{ErrorCode: "COM034", ErrorMessage: "Invalid Api-DeviceOS"}
+	ErrInvCharacter     = &AppError{ErrorCode: "COM035", ErrorMessage: "Invalid Character"}
     ErrConNotPass       = &AppError{ErrorCode: "COM043", ErrorMessage: "Condition not passed"}
 	ErrConNotPassCust   = &AppError{ErrorCode: "COM043", ErrorMessage: "Condition not passed(Customer Cannot Register)"}
 	ErrNoMatchProduct   = &AppError{ErrorCode: "COM065", ErrorMessage: "No Product Match with The Conditions"}
@@ -0,0 +1,515 @@
+// Package layout describes fixed-length System-I records declaratively and
+// converts them to and from Go structs.
+//
+// A layout is a list of fields, each with a type and a length in characters
+// (runes, so Thai text counts one per character as it does on the host):
+//
+//	layouts:
+//	  - name: CollectionLogResponse
+//	    minLength: 36
+//	    fields:
+//	      - {name: IDCardNo, type: string, length: 20}
+//	      - {name: AgreementNo, type: string, length: 16}
+//
+// Field types are string, int, decimal (with implied decimals), filler and
+// group. A group repeats its sub-fields; its count is a number, the name of an
+// earlier int field, or "rest" to repeat until the data runs out.
+//
+// Marshal and Unmarshal work on Go structs; MarshalRecord and UnmarshalRecord
+// on decoded JSON.
+package layout
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FieldError is a numeric field whose text is not a number. Offset is in
+// characters from the start of the data passed to Unmarshal.
+type FieldError struct {
+	Field  string
+	Offset int
+	Raw    string
+}
+
+// FieldErrors is returned by Unmarshal when numeric fields did not parse.
+type FieldErrors []FieldError
+
+func (e FieldErrors) Error() string {
+	parts := make([]string, len(e))
+	for i, fe := range e {
+		parts[i] = fmt.Sprintf("%s at %d: %q", fe.Field, fe.Offset, fe.Raw)
+	}
+	return "malformed fields: " + strings.Join(parts, ", ")
+}
+
+// Field types.
+const (
+	TypeString  = "string"
+	TypeInt     = "int"
+	TypeDecimal = "decimal"
+	TypeFiller  = "filler"
+	TypeGroup   = "group"
+)
+
+// CountRest repeats a group for every complete block left in the data.
+const CountRest = "rest"
+
+// Field is one element of a record.
+type Field struct {
+	Name     string  `yaml:"name" json:"name"`
+	Type     string  `yaml:"type" json:"type"`
+	Length   int     `yaml:"length" json:"length"`
+	Pad      string  `yaml:"pad" json:"pad"`         // left or right; numbers default to left, strings to right
+	PadChar  string  `yaml:"padChar" json:"padChar"` // numbers default to "0", strings to " "
+	Decimals int     `yaml:"decimals" json:"decimals"`
+	Count    string  `yaml:"count" json:"count"`
+	Fields   []Field `yaml:"fields" json:"fields"`
+}
+
+// Layout is a complete record body, without the 123-character header.
+type Layout struct {
+	Name      string  `yaml:"name" json:"name"`
+	MinLength int     `yaml:"minLength" json:"minLength"`
+	Fields    []Field `yaml:"fields" json:"fields"`
+}
+
+type layoutFile struct {
+	Layouts []*Layout `yaml:"layouts" json:"layouts"`
+}
+
+// Registry holds layouts by name.
+type Registry struct {
+	layouts map[string]*Layout
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{layouts: make(map[string]*Layout)}
+}
+
+// Parse reads a YAML or JSON layout file.
+func Parse(data []byte) ([]*Layout, error) {
+	var f layoutFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	return f.Layouts, nil
+}
+
+// LoadFS adds every .yaml, .yml and .json layout file found in dir of fsys.
+func (r *Registry) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		switch path.Ext(e.Name()) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return err
+		}
+		layouts, err := Parse(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", e.Name(), err)
+		}
+		for _, l := range layouts {
+			if err := r.Add(l); err != nil {
+				return fmt.Errorf("%s: %w", e.Name(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// Add validates l and registers it under its name.
+func (r *Registry) Add(l *Layout) error {
+	if l.Name == "" {
+		return fmt.Errorf("layout without a name")
+	}
+	if _, ok := r.layouts[l.Name]; ok {
+		return fmt.Errorf("layout %s defined twice", l.Name)
+	}
+	if err := validateFields(l.Fields); err != nil {
+		return fmt.Errorf("layout %s: %w", l.Name, err)
+	}
+	r.layouts[l.Name] = l
+	return nil
+}
+
+// Get returns the layout registered as name.
+func (r *Registry) Get(name string) (*Layout, bool) {
+	l, ok := r.layouts[name]
+	return l, ok
+}
+
+// Names returns the registered layout names.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.layouts))
+	for name := range r.layouts {
+		names = append(names, name)
+	}
+	return names
+}
+
+func validateFields(fields []Field) error {
+	ints := map[string]bool{}
+	for _, f := range fields {
+		switch f.Type {
+		case TypeString, TypeInt, TypeDecimal:
+			if f.Name == "" {
+				return fmt.Errorf("%s field without a name", f.Type)
+			}
+			if f.Length <= 0 {
+				return fmt.Errorf("field %s: length must be positive", f.Name)
+			}
+			if f.Pad != "" && f.Pad != "left" && f.Pad != "right" {
+				return fmt.Errorf("field %s: pad must be left or right", f.Name)
+			}
+			if len([]rune(f.PadChar)) > 1 {
+				return fmt.Errorf("field %s: padChar must be a single character", f.Name)
+			}
+			if f.Type == TypeInt {
+				ints[f.Name] = true
+			}
+		case TypeFiller:
+			if f.Length <= 0 {
+				return fmt.Errorf("filler: length must be positive")
+			}
+		case TypeGroup:
+			if f.Name == "" {
+				return fmt.Errorf("group without a name")
+			}
+			if len(f.Fields) == 0 {
+				return fmt.Errorf("group %s has no fields", f.Name)
+			}
+			if _, err := strconv.Atoi(f.Count); err != nil && f.Count != CountRest && !ints[f.Count] {
+				return fmt.Errorf("group %s: count must be a number, %q or an earlier int field", f.Name, CountRest)
+			}
+			if err := validateFields(f.Fields); err != nil {
+				return fmt.Errorf("group %s: %w", f.Name, err)
+			}
+		default:
+			return fmt.Errorf("field %s: unknown type %q", f.Name, f.Type)
+		}
+	}
+	return nil
+}
+
+// Length returns the number of characters the fields occupy, counting every
+// group once. For a group this is the size of one repetition.
+func Length(fields []Field) int {
+	n := 0
+	for _, f := range fields {
+		if f.Type == TypeGroup {
+			n += Length(f.Fields)
+			continue
+		}
+		n += f.Length
+	}
+	return n
+}
+
+// FixedLength returns the length of the record when every group is empty,
+// i.e. the part of the record that does not repeat.
+func (l *Layout) FixedLength() int {
+	n := 0
+	for _, f := range l.Fields {
+		if f.Type != TypeGroup {
+			n += f.Length
+		}
+	}
+	return n
+}
+
+// Marshal renders the struct v points to (or v itself) as a fixed-length
+// string. Groups write one block per element of the matching slice.
+func (l *Layout) Marshal(v interface{}) (string, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return "", fmt.Errorf("layout %s: cannot marshal %T", l.Name, v)
+	}
+	var sb strings.Builder
+	if err := marshalFields(&sb, l.Fields, rv); err != nil {
+		return "", fmt.Errorf("layout %s: %w", l.Name, err)
+	}
+	return sb.String(), nil
+}
+
+func marshalFields(sb *strings.Builder, fields []Field, rv reflect.Value) error {
+	for _, f := range fields {
+		if f.Type == TypeFiller {
+			sb.WriteString(strings.Repeat(padChar(f), f.Length))
+			continue
+		}
+		fv := rv.FieldByName(f.Name)
+		if !fv.IsValid() {
+			return fmt.Errorf("%s has no field %s", rv.Type(), f.Name)
+		}
+		if f.Type == TypeGroup {
+			if fv.Kind() != reflect.Slice && fv.Kind() != reflect.Array {
+				return fmt.Errorf("group %s must be a slice, got %s", f.Name, fv.Type())
+			}
+			for i := 0; i < fv.Len(); i++ {
+				if err := marshalFields(sb, f.Fields, reflect.Indirect(fv.Index(i))); err != nil {
+					return fmt.Errorf("%s[%d]: %w", f.Name, i, err)
+				}
+			}
+			continue
+		}
+		s, err := formatValue(f, fv)
+		if err != nil {
+			return err
+		}
+		sb.WriteString(s)
+	}
+	return nil
+}
+
+func formatValue(f Field, fv reflect.Value) (string, error) {
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return pad(f, ""), nil
+		}
+		fv = fv.Elem()
+	}
+	switch f.Type {
+	case TypeString:
+		switch fv.Kind() {
+		case reflect.String:
+			return pad(f, fv.String()), nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return pad(f, strconv.FormatInt(fv.Int(), 10)), nil
+		}
+	case TypeInt:
+		switch fv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return formatNumber(f, fv.Int()), nil
+		case reflect.String:
+			return pad(f, fv.String()), nil
+		}
+	case TypeDecimal:
+		switch fv.Kind() {
+		case reflect.Float32, reflect.Float64:
+			scaled := fv.Float() * pow10(f.Decimals)
+			if scaled < 0 {
+				scaled -= 0.5
+			} else {
+				scaled += 0.5
+			}
+			return formatNumber(f, int64(scaled)), nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return formatNumber(f, fv.Int()*int64(pow10(f.Decimals))), nil
+		}
+	}
+	return "", fmt.Errorf("field %s: cannot write %s as %s", f.Name, fv.Type(), f.Type)
+}
+
+// formatNumber zero-pads on the left like %0*d unless the field asks for
+// other padding. Numbers wider than the field are not truncated.
+func formatNumber(f Field, n int64) string {
+	if padChar(f) == "0" && padLeft(f) {
+		return fmt.Sprintf("%0*d", f.Length, n)
+	}
+	return pad(f, strconv.FormatInt(n, 10))
+}
+
+// pad truncates s to the field length and pads it with the field's pad character.
+func pad(f Field, s string) string {
+	runes := []rune(s)
+	if len(runes) > f.Length {
+		runes = runes[:f.Length]
+	}
+	fill := strings.Repeat(padChar(f), f.Length-len(runes))
+	if padLeft(f) {
+		return fill + string(runes)
+	}
+	return string(runes) + fill
+}
+
+func padLeft(f Field) bool {
+	if f.Pad != "" {
+		return f.Pad == "left"
+	}
+	return f.Type == TypeInt || f.Type == TypeDecimal
+}
+
+func padChar(f Field) string {
+	if f.PadChar != "" {
+		return f.PadChar
+	}
+	if f.Type == TypeInt || f.Type == TypeDecimal {
+		return "0"
+	}
+	return " "
+}
+
+func pow10(n int) float64 {
+	p := 1.0
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+	return p
+}
+
+// Unmarshal reads data into the struct v points to. Values are trimmed of
+// spaces and fields past the end of data are left empty. Numbers that do not
+// parse read as zero, as the hand-written parsers did, and are returned as
+// FieldErrors after every other field has been filled in.
+func (l *Layout) Unmarshal(data string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("layout %s: Unmarshal needs a pointer to a struct, got %T", l.Name, v)
+	}
+	runes := []rune(data)
+	if l.MinLength > 0 && len(runes) < l.MinLength {
+		return fmt.Errorf("raw data too short for body, length=%d, need %d", len(runes), l.MinLength)
+	}
+	var errs FieldErrors
+	if _, err := unmarshalFields(runes, 0, "", l.Fields, rv.Elem(), &errs); err != nil {
+		return fmt.Errorf("layout %s: %w", l.Name, err)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func unmarshalFields(runes []rune, pos int, path string, fields []Field, rv reflect.Value, errs *FieldErrors) (int, error) {
+	counts := map[string]int{}
+	for _, f := range fields {
+		switch f.Type {
+		case TypeFiller:
+			pos += f.Length
+			continue
+		case TypeGroup:
+			next, err := unmarshalGroup(runes, pos, path, f, rv, counts, errs)
+			if err != nil {
+				return 0, err
+			}
+			pos = next
+			continue
+		}
+
+		raw := readField(runes, pos, path, f, errs)
+		pos += f.Length
+		fv := rv.FieldByName(f.Name)
+		if !fv.IsValid() {
+			return 0, fmt.Errorf("%s has no field %s", rv.Type(), f.Name)
+		}
+		if f.Type == TypeInt {
+			n, _ := strconv.Atoi(raw)
+			counts[f.Name] = n
+		}
+		if err := setValue(f, fv, raw); err != nil {
+			return 0, err
+		}
+	}
+	return pos, nil
+}
+
+func unmarshalGroup(runes []rune, pos int, path string, f Field, rv reflect.Value, counts map[string]int, errs *FieldErrors) (int, error) {
+	fv := rv.FieldByName(f.Name)
+	if !fv.IsValid() || fv.Kind() != reflect.Slice {
+		return 0, fmt.Errorf("%s has no slice field %s", rv.Type(), f.Name)
+	}
+	blockLen := Length(f.Fields)
+	count, stopAtEnd := groupCount(runes, pos, f, counts)
+	items := reflect.MakeSlice(fv.Type(), 0, count)
+	for i := 0; i < count; i++ {
+		start := pos + i*blockLen
+		if stopAtEnd && start >= len(runes) {
+			break
+		}
+		item := reflect.New(fv.Type().Elem()).Elem()
+		if _, err := unmarshalFields(runes, start, fmt.Sprintf("%s%s[%d].", path, f.Name, i), f.Fields, item, errs); err != nil {
+			return 0, fmt.Errorf("%s[%d]: %w", f.Name, i, err)
+		}
+		items = reflect.Append(items, item)
+	}
+	fv.Set(items)
+	return pos + items.Len()*blockLen, nil
+}
+
+// readField returns the trimmed text of the scalar field f at pos, recording a
+// FieldError when a numeric field does not hold a number.
+func readField(runes []rune, pos int, path string, f Field, errs *FieldErrors) string {
+	text := string(slice(runes, pos, f.Length))
+	raw := strings.TrimSpace(text)
+	if f.Type != TypeString && raw != "" {
+		if _, err := strconv.ParseInt(raw, 10, 64); err != nil {
+			*errs = append(*errs, FieldError{Field: path + f.Name, Offset: pos, Raw: text})
+		}
+	}
+	return raw
+}
+
+// groupCount returns how many blocks of group f to read at pos. stopAtEnd is
+// set when the count comes from the data and a short record ends the group
+// early; such a count is capped at the blocks the rest of the record can hold,
+// so a bad count cannot size a huge slice.
+func groupCount(runes []rune, pos int, f Field, counts map[string]int) (count int, stopAtEnd bool) {
+	switch n, err := strconv.Atoi(f.Count); {
+	case err == nil:
+		count = n
+	case f.Count == CountRest:
+		if remaining := len(runes) - pos; remaining > 0 {
+			count = remaining / Length(f.Fields)
+		}
+	default:
+		count, stopAtEnd = counts[f.Count], true
+		if blockLen := Length(f.Fields); blockLen > 0 {
+			count = min(count, (len(runes)-pos+blockLen-1)/blockLen)
+		}
+	}
+	if count < 0 {
+		count = 0
+	}
+	return count, stopAtEnd
+}
+
+func setValue(f Field, fv reflect.Value, raw string) error {
+	if fv.Kind() == reflect.Ptr {
+		ptr := reflect.New(fv.Type().Elem())
+		if err := setValue(f, ptr.Elem(), raw); err != nil {
+			return err
+		}
+		fv.Set(ptr)
+		return nil
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(raw)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, _ := strconv.ParseInt(raw, 10, 64)
+		fv.SetInt(n)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		n, _ := strconv.ParseInt(raw, 10, 64)
+		fv.SetFloat(float64(n) / pow10(f.Decimals))
+		return nil
+	}
+	return fmt.Errorf("field %s: cannot read %s into %s", f.Name, f.Type, fv.Type())
+}
+
+func slice(runes []rune, start, length int) []rune {
+	if start >= len(runes) {
+		return nil
+	}
+	end := start + length
+	if end > len(runes) {
+		end = len(runes)
+	}
+	return runes[start:end]
+}
@@ -0,0 +1,167 @@
+package layout
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testLayouts = `
+layouts:
+  - name: Statement
+    minLength: 12
+    fields:
+      - {name: Account, type: string, length: 8}
+      - {name: Count,   type: int,    length: 2}
+      - {type: filler,  length: 2}
+      - name: Lines
+        type: group
+        count: Count
+        fields:
+          - {name: Text,   type: string,  length: 4}
+          - {name: Amount, type: decimal, length: 6, decimals: 2}
+  - name: Tail
+    fields:
+      - {name: Code, type: string, length: 2}
+      - name: Items
+        type: group
+        count: rest
+        fields:
+          - {name: Value, type: int, length: 3}
+`
+
+type line struct {
+	Text   string
+	Amount float64
+}
+
+type statement struct {
+	Account string
+	Count   int
+	Lines   []line
+}
+
+type tail struct {
+	Code  *string
+	Items []struct{ Value int }
+}
+
+func loadTestRegistry(t *testing.T) *Registry {
+	t.Helper()
+	layouts, err := Parse([]byte(testLayouts))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	r := NewRegistry()
+	for _, l := range layouts {
+		if err := r.Add(l); err != nil {
+			t.Fatalf("Add %s: %v", l.Name, err)
+		}
+	}
+	return r
+}
+
+func TestMarshalUnmarshalRoundTrip(t *testing.T) {
+	l, _ := loadTestRegistry(t).Get("Statement")
+	in := statement{Account: "ACC1", Count: 2, Lines: []line{{"กขค", 12.34}, {"LONGER", 0.5}}}
+
+	got, err := l.Marshal(&in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	want := "ACC1    02  กขค 001234LONG000050"
+	if got != want {
+		t.Fatalf("Marshal = %q, want %q", got, want)
+	}
+	if l.FixedLength() != 12 || Length(l.Fields) != 22 {
+		t.Fatalf("FixedLength/Length = %d/%d, want 12/22", l.FixedLength(), Length(l.Fields))
+	}
+
+	var out statement
+	if err := l.Unmarshal(got, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if out.Account != "ACC1" || out.Count != 2 || len(out.Lines) != 2 {
+		t.Fatalf("Unmarshal = %+v", out)
+	}
+	if out.Lines[0] != (line{"กขค", 12.34}) || out.Lines[1] != (line{"LONG", 0.5}) {
+		t.Fatalf("Unmarshal lines = %+v", out.Lines)
+	}
+}
+
+func TestUnmarshalGroupCounts(t *testing.T) {
+	r := loadTestRegistry(t)
+	statementLayout, _ := r.Get("Statement")
+
+	// The count says three blocks but only one is present.
+	var s statement
+	if err := statementLayout.Unmarshal("ACC1    03  ABCD000100", &s); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if len(s.Lines) != 1 || s.Lines[0].Amount != 1 {
+		t.Fatalf("Lines = %+v, want one block", s.Lines)
+	}
+	if cap(s.Lines) != 1 {
+		t.Errorf("cap(Lines) = %d, want the count capped at the blocks present", cap(s.Lines))
+	}
+	statementField := statementLayout.Fields[3]
+	if n, _ := groupCount([]rune("ABCD000100"), 0, statementField, map[string]int{"Count": 1 << 40}); n != 1 {
+		t.Errorf("groupCount with a huge count = %d, want 1", n)
+	}
+
+	if err := statementLayout.Unmarshal("ACC1", &s); err == nil || !strings.Contains(err.Error(), "too short") {
+		t.Fatalf("short data error = %v", err)
+	}
+
+	tailLayout, _ := r.Get("Tail")
+	var tl tail
+	if err := tailLayout.Unmarshal("OK00100200", &tl); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if tl.Code == nil || *tl.Code != "OK" {
+		t.Fatalf("Code = %v, want OK", tl.Code)
+	}
+	if len(tl.Items) != 2 || tl.Items[0].Value != 1 || tl.Items[1].Value != 2 {
+		t.Fatalf("Items = %+v, want 2 complete blocks", tl.Items)
+	}
+}
+
+func TestAddRejectsInvalidLayouts(t *testing.T) {
+	tests := map[string]Layout{
+		"no name":       {Fields: []Field{{Name: "A", Type: TypeString, Length: 1}}},
+		"zero length":   {Name: "X", Fields: []Field{{Name: "A", Type: TypeString}}},
+		"unknown type":  {Name: "X", Fields: []Field{{Name: "A", Type: "date", Length: 8}}},
+		"bad pad":       {Name: "X", Fields: []Field{{Name: "A", Type: TypeString, Length: 1, Pad: "centre"}}},
+		"unknown count": {Name: "X", Fields: []Field{{Name: "G", Type: TypeGroup, Count: "N", Fields: []Field{{Name: "A", Type: TypeString, Length: 1}}}}},
+		"empty group":   {Name: "X", Fields: []Field{{Name: "G", Type: TypeGroup, Count: "1"}}},
+	}
+	for name, l := range tests {
+		l := l
+		if err := NewRegistry().Add(&l); err == nil {
+			t.Errorf("%s: Add accepted an invalid layout", name)
+		}
+	}
+
+	r := loadTestRegistry(t)
+	l, _ := r.Get("Tail")
+	if err := r.Add(l); err == nil {
+		t.Fatal("Add accepted a duplicate layout name")
+	}
+}
+
+func TestUnmarshalReportsMalformedNumbers(t *testing.T) {
+	l, _ := loadTestRegistry(t).Get("Statement")
+
+	var s statement
+	err := l.Unmarshal("ACC1    02  ABCD0001X0EFGH000200", &s)
+	var fieldErrs FieldErrors
+	if !errors.As(err, &fieldErrs) || len(fieldErrs) != 1 {
+		t.Fatalf("Unmarshal error = %v, want one FieldError", err)
+	}
+	if fe := fieldErrs[0]; fe.Field != "Lines[0].Amount" || fe.Offset != 16 || fe.Raw != "0001X0" {
+		t.Fatalf("FieldError = %+v", fe)
+	}
+	if len(s.Lines) != 2 || s.Lines[0].Amount != 0 || s.Lines[1].Amount != 2 {
+		t.Fatalf("Lines = %+v, want the record read with the bad amount zeroed", s.Lines)
+	}
+}
@@ -0,0 +1,175 @@
+package layout
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Record is a fixed-length record held as decoded JSON: scalar fields by name,
+// groups as []interface{} of Records. It lets a layout be used without a Go
+// struct, e.g. for endpoints that are only described in configuration.
+type Record = map[string]interface{}
+
+// MarshalRecord renders r like Marshal renders a struct. A field missing from
+// r is written as an empty string or zero. Values are read the way
+// encoding/json decodes them: strings, float64 or json.Number numbers, and
+// []interface{} groups.
+func (l *Layout) MarshalRecord(r Record) (string, error) {
+	sb := make([]byte, 0, l.FixedLength())
+	out, err := marshalRecordFields(sb, l.Fields, r)
+	if err != nil {
+		return "", fmt.Errorf("layout %s: %w", l.Name, err)
+	}
+	return string(out), nil
+}
+
+func marshalRecordFields(out []byte, fields []Field, r Record) ([]byte, error) {
+	for _, f := range fields {
+		switch f.Type {
+		case TypeFiller:
+			out = append(out, pad(f, "")...)
+			continue
+		case TypeGroup:
+			var items []interface{}
+			switch v := r[f.Name].(type) {
+			case nil:
+			case []interface{}:
+				items = v
+			default:
+				return nil, fmt.Errorf("group %s must be a list, got %T", f.Name, v)
+			}
+			for i, item := range items {
+				ir, ok := item.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("%s[%d] must be an object, got %T", f.Name, i, item)
+				}
+				var err error
+				if out, err = marshalRecordFields(out, f.Fields, ir); err != nil {
+					return nil, fmt.Errorf("%s[%d]: %w", f.Name, i, err)
+				}
+			}
+			continue
+		}
+		s, err := formatRecordValue(f, r[f.Name])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s...)
+	}
+	return out, nil
+}
+
+func formatRecordValue(f Field, v interface{}) (string, error) {
+	if v == nil {
+		if f.Type == TypeString {
+			return pad(f, ""), nil
+		}
+		return formatNumber(f, 0), nil
+	}
+	if n, ok := v.(json.Number); ok {
+		v = n.String()
+		if f.Type != TypeString {
+			fv, err := n.Float64()
+			if err != nil {
+				return "", fmt.Errorf("field %s: %q is not a number", f.Name, n)
+			}
+			v = fv
+		}
+	}
+	switch f.Type {
+	case TypeString:
+		switch v := v.(type) {
+		case string:
+			return pad(f, v), nil
+		case float64:
+			return pad(f, strconv.FormatFloat(v, 'f', -1, 64)), nil
+		}
+	case TypeInt:
+		switch v := v.(type) {
+		case float64:
+			if v != math.Trunc(v) {
+				return "", fmt.Errorf("field %s: %v is not a whole number", f.Name, v)
+			}
+			return formatNumber(f, int64(v)), nil
+		case string:
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return "", fmt.Errorf("field %s: %q is not a whole number", f.Name, v)
+			}
+			return formatNumber(f, n), nil
+		}
+	case TypeDecimal:
+		switch v := v.(type) {
+		case float64:
+			return formatNumber(f, int64(math.Round(v*pow10(f.Decimals)))), nil
+		case string:
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return "", fmt.Errorf("field %s: %q is not a number", f.Name, v)
+			}
+			return formatNumber(f, int64(math.Round(n*pow10(f.Decimals)))), nil
+		}
+	}
+	return "", fmt.Errorf("field %s: cannot write %T as %s", f.Name, v, f.Type)
+}
+
+// UnmarshalRecord reads data like Unmarshal, into a new Record: strings for
+// string fields, int64 for int fields, float64 for decimals and a (possibly
+// empty) []interface{} for every group.
+func (l *Layout) UnmarshalRecord(data string) (Record, error) {
+	runes := []rune(data)
+	if l.MinLength > 0 && len(runes) < l.MinLength {
+		return nil, fmt.Errorf("raw data too short for body, length=%d, need %d", len(runes), l.MinLength)
+	}
+	r := Record{}
+	var errs FieldErrors
+	unmarshalRecordFields(runes, 0, "", l.Fields, r, &errs)
+	if len(errs) > 0 {
+		return r, errs
+	}
+	return r, nil
+}
+
+func unmarshalRecordFields(runes []rune, pos int, path string, fields []Field, r Record, errs *FieldErrors) int {
+	counts := map[string]int{}
+	for _, f := range fields {
+		switch f.Type {
+		case TypeFiller:
+			pos += f.Length
+			continue
+		case TypeGroup:
+			blockLen := Length(f.Fields)
+			count, stopAtEnd := groupCount(runes, pos, f, counts)
+			items := make([]interface{}, 0, count)
+			for i := 0; i < count; i++ {
+				start := pos + i*blockLen
+				if stopAtEnd && start >= len(runes) {
+					break
+				}
+				item := Record{}
+				unmarshalRecordFields(runes, start, fmt.Sprintf("%s%s[%d].", path, f.Name, i), f.Fields, item, errs)
+				items = append(items, item)
+			}
+			r[f.Name] = items
+			pos += len(items) * blockLen
+			continue
+		}
+
+		raw := readField(runes, pos, path, f, errs)
+		pos += f.Length
+		switch f.Type {
+		case TypeString:
+			r[f.Name] = raw
+		case TypeInt:
+			n, _ := strconv.ParseInt(raw, 10, 64)
+			counts[f.Name] = int(n)
+			r[f.Name] = n
+		case TypeDecimal:
+			n, _ := strconv.ParseInt(raw, 10, 64)
+			r[f.Name] = float64(n) / pow10(f.Decimals)
+		}
+	}
+	return pos
+}
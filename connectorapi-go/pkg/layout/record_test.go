@@ -0,0 +1,65 @@
+package layout
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRecordMatchesStructMarshalling(t *testing.T) {
+	l, _ := loadTestRegistry(t).Get("Statement")
+
+	var in Record
+	dec := json.NewDecoder(strings.NewReader(`{"Account": "ACC1", "Count": 2, "Lines": [{"Text": "กขค", "Amount": 12.34}, {"Text": "LONGER", "Amount": "0.5"}]}`))
+	dec.UseNumber()
+	if err := dec.Decode(&in); err != nil {
+		t.Fatal(err)
+	}
+	got, err := l.MarshalRecord(in)
+	if err != nil {
+		t.Fatalf("MarshalRecord: %v", err)
+	}
+	if want := "ACC1    02  กขค 001234LONG000050"; got != want {
+		t.Fatalf("MarshalRecord = %q, want %q", got, want)
+	}
+
+	out, err := l.UnmarshalRecord(got)
+	if err != nil {
+		t.Fatalf("UnmarshalRecord: %v", err)
+	}
+	lines, _ := out["Lines"].([]interface{})
+	if out["Account"] != "ACC1" || out["Count"] != int64(2) || len(lines) != 2 {
+		t.Fatalf("UnmarshalRecord = %v", out)
+	}
+	if first := lines[0].(Record); first["Text"] != "กขค" || first["Amount"] != 12.34 {
+		t.Fatalf("first line = %v", first)
+	}
+}
+
+func TestMarshalRecordRejectsWrongTypes(t *testing.T) {
+	l, _ := loadTestRegistry(t).Get("Statement")
+	for name, r := range map[string]Record{
+		"fraction in int": {"Count": 1.5},
+		"text in int":     {"Count": "two"},
+		"object as group": {"Lines": map[string]interface{}{}},
+		"bool as string":  {"Account": true},
+	} {
+		if _, err := l.MarshalRecord(r); err == nil {
+			t.Errorf("%s: MarshalRecord accepted %v", name, r)
+		}
+	}
+}
+
+func TestUnmarshalRecordReportsMalformedNumbers(t *testing.T) {
+	l, _ := loadTestRegistry(t).Get("Statement")
+
+	out, err := l.UnmarshalRecord("ACC1    02  ABCD0001X0EFGH000200")
+	var fieldErrs FieldErrors
+	if !errors.As(err, &fieldErrs) || len(fieldErrs) != 1 || fieldErrs[0].Field != "Lines[0].Amount" {
+		t.Fatalf("UnmarshalRecord error = %v, want one FieldError for Lines[0].Amount", err)
+	}
+	if lines := out["Lines"].([]interface{}); len(lines) != 2 {
+		t.Fatalf("Lines = %v, want the record read anyway", lines)
+	}
+}
@@ -0,0 +1,62 @@
+package layout
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Rewrite returns data with the text of every scalar field replaced by
+// fn(f, text), e.g. to mask fields before data is logged. text is the field as
+// it appears in data, padding included. The result is padded or truncated to
+// the length of text, so every field keeps its position; fillers, fields past
+// the end of data and anything after the last field are copied unchanged.
+// Group counts are read from the original data.
+func (l *Layout) Rewrite(data string, fn func(f Field, text string) string) string {
+	runes := []rune(data)
+	out := make([]rune, len(runes))
+	copy(out, runes)
+	rewriteFields(runes, out, 0, l.Fields, fn)
+	return string(out)
+}
+
+func rewriteFields(runes, out []rune, pos int, fields []Field, fn func(Field, string) string) int {
+	counts := map[string]int{}
+	for _, f := range fields {
+		switch f.Type {
+		case TypeFiller:
+			pos += f.Length
+			continue
+		case TypeGroup:
+			blockLen := Length(f.Fields)
+			count, stopAtEnd := groupCount(runes, pos, f, counts)
+			n := 0
+			for ; n < count; n++ {
+				start := pos + n*blockLen
+				if stopAtEnd && start >= len(runes) {
+					break
+				}
+				rewriteFields(runes, out, start, f.Fields, fn)
+			}
+			pos += n * blockLen
+			continue
+		}
+
+		text := slice(runes, pos, f.Length)
+		if f.Type == TypeInt {
+			n, _ := strconv.Atoi(strings.TrimSpace(string(text)))
+			counts[f.Name] = n
+		}
+		if len(text) > 0 {
+			repl := []rune(fn(f, string(text)))
+			if len(repl) > len(text) {
+				repl = repl[:len(text)]
+			}
+			for len(repl) < len(text) {
+				repl = append(repl, ' ')
+			}
+			copy(out[pos:], repl)
+		}
+		pos += f.Length
+	}
+	return pos
+}
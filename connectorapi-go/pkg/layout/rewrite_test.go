@@ -0,0 +1,34 @@
+package layout
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRewriteKeepsPositions(t *testing.T) {
+	l, _ := loadTestRegistry(t).Get("Statement")
+	data := "ACC1    02  กขค 001234LONG000050"
+
+	var seen []string
+	got := l.Rewrite(data, func(f Field, text string) string {
+		seen = append(seen, f.Name)
+		switch f.Name {
+		case "Account":
+			return "XX" // padded back to the field length
+		case "Text":
+			return strings.Repeat("*", 10) // truncated to the field length
+		}
+		return text
+	})
+	if want := "XX      02  ****001234****000050"; got != want {
+		t.Errorf("Rewrite = %q, want %q", got, want)
+	}
+	if strings.Join(seen, ",") != "Account,Count,Text,Amount,Text,Amount" {
+		t.Errorf("fields visited: %v", seen)
+	}
+
+	// A short record is rewritten as far as it goes.
+	if got := l.Rewrite("ACC1    02  กข", func(f Field, text string) string { return "" }); got != strings.Repeat(" ", 14) {
+		t.Errorf("Rewrite of a short record = %q", got)
+	}
+}
@@ -6,6 +6,35 @@ import (
 var (
 	HttpRequestsTotal   *prometheus.CounterVec
 	HttpRequestDuration *prometheus.HistogramVec
+
+	TCPPoolConnections  *prometheus.GaugeVec
+	TCPPoolEventsTotal  *prometheus.CounterVec
+	TCPPoolWaitSeconds  *prometheus.HistogramVec
+
+	TCPDialSeconds      *prometheus.HistogramVec
+	TCPRoundTripSeconds *prometheus.HistogramVec
+	TCPBytesTotal       *prometheus.CounterVec
+	TCPErrorsTotal      *prometheus.CounterVec
+	TCPInFlight         *prometheus.GaugeVec
+	SystemIResponses    *prometheus.CounterVec
+
+	CircuitBreakerState       *prometheus.GaugeVec
+	CircuitBreakerTransitions *prometheus.CounterVec
+
+	ConfigReloadsTotal *prometheus.CounterVec
+	ConfigVersion      *prometheus.GaugeVec
+
+	APIKeyRequestsTotal *prometheus.CounterVec
+	APIKeyLastUsed      *prometheus.GaugeVec
+
+	RateLimitedTotal *prometheus.CounterVec
+	RateLimitTokens  *prometheus.GaugeVec
+	QuotaUsed        *prometheus.GaugeVec
+
+	ELKLogLinesTotal *prometheus.CounterVec
+	ELKQueueLength   prometheus.Gauge
+	ELKSinkLines     *prometheus.CounterVec
+	ELKSpoolBytes    *prometheus.GaugeVec
 )
 func Init() {
 	HttpRequestsTotal = promauto.NewCounterVec(
@@ -23,4 +52,160 @@ func Init() {
 		},
 		[]string{"method", "path", "status"},
 	)
-}
\ No newline at end of file
+	TCPPoolConnections = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "tcp_pool_connections",
+			Help: "Connections held by the System-I TCP pool by state (open, idle, in_use).",
+		},
+		[]string{"address", "state"},
+	)
+	TCPPoolEventsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "tcp_pool_events_total",
+			Help: "System-I TCP pool events (dial, reuse, discard, wait, exhausted).",
+		},
+		[]string{"address", "event"},
+	)
+	TCPPoolWaitSeconds = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "tcp_pool_wait_seconds",
+			Help:    "Time spent waiting for a free System-I TCP pool slot.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"address"},
+	)
+	TCPDialSeconds = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "tcp_dial_seconds",
+			Help:    "Time to open a TCP connection to a System-I listener.",
+			Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		},
+		[]string{"destination", "port"},
+	)
+	TCPRoundTripSeconds = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "tcp_round_trip_seconds",
+			Help:    "Time from writing a System-I request to reading its response, by header Service name.",
+			Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 20, 30},
+		},
+		[]string{"destination", "port", "service"},
+	)
+	TCPBytesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "tcp_bytes_total",
+			Help: "Bytes exchanged with System-I by direction (sent, received).",
+		},
+		[]string{"destination", "port", "service", "direction"},
+	)
+	TCPErrorsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "tcp_errors_total",
+			Help: "Failed System-I exchanges by error class (ER040 connect, ER050 timeout, ER060 read/write, ER070 cancelled, ER099 encoding).",
+		},
+		[]string{"destination", "port", "service", "code"},
+	)
+	TCPInFlight = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "tcp_in_flight_requests",
+			Help: "System-I requests waiting for a response, by listener port.",
+		},
+		[]string{"destination", "port"},
+	)
+	SystemIResponses = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "systemi_responses_total",
+			Help: "System-I responses by header response code (OK when empty).",
+		},
+		[]string{"destination", "port", "service", "code"},
+	)
+	CircuitBreakerState = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "tcp_circuit_breaker_state",
+			Help: "System-I port circuit breaker state (0 closed, 1 half-open, 2 open).",
+		},
+		[]string{"address"},
+	)
+	CircuitBreakerTransitions = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "tcp_circuit_breaker_transitions_total",
+			Help: "System-I port circuit breaker state changes by target state.",
+		},
+		[]string{"address", "state"},
+	)
+	ConfigReloadsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "config_reloads_total",
+			Help: "Configuration file reloads by file and result (success, unchanged, failure).",
+		},
+		[]string{"file", "result"},
+	)
+	ConfigVersion = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "config_version_info",
+			Help: "Always 1, labelled with the hash of the configuration file in force.",
+		},
+		[]string{"file", "version"},
+	)
+	APIKeyRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "api_key_requests_total",
+			Help: "Requests by API key and outcome (allowed, denied, inactive, expired, not_yet_valid, unknown_key, ip_not_allowed, channel_not_allowed, device_os_not_allowed).",
+		},
+		[]string{"client", "key_id", "result"},
+	)
+	APIKeyLastUsed = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "api_key_last_used_timestamp_seconds",
+			Help: "Unix time an API key was last presented.",
+		},
+		[]string{"client", "key_id"},
+	)
+	RateLimitedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rate_limited_requests_total",
+			Help: "Requests refused with 429 by client, scope (client or route) and limit (rate, daily, monthly).",
+		},
+		[]string{"client", "scope", "limit"},
+	)
+	RateLimitTokens = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rate_limit_tokens",
+			Help: "Tokens left in a client's rate-limit bucket after its last request.",
+		},
+		[]string{"client", "scope"},
+	)
+	QuotaUsed = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rate_limit_quota_used",
+			Help: "Requests counted against a client's quota in the current period (daily, monthly).",
+		},
+		[]string{"client", "scope", "period"},
+	)
+	ELKLogLinesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "elk_log_lines_total",
+			Help: "ELK log lines by result (written, dropped_queue_full, dropped_write_error).",
+		},
+		[]string{"result"},
+	)
+	ELKQueueLength = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "elk_log_queue_length",
+			Help: "Requests whose ELK log lines wait for the background writer.",
+		},
+	)
+	ELKSinkLines = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "elk_sink_lines_total",
+			Help: "ELK log lines per remote sink by result (shipped, spooled, replayed, rejected, dropped_spool_full, dropped_spool_error).",
+		},
+		[]string{"sink", "result"},
+	)
+	ELKSpoolBytes = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "elk_sink_spool_bytes",
+			Help: "Bytes of ELK log lines spooled on disk while a remote sink is down.",
+		},
+		[]string{"sink"},
+	)
+}
@@ -0,0 +1,102 @@
+// Package tracing sets up OpenTelemetry tracing: the tracer provider and its
+// exporter, W3C trace context propagation and the attributes shared by the
+// spans of the inbound request, the service call and the System-I TCP hop.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"connectorapi-go/pkg/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporters of config.TracingConfig.
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+const tracerName = "connectorapi-go"
+
+// Attributes of the service and TCP spans.
+const (
+	RouteKey      = attribute.Key("connector.route")       // METHOD:/path of the inbound request
+	RequestIDKey  = attribute.Key("connector.request_id")  // Api-RequestID
+	SystemKey     = attribute.Key("systemi.system")        // System code of the request header
+	ServiceKey    = attribute.Key("systemi.service")       // Service code of the request header
+	FormatKey     = attribute.Key("systemi.format")        // Format code of the request header
+	ResponseKey   = attribute.Key("systemi.response_code") // SVC code of the response header
+	ErrorCodeKey  = attribute.Key("connector.error_code")  // error code returned to the client
+	TCPErrorKey   = attribute.Key("systemi.tcp_error")     // ER040, ER050, ER060, ER070 or ER099
+	TCPAttemptKey = attribute.Key("systemi.attempt")       // 0 for the first port tried
+)
+
+// Init installs the W3C trace context propagator and, when cfg names an
+// exporter, a tracer provider exporting to it. The returned function flushes
+// and stops the provider; it is a no-op when no exporter is configured.
+func Init(ctx context.Context, cfg config.TracingConfig) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		var opts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		return nil, fmt.Errorf("tracing: unknown exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tracing: %w", err)
+	}
+
+	name := cfg.ServiceName
+	if name == "" {
+		name = tracerName
+	}
+	ratio := cfg.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(name))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Tracer returns the tracer of the application.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// TraceID returns the hex trace ID of the span in ctx, or "" when there is none.
+func TraceID(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+	return ""
+}
@@ -0,0 +1,37 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"connectorapi-go/pkg/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func TestInitPropagatesTraceparent(t *testing.T) {
+	if _, err := Init(context.Background(), config.TracingConfig{Exporter: "zipkin"}); err == nil {
+		t.Fatal("unknown exporter accepted")
+	}
+	shutdown, err := Init(context.Background(), config.TracingConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(context.Background())
+
+	// Without an exporter no spans are recorded, but the caller's trace is
+	// still picked up for the logs.
+	h := http.Header{}
+	h.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.HeaderCarrier(h))
+	ctx, span := Tracer().Start(ctx, "test")
+	defer span.End()
+	if got := TraceID(ctx); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("TraceID = %q", got)
+	}
+	if got := TraceID(context.Background()); got != "" {
+		t.Errorf("TraceID without a span = %q", got)
+	}
+}